@@ -1,22 +1,236 @@
 package cli
 
 import (
+	"fmt"
+	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/mirovarga/litepub/lib"
 )
 
 func build(arguments map[string]interface{}) int {
 	dir := arguments["<dir>"].(string)
+	profile, _ := arguments["--profile"].(int)
+	relative, _ := arguments["--relative"].(int)
+	preview, _ := arguments["--preview"].(bool)
 
-	blog, err := lib.NewMarkdownBlog(dir).Read()
+	if pprofDir, ok := arguments["--pprof"].(string); ok {
+		stop, err := startPprof(pprofDir)
+		if err != nil {
+			log.Errorf("Failed to start profiling: %s\n", err)
+			return 1
+		}
+		defer func() {
+			if err := stop(); err != nil {
+				log.Errorf("Failed to write profiles: %s\n", err)
+			}
+		}()
+	}
+
+	wait, ok := arguments["--wait"].(string)
+	if !ok {
+		wait = "0s"
+	}
+	timeout, err := time.ParseDuration(wait)
+	if err != nil {
+		log.Errorf("Invalid timeout: %s\n", wait)
+		return 1
+	}
+
+	return buildLocked(dir, buildFlags{relative: relative == 1, preview: preview,
+		profile: profile == 1}, timeout)
+}
+
+// buildLocked builds the blog (or the sites) in the directory according to the
+// flags while holding its build lock, waiting at most for the timeout if
+// another build holds it.
+func buildLocked(dir string, flags buildFlags, timeout time.Duration) int {
+	lock, err := lib.AcquireBuildLock(filepath.Join(dir, cacheDir, lib.LockFile), timeout)
+	if err != nil {
+		log.Errorf("Failed to lock blog: %s\n", err)
+		return 1
+	}
+	defer lock.Release()
+
+	if _, err := os.Stat(filepath.Join(dir, lib.SitesFile)); err == nil {
+		return buildSites(dir, flags)
+	}
+	return buildBlog(dir, filepath.Join(dir, templatesDir), nil, "", flags)
+}
+
+// buildSites builds the blogs listed in the SitesFile in the directory. The
+// shared templates are parsed only once.
+func buildSites(dir string, flags buildFlags) int {
+	sites, err := lib.ReadSitesConfig(dir)
+	if err != nil {
+		log.Errorf("Failed to read sites: %s\n", err)
+		return 1
+	}
+
+	var templates *lib.Templates
+	if sites.Templates != "" {
+		parsed, err := lib.ParseTemplates(filepath.Join(dir, sites.Templates))
+		if err != nil {
+			log.Errorf("Failed to parse templates: %s\n", err)
+			return 1
+		}
+		templates = &parsed
+	}
+
+	for _, site := range sites.Sites {
+		siteDir := filepath.Join(dir, site)
+		siteTemplates := filepath.Join(siteDir, templatesDir)
+		if sites.Templates != "" {
+			siteTemplates = filepath.Join(dir, sites.Templates)
+		}
+
+		started := time.Now()
+		if code := buildBlog(siteDir, siteTemplates, templates, site, flags); code != 0 {
+			log.Errorf("Failed to build site: %s\n", site)
+			return code
+		}
+		log.Infof("Built: %s (%.2fs)\n", site, time.Since(started).Seconds())
+	}
+
+	log.Infof("Built %d site(s)\n", len(sites.Sites))
+	return 0
+}
+
+// buildFlags are the options of the build command.
+type buildFlags struct {
+	// relative tells whether internal links use relative paths (see
+	// Config.RelativeURLs).
+	relative bool
+
+	// preview tells whether draft and scheduled posts are included.
+	preview bool
+
+	// profile tells whether timings of the build are printed after it.
+	profile bool
+
+	// changes tells whether the files of the output directory that changed or
+	// were removed (and the number of unchanged ones) are printed after the
+	// build instead of each generated file (for rebuilds in watch mode).
+	changes bool
+}
+
+// buildBlog builds the blog in the directory with the templates (using the
+// shared Templates if they aren't nil) according to the flags. Progress and
+// warnings of a site (if it isn't empty) are prefixed with its name.
+func buildBlog(dir, templates string, shared *lib.Templates, site string, flags buildFlags) int {
+	progress, warn := printProgress, printWarning
+	if site != "" {
+		progress = func(path string) { printProgress(filepath.Join(site, path)) }
+		warn = func(message string) { printWarning(site + ": " + message) }
+	}
+
+	unchanged := 0
+	changes := func(event lib.ProgressEvent) {
+		if event.Status == lib.OutputUnchanged {
+			unchanged++
+			return
+		}
+		if event.Status == lib.OutputRemoved {
+			log.Infof("Removed: %s\n", filepath.Join(site, event.Path))
+			return
+		}
+		log.Infof("Regenerated: %s (%s)\n", filepath.Join(site, event.Path), event.Status)
+	}
+	if flags.changes {
+		progress = func(string) {}
+	}
+
+	config, err := lib.ReadConfig(dir)
+	if err != nil {
+		log.Errorf("Failed to read config: %s\n", err)
+		return 1
+	}
+	if flags.relative {
+		config.RelativeURLs = true
+	}
+
+	var p *lib.Profile
+	if flags.profile {
+		p = lib.NewProfile()
+	}
+
+	var blog lib.Blog
+	err = p.Time("reading", func() (err error) {
+		blog, err = readBlog(dir, config)
+		return err
+	})
 	if err != nil {
 		log.Errorf("Failed to read blog: %s\n", err)
 		return 1
 	}
 
-	gen, err := lib.NewStaticBlogGenerator(blog, filepath.Join(dir, templatesDir),
-		filepath.Join(dir, outputDir), printProgress)
+	archiveFile := filepath.Join(dir, lib.ArchiveFile)
+	snapshots, err := lib.ReadArchive(archiveFile)
+	if config.ArchiveLinks && err == nil {
+		err = p.Time("archiving", func() (err error) {
+			snapshots, err = lib.NewArchiver(archiveFile).Archive(
+				blog.ExternalLinks(false), printArchiving, warn)
+			return err
+		})
+	}
+	if err != nil {
+		log.Errorf("Failed to archive links: %s\n", err)
+		return 1
+	}
+
+	blogroll, err := lib.ReadBlogroll(dir)
+	if err == nil && config.FetchBlogroll && len(blogroll) > 0 {
+		p.Time("blogroll", func() error {
+			blogroll = blogroll.FetchLatest(printFetching, warn)
+			return nil
+		})
+	}
+	if err != nil {
+		log.Errorf("Failed to read blogroll: %s\n", err)
+		return 1
+	}
+
+	data, err := lib.ReadData(dir)
+	if config.TemplatePosts && err != nil {
+		log.Errorf("Failed to read data: %s\n", err)
+		return 1
+	}
+
+	opts := []lib.Option{lib.WithConfig(config),
+		lib.WithStaticDir(filepath.Join(dir, staticDir)), lib.WithWarnFunc(warn),
+		lib.WithSnapshots(snapshots), lib.WithCacheDir(filepath.Join(dir, cacheDir)),
+		lib.WithRenderCacheDir(filepath.Join(dir, lib.RenderCacheDir)),
+		lib.WithProfile(p), lib.WithBlogroll(blogroll), lib.WithBuild(lib.NewBuild(dir)),
+		lib.WithData(data)}
+	for _, command := range config.Hooks.Before {
+		opts = append(opts, lib.WithBeforeHook(lib.CommandHook(command, dir)))
+	}
+	for _, command := range config.Hooks.After {
+		opts = append(opts, lib.WithAfterHook(lib.CommandHook(command, dir)))
+	}
+
+	plugins, err := startPlugins(dir, config)
+	if err != nil {
+		log.Errorf("Failed to start plugins: %s\n", err)
+		return 1
+	}
+	defer stopPlugins(plugins)
+	for _, plugin := range plugins {
+		opts = append(opts, lib.WithPlugin(plugin))
+	}
+	if shared != nil {
+		opts = append(opts, lib.WithTemplates(*shared))
+	}
+	if flags.preview {
+		opts = append(opts, lib.WithPreview())
+	}
+	if flags.changes {
+		opts = append(opts, lib.WithProgressEvents(changes))
+	}
+
+	gen, err := lib.NewStaticBlogGenerator(blog, templates,
+		filepath.Join(dir, outputDir), progress, opts...)
 	if err != nil {
 		log.Errorf("Failed to create generator: %s\n", err)
 		return 1
@@ -27,10 +241,85 @@ func build(arguments map[string]interface{}) int {
 		log.Errorf("Failed to generate blog: %s\n", err)
 		return 1
 	}
+	if flags.changes {
+		log.Infof("Unchanged: %d file(s)\n", unchanged)
+	}
 
+	if flags.profile {
+		printProfile(p)
+	}
 	return 0
 }
 
+// readBlog reads the blog in the directory merged with the blogs (in other
+// directories, Git repositories or S3 buckets) of the config's Sources.
+func readBlog(dir string, config lib.Config) (lib.Blog, error) {
+	cache := lib.WithCacheDir(filepath.Join(dir, cacheDir))
+	renderCache := lib.WithRenderCacheDir(filepath.Join(dir, lib.RenderCacheDir))
+	readers := []lib.BlogReader{lib.NewMarkdownBlog(dir, lib.WithConfig(config),
+		lib.WithWarnFunc(printWarning), cache, renderCache)}
+	for _, source := range config.Sources {
+		if lib.IsS3URL(source) {
+			store, err := lib.NewS3Store(source, config.S3)
+			if err != nil {
+				return nil, err
+			}
+			readers = append(readers, lib.NewStoreBlog(store, lib.WithConfig(config)))
+			continue
+		}
+
+		if lib.IsGitURL(source) {
+			readers = append(readers, lib.NewGitBlog(source, lib.WithConfig(config), cache))
+			continue
+		}
+
+		if !filepath.IsAbs(source) {
+			source = filepath.Join(dir, source)
+		}
+		if _, err := os.Stat(source); err != nil {
+			return nil, fmt.Errorf("source not found: %s", source)
+		}
+		readers = append(readers, lib.NewMarkdownBlog(source, lib.WithConfig(config), cache,
+			renderCache))
+	}
+
+	if len(readers) == 1 {
+		return readers[0].Read()
+	}
+	return lib.NewMergedBlog(readers, lib.WithWarnFunc(printWarning)).Read()
+}
+
+func startPlugins(dir string, config lib.Config) ([]*lib.Plugin, error) {
+	var plugins []*lib.Plugin
+	for _, command := range config.Plugins {
+		plugin, err := lib.StartPlugin(command, dir)
+		if err != nil {
+			stopPlugins(plugins)
+			return nil, err
+		}
+		plugins = append(plugins, plugin)
+	}
+	return plugins, nil
+}
+
+func stopPlugins(plugins []*lib.Plugin) {
+	for _, plugin := range plugins {
+		plugin.Close()
+	}
+}
+
 func printProgress(path string) {
 	log.Infof("Generating: %s\n", path)
 }
+
+func printArchiving(url string) {
+	log.Infof("Archiving: %s\n", url)
+}
+
+func printFetching(url string) {
+	log.Infof("Fetching: %s\n", url)
+}
+
+func printWarning(message string) {
+	log.Warnf("%s\n", message)
+}
@@ -4,19 +4,24 @@ import (
 	"fmt"
 
 	"github.com/docopt/docopt-go"
+	"github.com/mirovarga/litepub/lib"
 )
 
 const (
 	postsDir     = "posts"
 	templatesDir = "templates"
+	staticDir    = "static"
 	outputDir    = "www"
+	commentsDir  = "comments"
+	dataDir      = "data"
+	cacheDir     = ".cache"
 )
 
 var log quietableLog
 
 // TODO pass args (also check for other ways to decouple things)
 func Run() int {
-	arguments, _ := docopt.ParseArgs(usage, nil, "LitePub 0.5.7")
+	arguments, _ := docopt.ParseArgs(usage, nil, "LitePub "+lib.Version)
 
 	log = quietableLog{arguments["--quiet"].(int) == 1}
 
@@ -26,10 +31,28 @@ func Run() int {
 
 	if arguments["create"].(bool) {
 		return create(arguments)
+	} else if arguments["init"].(bool) {
+		return initBlog(arguments)
 	} else if arguments["build"].(bool) {
 		return build(arguments)
 	} else if arguments["serve"].(bool) {
 		return serve(arguments)
+	} else if arguments["stats"].(bool) {
+		return stats(arguments)
+	} else if arguments["links"].(bool) {
+		return links(arguments)
+	} else if arguments["stale"].(bool) {
+		return stale(arguments)
+	} else if arguments["encrypt"].(bool) {
+		return encrypt(arguments)
+	} else if arguments["newsletter"].(bool) {
+		return newsletter(arguments)
+	} else if arguments["deploy"].(bool) {
+		return deploy(arguments)
+	} else if arguments["daemon"].(bool) {
+		return daemon(arguments)
+	} else if arguments["fixture"].(bool) {
+		return fixture(arguments)
 	}
 
 	return 0
@@ -45,6 +68,10 @@ func (l quietableLog) Infof(format string, v ...interface{}) {
 	}
 }
 
+func (l quietableLog) Warnf(format string, v ...interface{}) {
+	fmt.Printf("WARNING: "+format, v...)
+}
+
 func (l quietableLog) Errorf(format string, v ...interface{}) {
 	fmt.Printf("ERROR: "+format, v...)
 }
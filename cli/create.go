@@ -39,22 +39,7 @@ func create(arguments map[string]interface{}) int {
 			}
 		}
 	} else {
-		err := fs.WalkDir(assets, "sample", func(path string, d fs.DirEntry, err error) error {
-			if path == "sample" {
-				return nil
-			}
-
-			trimmedPath := strings.TrimPrefix(path, "sample/")
-			if d.IsDir() {
-				return os.MkdirAll(filepath.Join(dir, trimmedPath), 0700)
-			} else {
-				bytes, err := fs.ReadFile(assets, path)
-				if err != nil {
-					return err
-				}
-				return os.WriteFile(filepath.Join(dir, trimmedPath), bytes, 0600)
-			}
-		})
+		err := copySample(dir, "sample")
 		if err != nil {
 			log.Errorf("Failed to create blog: %s\n", err)
 			return 1
@@ -64,3 +49,24 @@ func create(arguments map[string]interface{}) int {
 	log.Infof("Created blog: %s\n", dir)
 	return 0
 }
+
+// copySample copies the embedded sample files in the root (sample or one of
+// its subdirectories) to the directory.
+func copySample(dir, root string) error {
+	return fs.WalkDir(assets, root, func(path string, d fs.DirEntry, err error) error {
+		if path == "sample" {
+			return nil
+		}
+
+		trimmedPath := strings.TrimPrefix(path, "sample/")
+		if d.IsDir() {
+			return os.MkdirAll(filepath.Join(dir, trimmedPath), 0700)
+		} else {
+			bytes, err := fs.ReadFile(assets, path)
+			if err != nil {
+				return err
+			}
+			return os.WriteFile(filepath.Join(dir, trimmedPath), bytes, 0600)
+		}
+	})
+}
@@ -0,0 +1,92 @@
+package cli
+
+import (
+	"time"
+
+	"github.com/mirovarga/litepub/lib"
+)
+
+func daemon(arguments map[string]interface{}) int {
+	dir := arguments["<dir>"].(string)
+	deploy, _ := arguments["--deploy"].(bool)
+
+	var schedule *lib.Schedule
+	if cron, ok := arguments["--cron"].([]string); ok && len(cron) > 0 {
+		parsed, err := lib.ParseSchedule(cron[0])
+		if err != nil {
+			log.Errorf("Failed to parse schedule: %s\n", err)
+			return 1
+		}
+		schedule = &parsed
+	}
+
+	publish := func(reason string) {
+		log.Infof("Rebuilding: %s\n", reason)
+		if rebuild(dir, false) == 0 && deploy {
+			deployBlog(dir)
+		}
+	}
+
+	changes := make(chan string, 1)
+	if arguments["--watch"].(int) == 1 {
+		go watchDirs(dir, func(path string) {
+			select {
+			case changes <- path:
+			default:
+			}
+		})
+		log.Infof("Rebuilding when posts, templates or static files change\n")
+	}
+	log.Infof("Ctrl+C to quit\n")
+
+	publish("started")
+	for {
+		next, reason := nextRebuild(dir, schedule, time.Now())
+
+		var timer *time.Timer
+		var due <-chan time.Time
+		if !next.IsZero() {
+			log.Infof("Next rebuild: %s (%s)\n", next.Format("2006-01-02 15:04"), reason)
+			timer = time.NewTimer(time.Until(next))
+			due = timer.C
+		}
+
+		select {
+		case <-due:
+			publish(reason)
+		case path := <-changes:
+			if timer != nil {
+				timer.Stop()
+			}
+			publish(path + " changed")
+		}
+	}
+}
+
+// nextRebuild returns the time of the next rebuild of the blog in the
+// directory after now and its reason: the next time of the schedule (if it
+// isn't nil) or the time a post is published or expires if it's sooner.
+func nextRebuild(dir string, schedule *lib.Schedule, now time.Time) (time.Time, string) {
+	var next time.Time
+	reason := "schedule"
+	if schedule != nil {
+		next = schedule.Next(now)
+	}
+
+	config, err := lib.ReadConfig(dir)
+	if err != nil {
+		log.Errorf("Failed to read config: %s\n", err)
+		return next, reason
+	}
+	blog, err := readBlog(dir, config)
+	if err != nil {
+		log.Errorf("Failed to read blog: %s\n", err)
+		return next, reason
+	}
+
+	if due := blog.NextDue(now, config.ScheduledPosts); !due.IsZero() &&
+		(next.IsZero() || due.Before(next)) {
+		return due, "post due"
+	}
+	return next, reason
+}
@@ -0,0 +1,61 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/mirovarga/litepub/lib"
+)
+
+func deploy(arguments map[string]interface{}) int {
+	return deployBlog(arguments["<dir>"].(string))
+}
+
+// deployBlog deploys the built blog in the directory with the deploy plugins.
+func deployBlog(dir string) int {
+	config, err := lib.ReadConfig(dir)
+	if err != nil {
+		log.Errorf("Failed to read config: %s\n", err)
+		return 1
+	}
+
+	output, err := filepath.Abs(filepath.Join(dir, outputDir))
+	if err == nil {
+		_, err = os.Stat(output)
+	}
+	if err != nil {
+		log.Errorf("Blog not built: %s\n", err)
+		return 1
+	}
+
+	plugins, err := startPlugins(dir, config)
+	if err != nil {
+		log.Errorf("Failed to start plugins: %s\n", err)
+		return 1
+	}
+	defer stopPlugins(plugins)
+
+	info := lib.BuildInfo{URL: config.URL, OutputDir: output, Started: time.Now()}
+	deployed := 0
+	for _, plugin := range plugins {
+		if !plugin.Provides(lib.PluginDeploy) {
+			continue
+		}
+
+		log.Infof("Deploying: %s\n", plugin.Info().Name)
+		err = plugin.Deploy(info)
+		if err != nil {
+			log.Errorf("Failed to deploy blog: %s\n", err)
+			return 1
+		}
+		deployed++
+	}
+
+	if deployed == 0 {
+		log.Errorf("No deploy plugins configured\n")
+		return 1
+	}
+
+	return 0
+}
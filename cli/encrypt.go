@@ -0,0 +1,29 @@
+package cli
+
+import (
+	"path/filepath"
+
+	"github.com/mirovarga/litepub/lib"
+)
+
+func encrypt(arguments map[string]interface{}) int {
+	dir := arguments["<dir>"].(string)
+
+	config, err := lib.ReadConfig(dir)
+	if err != nil {
+		log.Errorf("Failed to read config: %s\n", err)
+		return 1
+	}
+
+	encrypted, err := lib.EncryptDrafts(filepath.Join(dir, postsDir), config.DraftEncryption)
+	for _, path := range encrypted {
+		log.Infof("Encrypted %s\n", path)
+	}
+	if err != nil {
+		log.Errorf("Failed to encrypt drafts: %s\n", err)
+		return 1
+	}
+
+	log.Infof("\nEncrypted drafts: %d\n", len(encrypted))
+	return 0
+}
@@ -0,0 +1,41 @@
+package cli
+
+import (
+	"strconv"
+
+	"github.com/mirovarga/litepub/lib"
+)
+
+func fixture(arguments map[string]interface{}) int {
+	dir := arguments["<dir>"].(string)
+
+	n, ok := arguments["--posts"].([]string)
+	if !ok {
+		n = []string{"1000"}
+	}
+	posts, err := strconv.Atoi(n[0])
+	if err != nil || posts < 1 {
+		log.Errorf("Invalid number of posts: %s\n", n[0])
+		return 1
+	}
+	seed, err := strconv.ParseInt(arguments["--seed"].(string), 10, 64)
+	if err != nil {
+		log.Errorf("Invalid seed: %s\n", arguments["--seed"])
+		return 1
+	}
+
+	err = copySample(dir, "sample/templates")
+	if err != nil {
+		log.Errorf("Failed to create blog: %s\n", err)
+		return 1
+	}
+
+	err = lib.WriteFixture(dir, posts, seed)
+	if err != nil {
+		log.Errorf("Failed to create posts: %s\n", err)
+		return 1
+	}
+
+	log.Infof("Created blog with %d posts: %s\n", posts, dir)
+	return 0
+}
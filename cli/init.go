@@ -0,0 +1,109 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/mirovarga/litepub/lib"
+)
+
+const initConfig = `# See https://github.com/mirovarga/litepub#configuration for all settings.
+title: My Blog
+# url: https://example.com
+# author: Jane Doe
+`
+
+const initPost = `# Hello, World
+
+*%s*
+
+*general*
+
+This is the first post of the blog. Edit it or add more Markdown files to the
+posts directory, then run **litepub serve --rebuild --watch** and open
+[http://localhost:2703](http://localhost:2703) to see the changes.
+`
+
+const initGitignore = outputDir + "/\n" + cacheDir + "/\n" + lib.RenderCacheDir + "/\n"
+
+// initBlog creates the directory skeleton of a new blog with a configuration
+// file, an example post and the sample templates (or a link to a theme's
+// templates), so it can be built right away.
+func initBlog(arguments map[string]interface{}) int {
+	dir := arguments["<dir>"].(string)
+	var theme string
+	if t, ok := arguments["--theme"].([]string); ok && len(t) > 0 {
+		theme = t[0]
+	}
+
+	for _, name := range []string{lib.ConfigFile, templatesDir} {
+		if _, err := os.Lstat(filepath.Join(dir, name)); err == nil {
+			log.Errorf("Failed to initialize blog: %s already exists\n", filepath.Join(dir, name))
+			return 1
+		}
+	}
+	if _, err := os.Stat(filepath.Join(theme, "layout.tmpl")); theme != "" && err != nil {
+		log.Errorf("Failed to initialize blog: theme not found: %s\n", theme)
+		return 1
+	}
+
+	for _, subdir := range []string{filepath.Join(postsDir, "draft"), staticDir, dataDir} {
+		err := os.MkdirAll(filepath.Join(dir, subdir), 0700)
+		if err != nil {
+			log.Errorf("Failed to initialize blog: %s\n", err)
+			return 1
+		}
+	}
+
+	var err error
+	if theme != "" {
+		err = linkTheme(dir, theme)
+	} else {
+		err = copySample(dir, "sample/templates")
+	}
+	if err != nil {
+		log.Errorf("Failed to initialize blog: %s\n", err)
+		return 1
+	}
+
+	files := map[string]string{
+		lib.ConfigFile: initConfig,
+		filepath.Join(postsDir, "hello-world.md"): fmt.Sprintf(initPost,
+			time.Now().Format("Jan 2, 2006")),
+		".gitignore": initGitignore,
+	}
+	for name, content := range files {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			continue
+		}
+
+		err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0600)
+		if err != nil {
+			log.Errorf("Failed to initialize blog: %s\n", err)
+			return 1
+		}
+	}
+
+	log.Infof("Initialized blog: %s\n", dir)
+	return 0
+}
+
+// linkTheme links the templates directory of the blog in the directory to the
+// theme directory (relative to the blog's directory if possible), so the blog
+// uses the theme's templates.
+func linkTheme(dir, theme string) error {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+	target, err := filepath.Abs(theme)
+	if err != nil {
+		return err
+	}
+	if rel, err := filepath.Rel(absDir, target); err == nil {
+		target = rel
+	}
+	return os.Symlink(target, filepath.Join(dir, templatesDir))
+}
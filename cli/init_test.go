@@ -0,0 +1,117 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mirovarga/litepub/lib"
+)
+
+func TestInitBlog(t *testing.T) {
+	dir := t.TempDir()
+
+	if code := initBlog(map[string]interface{}{"<dir>": dir}); code != 0 {
+		t.Fatalf("want %v, got %v", 0, code)
+	}
+
+	for _, name := range []string{lib.ConfigFile, ".gitignore",
+		filepath.Join(postsDir, "hello-world.md"),
+		filepath.Join(templatesDir, "layout.tmpl"),
+		filepath.Join(templatesDir, "index.tmpl"),
+		filepath.Join(templatesDir, "post.tmpl"),
+		filepath.Join(templatesDir, "tag.tmpl")} {
+		if info, err := os.Stat(filepath.Join(dir, name)); err != nil || info.IsDir() {
+			t.Errorf("want file %v, got %v", name, err)
+		}
+	}
+	for _, name := range []string{filepath.Join(postsDir, "draft"), staticDir, dataDir} {
+		if info, err := os.Stat(filepath.Join(dir, name)); err != nil || !info.IsDir() {
+			t.Errorf("want directory %v, got %v", name, err)
+		}
+	}
+
+	gitignore, err := os.ReadFile(filepath.Join(dir, ".gitignore"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(gitignore) != initGitignore {
+		t.Errorf("want %q, got %q", initGitignore, gitignore)
+	}
+
+	config, err := lib.ReadConfig(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Title != "My Blog" {
+		t.Errorf("want %v, got %v", "My Blog", config.Title)
+	}
+
+	blog, err := lib.NewMarkdownBlog(dir).Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(blog) != 1 || blog[0].Title != "Hello, World" {
+		t.Errorf("want %v, got %v", "[Hello, World]", blog)
+	}
+}
+
+func TestInitBlogKeepsExistingFiles(t *testing.T) {
+	dir := t.TempDir()
+	gitignore := "node_modules/\n"
+	err := os.WriteFile(filepath.Join(dir, ".gitignore"), []byte(gitignore), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if code := initBlog(map[string]interface{}{"<dir>": dir}); code != 0 {
+		t.Fatalf("want %v, got %v", 0, code)
+	}
+
+	content, err := os.ReadFile(filepath.Join(dir, ".gitignore"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != gitignore {
+		t.Errorf("want %q, got %q", gitignore, content)
+	}
+}
+
+func TestInitBlogRefusesExistingBlog(t *testing.T) {
+	for _, name := range []string{lib.ConfigFile, filepath.Join(templatesDir, "layout.tmpl")} {
+		dir := t.TempDir()
+		existing := filepath.Join(dir, name)
+		err := os.MkdirAll(filepath.Dir(existing), 0700)
+		if err == nil {
+			err = os.WriteFile(existing, []byte("title: Existing\n"), 0600)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if code := initBlog(map[string]interface{}{"<dir>": dir}); code != 1 {
+			t.Errorf("%s: want %v, got %v", name, 1, code)
+		}
+
+		if _, err := os.Stat(filepath.Join(dir, postsDir)); !os.IsNotExist(err) {
+			t.Errorf("%s: want no %v, got %v", name, postsDir, err)
+		}
+		content, err := os.ReadFile(existing)
+		if err != nil || string(content) != "title: Existing\n" {
+			t.Errorf("%s: want %q, got %q (%v)", name, "title: Existing\n", content, err)
+		}
+	}
+}
+
+func TestInitBlogMissingTheme(t *testing.T) {
+	dir := t.TempDir()
+
+	code := initBlog(map[string]interface{}{"<dir>": dir,
+		"--theme": []string{filepath.Join(dir, "missing")}})
+	if code != 1 {
+		t.Errorf("want %v, got %v", 1, code)
+	}
+	if _, err := os.Stat(filepath.Join(dir, lib.ConfigFile)); !os.IsNotExist(err) {
+		t.Errorf("want no %v, got %v", lib.ConfigFile, err)
+	}
+}
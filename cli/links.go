@@ -0,0 +1,55 @@
+package cli
+
+import (
+	"path/filepath"
+
+	"github.com/mirovarga/litepub/lib"
+)
+
+func links(arguments map[string]interface{}) int {
+	dir := arguments["<dir>"].(string)
+
+	config, err := lib.ReadConfig(dir)
+	if err != nil {
+		log.Errorf("Failed to read config: %s\n", err)
+		return 1
+	}
+
+	blog, err := readBlog(dir, config)
+	if err != nil {
+		log.Errorf("Failed to read blog: %s\n", err)
+		return 1
+	}
+
+	checker := lib.NewLinkChecker(filepath.Join(dir, lib.LinkCheckFile),
+		config.LinkCheck)
+	links, err := checker.Check(blog.ExternalLinks(arguments["--drafts"].(int) == 1))
+	if err != nil {
+		log.Errorf("Failed to check links: %s\n", err)
+		return 1
+	}
+
+	dead := 0
+	for _, link := range links {
+		if !link.Dead() {
+			continue
+		}
+		dead++
+
+		if link.Error != "" {
+			log.Infof("%s (%s)\n", link.URL, link.Error)
+		} else {
+			log.Infof("%s (%d)\n", link.URL, link.Code)
+		}
+		for _, post := range link.Posts {
+			log.Infof("  %s\n", post.Title)
+		}
+	}
+
+	log.Infof("\nChecked links: %d, dead links: %d\n", len(links), dead)
+
+	if dead > 0 {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,66 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/mirovarga/litepub/lib"
+)
+
+func newsletter(arguments map[string]interface{}) int {
+	dir := arguments["<dir>"].(string)
+
+	config, err := lib.ReadConfig(dir)
+	if err != nil {
+		log.Errorf("Failed to read config: %s\n", err)
+		return 1
+	}
+
+	blog, err := readBlog(dir, config)
+	if err != nil {
+		log.Errorf("Failed to read blog: %s\n", err)
+		return 1
+	}
+
+	snapshots, err := lib.ReadArchive(filepath.Join(dir, lib.ArchiveFile))
+	if err != nil {
+		log.Errorf("Failed to read archive: %s\n", err)
+		return 1
+	}
+
+	gen, err := lib.NewStaticBlogGenerator(blog, filepath.Join(dir, templatesDir),
+		filepath.Join(dir, outputDir), func(string) {}, lib.WithConfig(config),
+		lib.WithStaticDir(filepath.Join(dir, staticDir)), lib.WithWarnFunc(printWarning),
+		lib.WithSnapshots(snapshots), lib.WithCacheDir(filepath.Join(dir, cacheDir)),
+		lib.WithRenderCacheDir(filepath.Join(dir, lib.RenderCacheDir)))
+	if err != nil {
+		log.Errorf("Failed to create generator: %s\n", err)
+		return 1
+	}
+
+	newsletter, err := gen.Newsletter(arguments["--digest"].(int) == 1, time.Now())
+	if err != nil {
+		log.Errorf("Failed to create newsletter: %s\n", err)
+		return 1
+	}
+
+	output := arguments["--output"].([]string)[0]
+	err = os.WriteFile(output, []byte(newsletter.HTML), 0644)
+	if err != nil {
+		log.Errorf("Failed to write newsletter: %s\n", err)
+		return 1
+	}
+	log.Infof("Written: %s (%s)\n", output, newsletter.Subject)
+
+	if arguments["--send"].(int) == 1 {
+		err = newsletter.Send(config.Newsletter)
+		if err != nil {
+			log.Errorf("Failed to send newsletter: %s\n", err)
+			return 1
+		}
+		log.Infof("Sent: %s\n", newsletter.Subject)
+	}
+
+	return 0
+}
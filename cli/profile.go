@@ -0,0 +1,81 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/pprof"
+	"time"
+
+	"github.com/mirovarga/litepub/lib"
+)
+
+// slowestPages is the number of pages listed in the profile summary.
+const slowestPages = 10
+
+// startPprof starts writing the CPU profile to the cpu.pprof file in the
+// directory. The returned function stops it and writes the heap profile to the
+// heap.pprof file.
+func startPprof(dir string) (func() error, error) {
+	err := os.MkdirAll(dir, 0700)
+	if err != nil {
+		return nil, err
+	}
+
+	cpu, err := os.Create(filepath.Join(dir, "cpu.pprof"))
+	if err != nil {
+		return nil, err
+	}
+	err = pprof.StartCPUProfile(cpu)
+	if err != nil {
+		cpu.Close()
+		return nil, err
+	}
+
+	return func() error {
+		pprof.StopCPUProfile()
+		cpu.Close()
+
+		heap, err := os.Create(filepath.Join(dir, "heap.pprof"))
+		if err != nil {
+			return err
+		}
+		defer heap.Close()
+
+		runtime.GC()
+		return pprof.WriteHeapProfile(heap)
+	}, nil
+}
+
+func printProfile(p *lib.Profile) {
+	log.Infof("\nBuild stages (%s total):\n", formatDuration(p.Total()))
+	for _, stage := range p.Stages() {
+		log.Infof("  %s: %s\n", stage.Name, formatDuration(stage.Duration))
+	}
+
+	if render := p.Render(); len(render) > 0 {
+		log.Infof("\nRendering stages:\n")
+		for _, stage := range render {
+			log.Infof("  %s: %s\n", stage.Name, formatDuration(stage.Duration))
+		}
+	}
+
+	log.Infof("\nTemplates:\n")
+	for _, t := range p.Templates() {
+		log.Infof("  %s: %s (%d pages, %s per page)\n", t.Name, formatDuration(t.Duration),
+			t.Count, formatDuration(t.Duration/time.Duration(t.Count)))
+	}
+
+	log.Infof("\nSlowest pages:\n")
+	for _, page := range p.SlowestPages(slowestPages) {
+		log.Infof("  %s: %s (%s)\n", page.Name, formatDuration(page.Duration), page.Template)
+	}
+}
+
+func formatDuration(d time.Duration) string {
+	if d < time.Second {
+		return fmt.Sprintf("%.1fms", float64(d)/float64(time.Millisecond))
+	}
+	return fmt.Sprintf("%.2fs", d.Seconds())
+}
@@ -0,0 +1,14 @@
+// Replaces click-to-load embeds with their players or posts when clicked.
+document.addEventListener('click', function (event) {
+  var link = event.target.closest('.embed-load');
+  if (!link) {
+    return;
+  }
+  event.preventDefault();
+
+  var iframe = document.createElement('iframe');
+  iframe.src = link.parentNode.dataset.embedSrc;
+  iframe.allow = 'autoplay; fullscreen; picture-in-picture';
+  iframe.allowFullscreen = true;
+  link.parentNode.replaceChildren(iframe);
+});
@@ -1,19 +1,28 @@
 package cli
 
 import (
+	"errors"
+	"io/fs"
 	"net/http"
 	"path/filepath"
+	"time"
 
 	"github.com/fsnotify/fsnotify"
+	"github.com/mirovarga/litepub/lib"
 )
 
 const defaultPort = "2703"
 
+// rebuildTimeout is how long rebuilds wait for other builds of the blog to
+// finish.
+const rebuildTimeout = time.Minute
+
 func serve(arguments map[string]interface{}) int {
 	dir := arguments["<dir>"].(string)
+	preview, _ := arguments["--preview"].(bool)
 
 	if arguments["--rebuild"].(int) == 1 {
-		build(map[string]interface{}{"<dir>": dir})
+		rebuild(dir, preview)
 	}
 
 	port, ok := arguments["--port"].([]string)
@@ -24,30 +33,89 @@ func serve(arguments map[string]interface{}) int {
 	watch := arguments["--watch"].(int)
 
 	if watch == 1 {
-		go watchDirs(dir)
+		go watchDirs(dir, func(path string) {
+			log.Infof("Rebuilding: %s changed\n", path)
+			rebuild(dir, preview)
+		})
 	}
 
 	log.Infof("Running on http://localhost:%s\n", port[0])
 	if watch == 1 {
-		log.Infof("Rebuilding when posts or templates change\n")
+		log.Infof("Rebuilding when posts, templates or static files change\n")
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/", http.FileServer(http.Dir(filepath.Join(dir, outputDir))))
+	if micropub, ok := newMicropub(dir, preview); ok {
+		mux.Handle("/micropub", micropub)
+		log.Infof("Accepting Micropub requests on http://localhost:%s/micropub\n", port[0])
 	}
 	log.Infof("Ctrl+C to quit\n")
 
-	http.ListenAndServe(":"+port[0], http.FileServer(http.Dir(filepath.Join(dir, outputDir))))
+	http.ListenAndServe(":"+port[0], mux)
 	return 0
 }
 
-func watchDirs(dir string) {
+// newMicropub creates the Micropub endpoint of the blog in the directory if
+// its authentication is configured. The blog is rebuilt (as a preview if
+// preview == true) after each change.
+func newMicropub(dir string, preview bool) (lib.Micropub, bool) {
+	config, err := lib.ReadConfig(dir)
+	if err != nil {
+		log.Errorf("Failed to read config: %s\n", err)
+		return lib.Micropub{}, false
+	}
+
+	rebuild := func() error {
+		if rebuild(dir, preview) != 0 {
+			return errors.New("failed to rebuild blog")
+		}
+		return nil
+	}
+	var store lib.PostStore = lib.NewDirStore(filepath.Join(dir, postsDir))
+	if config.DraftEncryption.Tool != "" {
+		store = lib.NewEncryptedStore(store, config.DraftEncryption)
+	}
+	micropub, err := lib.NewMicropub(store, rebuild, lib.WithConfig(config))
+	if err != nil && !errors.Is(err, lib.ErrMicropubDisabled) {
+		log.Errorf("Failed to create Micropub endpoint: %s\n", err)
+	}
+	return micropub, err == nil
+}
+
+// rebuild builds the blog in the directory (as a preview if preview == true)
+// reporting which files of its output directory changed.
+func rebuild(dir string, preview bool) int {
+	return buildLocked(dir, buildFlags{preview: preview, changes: true}, rebuildTimeout)
+}
+
+// watchDirs calls the changed function with the path (relative to the
+// directory) of each changed post, comment, template, static or data file of
+// the blog in the directory.
+func watchDirs(dir string, changed func(path string)) {
 	watcher, _ := fsnotify.NewWatcher()
 	defer watcher.Close()
 
-	watcher.Add(filepath.Join(dir, postsDir))
+	for _, root := range []string{postsDir, commentsDir} {
+		filepath.WalkDir(filepath.Join(dir, root),
+			func(path string, d fs.DirEntry, err error) error {
+				if err == nil && d.IsDir() {
+					watcher.Add(path)
+				}
+				return nil
+			})
+	}
 	watcher.Add(filepath.Join(dir, templatesDir))
+	watcher.Add(filepath.Join(dir, staticDir))
+	watcher.Add(filepath.Join(dir, dataDir))
 
 	for {
 		select {
-		case <-watcher.Events:
-			build(map[string]interface{}{"<dir>": dir})
+		case event := <-watcher.Events:
+			path, err := filepath.Rel(dir, event.Name)
+			if err != nil {
+				path = event.Name
+			}
+			changed(path)
 		}
 	}
 }
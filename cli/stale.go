@@ -0,0 +1,54 @@
+package cli
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mirovarga/litepub/lib"
+)
+
+func stale(arguments map[string]interface{}) int {
+	dir := arguments["<dir>"].(string)
+
+	config, err := lib.ReadConfig(dir)
+	if err != nil {
+		log.Errorf("Failed to read config: %s\n", err)
+		return 1
+	}
+
+	if y, ok := arguments["--years"].([]string); ok && len(y) > 0 {
+		years, err := strconv.Atoi(y[0])
+		if err != nil || years <= 0 {
+			log.Errorf("Invalid number of years: %s\n", y[0])
+			return 1
+		}
+		config.Stale.Years = years
+	}
+
+	blog, err := readBlog(dir, config)
+	if err != nil {
+		log.Errorf("Failed to read blog: %s\n", err)
+		return 1
+	}
+
+	posts, err := blog.Stale(config.Stale, time.Now(), arguments["--drafts"].(int) == 1)
+	if err != nil {
+		log.Errorf("Failed to find stale posts: %s\n", err)
+		return 1
+	}
+
+	for _, post := range posts {
+		log.Infof("%s (updated %s)\n", post.Post.Title, post.Updated.Format("2006-01-02"))
+		if len(post.Links) > 0 {
+			log.Infof("  external links: %d\n", len(post.Links))
+		}
+		if len(post.Markers) > 0 {
+			log.Infof("  markers: %s\n", strings.Join(post.Markers, ", "))
+		}
+	}
+
+	log.Infof("\nStale posts: %d\n", len(posts))
+
+	return 0
+}
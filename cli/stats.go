@@ -0,0 +1,63 @@
+package cli
+
+import (
+	"sort"
+
+	"github.com/mirovarga/litepub/lib"
+)
+
+func stats(arguments map[string]interface{}) int {
+	dir := arguments["<dir>"].(string)
+
+	config, err := lib.ReadConfig(dir)
+	if err != nil {
+		log.Errorf("Failed to read config: %s\n", err)
+		return 1
+	}
+
+	blog, err := readBlog(dir, config)
+	if err != nil {
+		log.Errorf("Failed to read blog: %s\n", err)
+		return 1
+	}
+
+	stats := blog.Stats(arguments["--drafts"].(int) == 1)
+
+	log.Infof("Posts: %d\n", stats.Posts)
+	log.Infof("Words: %d (%d per post on average)\n", stats.Words,
+		stats.AverageWords)
+
+	var years []int
+	for year := range stats.PostsByYear {
+		years = append(years, year)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(years)))
+
+	log.Infof("\nPosts by year:\n")
+	for _, year := range years {
+		log.Infof("  %d: %d\n", year, stats.PostsByYear[year])
+	}
+
+	var tags []string
+	for tag := range stats.PostsByTag {
+		tags = append(tags, tag)
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		if stats.PostsByTag[tags[i]] == stats.PostsByTag[tags[j]] {
+			return tags[i] < tags[j]
+		}
+		return stats.PostsByTag[tags[i]] > stats.PostsByTag[tags[j]]
+	})
+
+	log.Infof("\nPosts by tag:\n")
+	for _, tag := range tags {
+		log.Infof("  %s: %d\n", tag, stats.PostsByTag[tag])
+	}
+
+	log.Infof("\nLongest posts:\n")
+	for _, post := range stats.LongestPosts {
+		log.Infof("  %s (%d words)\n", post.Title, post.WordCount())
+	}
+
+	return 0
+}
@@ -6,18 +6,42 @@ Copyright (c) 2024 Miro Varga [mirovarga.com]
 
 Usage:
   litepub create [<dir>] [-s, --skeleton] [-q, --quiet]
-  litepub build  [<dir>] [-q, --quiet]
-  litepub serve  [<dir>] [-R, --rebuild] [-p, --port <port>] [-w, --watch] [-q, --quiet]
+  litepub init   [<dir>] [-t, --theme <theme>] [-q, --quiet]
+  litepub build  [<dir>] [-r, --relative] [--preview] [-P, --profile] [--pprof <dir>] [--wait <timeout>] [-q, --quiet]
+  litepub serve  [<dir>] [-R, --rebuild] [-p, --port <port>] [-w, --watch] [--preview] [-q, --quiet]
+  litepub stats  [<dir>] [-d, --drafts]
+  litepub links  [<dir>] [-d, --drafts] [-q, --quiet]
+  litepub stale  [<dir>] [-y, --years <n>] [-d, --drafts] [-q, --quiet]
+  litepub encrypt [<dir>] [-q, --quiet]
+  litepub newsletter [<dir>] [-D, --digest] [-o, --output <file>] [-S, --send] [-q, --quiet]
+  litepub deploy [<dir>] [-q, --quiet]
+  litepub daemon [<dir>] [-c, --cron <schedule>] [-w, --watch] [--deploy] [-q, --quiet]
+  litepub fixture [<dir>] [-n, --posts <n>] [--seed <seed>] [-q, --quiet]
 
 Arguments:
   <dir>  The directory to create the blog in or look for; it will be created if
-         it doesn't exist (only when creating a blog) [default: .]
+         it doesn't exist (only when creating or initializing a blog) [default: .]
 
 Options:
   -s, --skeleton     Don't create sample posts and templates
+  -t, --theme <theme>  Link the templates to a theme directory instead of copying the sample ones
   -R, --rebuild      Rebuild the blog before serving
   -p, --port <port>  The port to listen on [default: 2703]
-  -w, --watch        Rebuild the blog when posts or templates change
+  -w, --watch        Rebuild the blog when posts, templates or static files change
+  --preview          Include draft and scheduled posts
+  -d, --drafts       Include draft posts
+  -D, --digest       Include the posts of the last days instead of the latest post
+  -o, --output <file>  The file to write the newsletter to [default: newsletter.html]
+  -S, --send         Send the newsletter
+  -r, --relative     Use relative links to browse the blog without a server
+  -P, --profile      Print timings of build stages, templates and pages
+  --pprof <dir>      Write CPU and heap profiles to the directory
+  --wait <timeout>   Wait for another build to finish (for example 30s) [default: 0s]
+  -y, --years <n>    Report posts not updated in the number of years (overrides the config)
+  -n, --posts <n>    The number of posts to create [default: 1000]
+  --seed <seed>      The seed of the created posts [default: 1]
+  -c, --cron <schedule>  Rebuild the blog on the cron schedule (for example "0 * * * *")
+  --deploy           Deploy the blog after each rebuild
   -q, --quiet        Show only errors
   -h, --help         Show this screen
   -v, --version      Show version
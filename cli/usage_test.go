@@ -0,0 +1,44 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/docopt/docopt-go"
+)
+
+// TestUsageArgumentTypes checks that the arguments parsed from the usage have
+// the types the commands assert (docopt returns options without a short form
+// as bool and options with one as int).
+func TestUsageArgumentTypes(t *testing.T) {
+	tests := []struct {
+		argv []string
+		key  string
+		want interface{}
+	}{
+		{[]string{"serve"}, "--preview", false},
+		{[]string{"serve", "--preview"}, "--preview", true},
+		{[]string{"serve", "-R", "-w"}, "--rebuild", 1},
+		{[]string{"serve", "-R", "-w"}, "--watch", 1},
+		{[]string{"build"}, "--preview", false},
+		{[]string{"build", "--preview"}, "--preview", true},
+		{[]string{"build", "-r"}, "--relative", 1},
+		{[]string{"build", "-P"}, "--profile", 1},
+		{[]string{"build"}, "--wait", "0s"},
+		{[]string{"daemon", "--deploy"}, "--deploy", true},
+		{[]string{"daemon", "-w"}, "--watch", 1},
+		{[]string{"stats", "-d"}, "--drafts", 1},
+		{[]string{"create", "-s"}, "--skeleton", 1},
+		{[]string{"newsletter", "-D", "-S"}, "--send", 1},
+		{[]string{"fixture"}, "--seed", "1"},
+	}
+
+	for _, test := range tests {
+		arguments, err := docopt.ParseArgs(usage, test.argv, "LitePub test")
+		if err != nil {
+			t.Fatalf("%v: %s", test.argv, err)
+		}
+		if got := arguments[test.key]; got != test.want {
+			t.Errorf("%v: want %s = %#v, got %#v", test.argv, test.key, test.want, got)
+		}
+	}
+}
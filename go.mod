@@ -6,11 +6,13 @@ require (
 	github.com/docopt/docopt-go v0.0.0-20180111231733-ee0de3bc6815
 	github.com/fsnotify/fsnotify v1.4.9
 	github.com/gosimple/slug v1.10.0
+	github.com/niklasfasching/go-org v1.7.0
 	github.com/russross/blackfriday v1.6.0
 	github.com/termie/go-shutil v0.0.0-20140729215957-bcacb06fecae
+	golang.org/x/crypto v0.33.0
+	golang.org/x/net v0.21.0
+	golang.org/x/sys v0.30.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
-require (
-	github.com/gosimple/unidecode v1.0.0 // indirect
-	golang.org/x/sys v0.0.0-20220722155257-8c9f86f7a55f // indirect
-)
+require github.com/gosimple/unidecode v1.0.0 // indirect
@@ -0,0 +1,120 @@
+package lib
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// ArchiveFile is the name of the file in the Blog directory that holds
+// snapshot URLs of external links archived by the Wayback Machine.
+const ArchiveFile = "archive.json"
+
+const waybackURL = "https://web.archive.org"
+
+// ReadArchive reads snapshot URLs keyed by the archived links' URLs from the
+// file. If the file doesn't exist it returns an empty map.
+func ReadArchive(path string) (map[string]string, error) {
+	snapshots := map[string]string{}
+
+	bytes, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return snapshots, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return snapshots, json.Unmarshal(bytes, &snapshots)
+}
+
+// Archiver submits external links to the Wayback Machine and records their
+// snapshot URLs in a file.
+type Archiver struct {
+	file    string
+	saveURL string
+	client  *http.Client
+}
+
+// NewArchiver creates an Archiver that records snapshot URLs in the file.
+func NewArchiver(file string) Archiver {
+	return Archiver{file: file, saveURL: waybackURL + "/save/",
+		client: &http.Client{Timeout: time.Minute}}
+}
+
+// Archive submits the links without snapshots to the Wayback Machine and
+// returns the snapshot URLs of all archived links. Links that fail to be
+// archived are reported to the warnFunc and submitted again next time.
+func (a Archiver) Archive(links []ExternalLink, progressFunc ProgressFunc,
+	warnFunc func(message string)) (map[string]string, error) {
+	snapshots, err := ReadArchive(a.file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archive: %s", err)
+	}
+
+	archived := false
+	for _, link := range links {
+		if _, ok := snapshots[link.URL]; ok {
+			continue
+		}
+
+		progressFunc(link.URL)
+
+		snapshot, err := a.save(link.URL)
+		if err != nil {
+			warnFunc(fmt.Sprintf("failed to archive %s: %s", link.URL, err))
+			continue
+		}
+		snapshots[link.URL] = snapshot
+		archived = true
+	}
+
+	if !archived {
+		return snapshots, nil
+	}
+
+	bytes, err := json.MarshalIndent(snapshots, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	err = os.WriteFile(a.file, bytes, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to write archive: %s", err)
+	}
+
+	return snapshots, nil
+}
+
+// save submits the URL to the Wayback Machine and returns the snapshot URL.
+// The Wayback Machine redirects to the snapshot or returns its path in the
+// Content-Location header.
+func (a Archiver) save(url string) (string, error) {
+	resp, err := a.client.Get(a.saveURL + url)
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return "", fmt.Errorf("unexpected status: %s", resp.Status)
+	}
+
+	if location := resp.Header.Get("Content-Location"); strings.HasPrefix(location, "/web/") {
+		return waybackURL + location, nil
+	}
+	if final := resp.Request.URL; strings.HasPrefix(final.Path, "/web/") {
+		return waybackURL + final.RequestURI(), nil
+	}
+	return "", errors.New("no snapshot URL in the response")
+}
+
+// archived returns the snapshot URL of the archived link or an empty string
+// if it isn't archived.
+func (g StaticBlogGenerator) archived(url string) string {
+	return g.options.snapshots[url]
+}
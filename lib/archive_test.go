@@ -0,0 +1,50 @@
+package lib
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestArchiverArchive(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if strings.HasSuffix(r.URL.Path, "/fail") {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Location", "/web/20240101000000/"+strings.TrimPrefix(r.URL.Path, "/save/"))
+	}))
+	defer server.Close()
+
+	archiver := NewArchiver(filepath.Join(t.TempDir(), ArchiveFile))
+	archiver.saveURL = server.URL + "/save/"
+
+	links := []ExternalLink{{URL: "https://example.com/ok"}, {URL: "https://example.com/fail"}}
+	var warnings []string
+	warn := func(message string) { warnings = append(warnings, message) }
+
+	snapshots, err := archiver.Archive(links, func(string) {}, warn)
+	if err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+
+	want := waybackURL + "/web/20240101000000/https://example.com/ok"
+	if snapshots["https://example.com/ok"] != want {
+		t.Errorf("want %q, got %q", want, snapshots["https://example.com/ok"])
+	}
+	if len(warnings) != 1 {
+		t.Errorf("want %v, got %v", 1, len(warnings))
+	}
+
+	_, err = archiver.Archive(links, func(string) {}, warn)
+	if err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+	if requests != 3 {
+		t.Errorf("want %v, got %v", 3, requests)
+	}
+}
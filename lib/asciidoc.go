@@ -0,0 +1,107 @@
+package lib
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+const defaultAsciidoctor = "asciidoctor"
+
+// asciidocToPost parses a post written in AsciiDoc. The front matter (see
+// frontMatter) is optional:
+//
+//	---
+//	updated: 2009-01-10
+//	---
+//
+//	= Title
+//	:date: Jan 2, 2009
+//	:tags: tag1, tag2
+//	:page:
+//
+//	Content
+//
+// The date is parsed by parsePostDate. The whole document is converted to
+// HTML by the convert function.
+func asciidocToPost(source string, loc *time.Location,
+	convert func(string) (string, error)) (Post, error) {
+	fm, doc, err := splitFrontMatter(strings.ReplaceAll(source, "\r\n", "\n"))
+	if err != nil {
+		return Post{}, err
+	}
+
+	header := strings.SplitN(doc, "\n\n", 2)[0]
+	lines := strings.Split(header, "\n")
+	if !strings.HasPrefix(lines[0], "= ") {
+		return Post{}, fmt.Errorf("title is missing")
+	}
+
+	post := Post{Title: strings.TrimSpace(strings.TrimPrefix(lines[0], "= ")),
+		Format: FormatHTML}
+
+	for _, line := range lines[1:] {
+		if !strings.HasPrefix(line, ":") {
+			continue
+		}
+
+		parts := strings.SplitN(strings.TrimPrefix(line, ":"), ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		name, value := parts[0], strings.TrimSpace(parts[1])
+
+		switch name {
+		case "date", "revdate":
+			post.Written, err = parsePostDate(value, loc)
+			if err != nil {
+				return Post{}, fmt.Errorf("failed to parse date: %s", err)
+			}
+		case "tags":
+			post.Tags = splitTags(value, ",")
+		case "page":
+			post.IsPage = true
+		}
+	}
+
+	if post.Written.IsZero() {
+		return Post{}, fmt.Errorf("date is missing")
+	}
+
+	post.Content, err = convert(doc)
+	if err != nil {
+		return Post{}, fmt.Errorf("failed to convert AsciiDoc: %s", err)
+	}
+
+	err = fm.apply(&post, loc)
+	if err != nil {
+		return Post{}, err
+	}
+
+	return post, nil
+}
+
+// asciidoctor converts an AsciiDoc document to HTML (without the document
+// header) using the configured Asciidoctor command.
+func (b MarkdownBlog) asciidoctor(doc string) (string, error) {
+	command := strings.Fields(b.options.config.Asciidoctor)
+	if len(command) == 0 {
+		command = []string{defaultAsciidoctor}
+	}
+
+	return newRenderCache(b.options).transform(func() (string, error) {
+		cmd := exec.Command(command[0], append(command[1:], "-s", "-o", "-", "-")...)
+		cmd.Stdin = strings.NewReader(doc)
+
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+
+		out, err := cmd.Output()
+		if err != nil {
+			return "", fmt.Errorf("%s: %s", err, strings.TrimSpace(stderr.String()))
+		}
+		return string(out), nil
+	}, "asciidoctor", strings.Join(command, " "), doc)
+}
@@ -0,0 +1,34 @@
+package lib
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAsciidocToPost(t *testing.T) {
+	adoc := "= A title\n:date: Aug 10, 2021\n:tags: Test, AsciiDoc\n\nTesting *AsciiDoc*\n"
+
+	post, err := asciidocToPost(adoc, time.UTC, func(doc string) (string, error) {
+		return "<p>converted</p>", nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if post.Title != "A title" {
+		t.Errorf("want %v, got %v", "A title", post.Title)
+	}
+
+	want := time.Date(2021, 8, 10, 0, 0, 0, 0, time.UTC)
+	if !post.Written.Equal(want) {
+		t.Errorf("want %v, got %v", want, post.Written)
+	}
+
+	if len(post.Tags) != 2 || post.Tags[1] != "AsciiDoc" {
+		t.Errorf("want %v, got %v", []string{"Test", "AsciiDoc"}, post.Tags)
+	}
+
+	if post.Format != FormatHTML || post.Content != "<p>converted</p>" {
+		t.Errorf("want HTML content, got %v %q", post.Format, post.Content)
+	}
+}
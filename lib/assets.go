@@ -0,0 +1,185 @@
+package lib
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/termie/go-shutil"
+)
+
+// Symlink handling modes used when copying the templates and static
+// directories.
+const (
+	// SymlinksPreserve copies symlinks as symlinks.
+	SymlinksPreserve = "preserve"
+
+	// SymlinksFollow copies the files and directories symlinks point to.
+	SymlinksFollow = "follow"
+
+	// SymlinksError fails the generation if there's a symlink.
+	SymlinksError = "error"
+)
+
+// copier copies directories to the output directory.
+type copier struct {
+	symlinks string
+
+	// ignored reports whether a file (or directory) with the path relative to
+	// the copied directory shouldn't be copied.
+	ignored func(rel string, isDir bool) bool
+
+	// visited holds the real paths of followed directories to stop symlink
+	// loops.
+	visited map[string]bool
+}
+
+func newCopier(symlinks string, ignored func(string, bool) bool) (copier, error) {
+	switch symlinks {
+	case "":
+		symlinks = SymlinksPreserve
+	case SymlinksPreserve, SymlinksFollow, SymlinksError:
+	default:
+		return copier{}, fmt.Errorf("unsupported symlinks mode: %s", symlinks)
+	}
+
+	if ignored == nil {
+		ignored = func(string, bool) bool { return false }
+	}
+	return copier{symlinks, ignored, map[string]bool{}}, nil
+}
+
+// copy copies the contents of the src directory to the dst directory, merging
+// them with the existing contents and overwriting existing files.
+func (c copier) copy(src, dst string) error {
+	return c.copyDir(src, dst, "")
+}
+
+func (c copier) copyDir(src, dst, rel string) error {
+	if real, err := filepath.EvalSymlinks(src); err == nil {
+		if c.visited[real] {
+			return fmt.Errorf("symlink loop: %s", src)
+		}
+		c.visited[real] = true
+		defer delete(c.visited, real)
+	}
+
+	entries, err := os.ReadDir(src)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(dst, 0700)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		srcPath := filepath.Join(src, entry.Name())
+		dstPath := filepath.Join(dst, entry.Name())
+		entryRel := path.Join(rel, entry.Name())
+
+		info, err := os.Lstat(srcPath)
+		if err != nil {
+			return err
+		}
+
+		if info.Mode()&os.ModeSymlink != 0 {
+			switch c.symlinks {
+			case SymlinksError:
+				return fmt.Errorf("symlinks aren't allowed: %s", srcPath)
+			case SymlinksPreserve:
+				if c.ignored(entryRel, false) {
+					continue
+				}
+				err = preserveSymlink(srcPath, dstPath)
+				if err != nil {
+					return err
+				}
+				continue
+			}
+
+			// the resolved path is copied, because go-shutil resolves
+			// relative targets against the working directory
+			srcPath, err = filepath.EvalSymlinks(srcPath)
+			if err != nil {
+				return err
+			}
+			info, err = os.Stat(srcPath)
+			if err != nil {
+				return err
+			}
+		}
+
+		if c.ignored(entryRel, info.IsDir()) {
+			continue
+		}
+
+		if info.IsDir() {
+			err = c.copyDir(srcPath, dstPath, entryRel)
+		} else {
+			_, err = shutil.Copy(srcPath, dstPath, true)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func preserveSymlink(src, dst string) error {
+	link, err := os.Readlink(src)
+	if err != nil {
+		return err
+	}
+
+	os.Remove(dst)
+	return os.Symlink(link, dst)
+}
+
+// ignoredTemplateFile reports whether a file in the templates directory
+// shouldn't be copied: it's one of the templateFiles, a tag template (see
+// tagTemplateFile) in the templates directory itself or it matches a
+// configured Ignore pattern.
+func (g StaticBlogGenerator) ignoredTemplateFile(rel string, isDir bool) bool {
+	for _, name := range templateFiles {
+		if rel == name && !isDir {
+			return true
+		}
+	}
+	if matched, _ := path.Match(tagTemplateFile("*"), rel); matched && !isDir {
+		return true
+	}
+
+	for _, pattern := range g.options.config.Ignore {
+		if ignoreMatch(pattern, rel, isDir) {
+			return true
+		}
+	}
+	return false
+}
+
+// ignoreMatch reports whether the path (relative to the copied directory)
+// matches the pattern. Patterns ending with / match only directories. Patterns
+// containing / are matched against the whole path, other patterns against the
+// base name.
+func ignoreMatch(pattern, path string, isDir bool) bool {
+	if strings.HasSuffix(pattern, "/") {
+		if !isDir {
+			return false
+		}
+		pattern = strings.TrimSuffix(pattern, "/")
+	}
+
+	name := filepath.Base(path)
+	if strings.Contains(pattern, "/") {
+		name = path
+		pattern = strings.TrimPrefix(pattern, "/")
+	}
+
+	matched, _ := filepath.Match(pattern, name)
+	return matched
+}
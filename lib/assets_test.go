@@ -0,0 +1,172 @@
+package lib
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIgnoreMatch(t *testing.T) {
+	tests := []struct {
+		pattern, path string
+		isDir, want   bool
+	}{
+		{"*.tmpl", "partials/header.tmpl", false, true},
+		{".DS_Store", "images/.DS_Store", false, true},
+		{"node_modules/", "js/node_modules", true, true},
+		{"node_modules/", "node_modules", false, false},
+		{"css/*.scss", "css/main.scss", false, true},
+		{"css/*.scss", "sass/main.scss", false, false},
+	}
+
+	for _, test := range tests {
+		if got := ignoreMatch(test.pattern, test.path, test.isDir); got != test.want {
+			t.Errorf("%s %s: want %v, got %v", test.pattern, test.path, test.want, got)
+		}
+	}
+}
+
+func TestIgnoredTemplateFile(t *testing.T) {
+	tests := []struct {
+		rel         string
+		isDir, want bool
+	}{
+		{"post.tmpl", false, true},
+		{"tag-go.tmpl", false, true},
+		{"docs/post.tmpl", false, false},
+		{"examples/tag-go.tmpl", false, false},
+		{"post.tmpl", true, false},
+	}
+
+	for _, test := range tests {
+		if got := (StaticBlogGenerator{}).ignoredTemplateFile(test.rel, test.isDir); got != test.want {
+			t.Errorf("%s: want %v, got %v", test.rel, test.want, got)
+		}
+	}
+}
+
+// writeSymlinkedDir writes a directory with a file, a subdirectory and
+// symlinks to both.
+func writeSymlinkedDir(t *testing.T) string {
+	src := t.TempDir()
+	os.MkdirAll(filepath.Join(src, "sub"), 0700)
+	os.WriteFile(filepath.Join(src, "a.txt"), []byte("a"), 0600)
+	os.WriteFile(filepath.Join(src, "sub", "b.txt"), []byte("b"), 0600)
+	for link, target := range map[string]string{"file-link": "a.txt", "dir-link": "sub"} {
+		if err := os.Symlink(target, filepath.Join(src, link)); err != nil {
+			t.Skipf("symlinks aren't supported: %s", err)
+		}
+	}
+	return src
+}
+
+func TestCopierSymlinks(t *testing.T) {
+	tests := []struct {
+		symlinks string
+		links    bool
+	}{
+		{"", true},
+		{SymlinksPreserve, true},
+		{SymlinksFollow, false},
+	}
+
+	for _, test := range tests {
+		src, dst := writeSymlinkedDir(t), t.TempDir()
+		c, err := newCopier(test.symlinks, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := c.copy(src, dst); err != nil {
+			t.Fatalf("%s: %s", test.symlinks, err)
+		}
+
+		for path, want := range map[string]string{"file-link": "a", filepath.Join("dir-link", "b.txt"): "b"} {
+			if content, _ := os.ReadFile(filepath.Join(dst, path)); string(content) != want {
+				t.Errorf("%s %s: want %v, got %s", test.symlinks, path, want, content)
+			}
+		}
+		for _, link := range []string{"file-link", "dir-link"} {
+			info, err := os.Lstat(filepath.Join(dst, link))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := info.Mode()&os.ModeSymlink != 0; got != test.links {
+				t.Errorf("%s %s: want %v, got %v", test.symlinks, link, test.links, got)
+			}
+		}
+	}
+
+	c, _ := newCopier(SymlinksError, nil)
+	if err := c.copy(writeSymlinkedDir(t), t.TempDir()); err == nil ||
+		!strings.HasPrefix(err.Error(), "symlinks aren't allowed") {
+		t.Errorf("want %v, got %v", "symlinks aren't allowed", err)
+	}
+
+	if _, err := newCopier("copy", nil); err == nil {
+		t.Errorf("want %v, got %v", "an error", err)
+	}
+}
+
+func TestCopierSymlinkLoop(t *testing.T) {
+	src := writeSymlinkedDir(t)
+	os.Symlink("..", filepath.Join(src, "sub", "parent"))
+
+	c, _ := newCopier(SymlinksFollow, nil)
+	if err := c.copy(src, t.TempDir()); err == nil || !strings.HasPrefix(err.Error(), "symlink loop") {
+		t.Errorf("want %v, got %v", "symlink loop", err)
+	}
+
+	// preserved symlinks aren't followed, so they can't loop
+	c, _ = newCopier(SymlinksPreserve, nil)
+	if err := c.copy(src, t.TempDir()); err != nil {
+		t.Errorf("want %v, got %v", nil, err)
+	}
+}
+
+func TestStaticDir(t *testing.T) {
+	templates, static := writeTestTemplates(t), t.TempDir()
+	os.WriteFile(filepath.Join(templates, "style.css"), []byte("body { color: black; }"), 0600)
+	files := map[string]string{
+		"robots.txt":                              "User-agent: *\nDisallow:\n",
+		filepath.Join("img", "a.bin"):             "\x00\x01\xfe\xff\r\n",
+		filepath.Join("img", "icons", "logo.svg"): "<svg/>",
+		// static files take precedence over template and generated files
+		"style.css":  "body { color: red; }",
+		"index.html": "Custom index",
+	}
+	for name, content := range files {
+		os.MkdirAll(filepath.Join(static, filepath.Dir(name)), 0700)
+		os.WriteFile(filepath.Join(static, name), []byte(content), 0600)
+	}
+
+	output := t.TempDir()
+	g, err := NewStaticBlogGenerator(Blog{{Title: "Rome", Written: time.Now()}}, templates, output,
+		func(string) {}, WithStaticDir(static))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Generate(); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, want := range files {
+		if got, _ := os.ReadFile(filepath.Join(output, name)); string(got) != want {
+			t.Errorf("%s: want %q, got %q", name, want, got)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(output, "rome.html")); err != nil {
+		t.Errorf("want %v, got %v", "rome.html", err)
+	}
+
+	// a missing static directory is ignored
+	g, err = NewStaticBlogGenerator(Blog{}, templates, t.TempDir(), func(string) {},
+		WithStaticDir(filepath.Join(static, "missing")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Generate(); err != nil {
+		t.Errorf("want %v, got %v", nil, err)
+	}
+}
@@ -0,0 +1,146 @@
+package lib
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	nethtml "golang.org/x/net/html"
+)
+
+// auditIssue is an issue (for example an accessibility issue) found on a line
+// of a page.
+type auditIssue struct {
+	line    int
+	message string
+}
+
+// auditOutput checks the generated HTML files for images without alt text,
+// headings skipping levels, pages without the lang attribute and links
+// without text and reports the issues as warnings.
+func (g StaticBlogGenerator) auditOutput() error {
+	titles := map[string]string{}
+	for _, post := range append(g.posts[:len(g.posts):len(g.posts)], g.unlisted...) {
+		titles[filepath.ToSlash(g.postPath(post))] = post.Title
+	}
+
+	return filepath.WalkDir(g.outputDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || filepath.Ext(p) != ".html" {
+			return err
+		}
+
+		file, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		rel, err := filepath.Rel(g.outputDir, p)
+		if err != nil {
+			return err
+		}
+		page := filepath.ToSlash(rel)
+
+		source := page
+		if title, ok := titles[page]; ok {
+			source = fmt.Sprintf("%s (%s)", page, title)
+		}
+
+		issues, err := auditHTML(file)
+		if err != nil {
+			return err
+		}
+		for _, issue := range issues {
+			g.options.warn(fmt.Sprintf("accessibility issue in %s on line %d: %s",
+				source, issue.line, issue.message))
+		}
+		return nil
+	})
+}
+
+// auditHTML returns the accessibility issues of the HTML page. Redirect pages
+// aren't required to have the lang attribute.
+func auditHTML(r io.Reader) ([]auditIssue, error) {
+	var issues []auditIssue
+	line, htmlLine, heading := 1, 0, 0
+	hasLang, redirect := false, false
+
+	type openLink struct {
+		line    int
+		href    string
+		hasText bool
+	}
+	var link *openLink
+
+	z := nethtml.NewTokenizer(r)
+	for {
+		tt := z.Next()
+		if tt == nethtml.ErrorToken {
+			if z.Err() != io.EOF {
+				return nil, z.Err()
+			}
+			break
+		}
+		tokenLine := line
+		line += strings.Count(string(z.Raw()), "\n")
+		token := z.Token()
+
+		switch tt {
+		case nethtml.TextToken:
+			if link != nil && strings.TrimSpace(token.Data) != "" {
+				link.hasText = true
+			}
+		case nethtml.StartTagToken, nethtml.SelfClosingTagToken:
+			attrs := map[string]string{}
+			for _, attr := range token.Attr {
+				attrs[attr.Key] = attr.Val
+			}
+
+			switch token.Data {
+			case "html":
+				htmlLine = tokenLine
+				hasLang = strings.TrimSpace(attrs["lang"]) != ""
+			case "meta":
+				redirect = redirect || strings.EqualFold(attrs["http-equiv"], "refresh")
+			case "img":
+				alt, ok := attrs["alt"]
+				if !ok {
+					issues = append(issues, auditIssue{tokenLine,
+						fmt.Sprintf("image without alt text: %s", attrs["src"])})
+				}
+				if link != nil && strings.TrimSpace(alt) != "" {
+					link.hasText = true
+				}
+			case "h1", "h2", "h3", "h4", "h5", "h6":
+				level := int(token.Data[1] - '0')
+				if heading > 0 && level > heading+1 {
+					issues = append(issues, auditIssue{tokenLine,
+						fmt.Sprintf("heading level jumps from h%d to h%d", heading, level)})
+				}
+				heading = level
+			case "a":
+				href, ok := attrs["href"]
+				if ok && tt == nethtml.StartTagToken {
+					label := strings.TrimSpace(attrs["aria-label"] + attrs["title"])
+					link = &openLink{tokenLine, href, label != ""}
+				}
+			}
+		case nethtml.EndTagToken:
+			if token.Data == "a" && link != nil {
+				if !link.hasText {
+					issues = append(issues, auditIssue{link.line,
+						fmt.Sprintf("link without text: %s", link.href)})
+				}
+				link = nil
+			}
+		}
+	}
+
+	if htmlLine > 0 && !hasLang && !redirect {
+		issues = append(issues, auditIssue{htmlLine, "page without the lang attribute"})
+	}
+	return issues, nil
+}
@@ -0,0 +1,66 @@
+package lib
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAuditHTML(t *testing.T) {
+	issues, err := auditHTML(strings.NewReader(`<!DOCTYPE html>
+<html>
+<body>
+<h1>Rome</h1>
+<h3>Sights</h3>
+<img src="/colosseum.jpg">
+<img src="/line.png" alt="">
+<a href="/tags/rome.html"></a>
+<a href="/"><img src="/logo.png" alt="Home"></a>
+<a href="/feed.xml" aria-label="Feed"></a>
+<h4>Food</h4>
+</body>
+</html>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []auditIssue{
+		{5, "heading level jumps from h1 to h3"},
+		{6, "image without alt text: /colosseum.jpg"},
+		{8, "link without text: /tags/rome.html"},
+		{2, "page without the lang attribute"},
+	}
+	if !reflect.DeepEqual(issues, want) {
+		t.Errorf("want %v, got %v", want, issues)
+	}
+
+	issues, _ = auditHTML(strings.NewReader(`<html lang="en"><h2>Rome</h2><h3>Sights</h3></html>`))
+	if len(issues) != 0 {
+		t.Errorf("want %v, got %v", "no issues", issues)
+	}
+}
+
+func TestAudit(t *testing.T) {
+	templates := writeTestTemplates(t)
+	os.WriteFile(filepath.Join(templates, "post.tmpl"), []byte(`{{define "content"}}{{. | html}}{{end}}`), 0600)
+	blog := Blog{{Title: "Rome", Written: time.Now(), Content: `<img src="/colosseum.jpg">`}}
+
+	var warnings []string
+	g, err := NewStaticBlogGenerator(blog, templates, t.TempDir(), func(string) {},
+		WithConfig(Config{Audit: true}),
+		WithWarnFunc(func(message string) { warnings = append(warnings, message) }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Generate(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"accessibility issue in rome.html (Rome) on line 1: image without alt text: /colosseum.jpg"}
+	if !reflect.DeepEqual(warnings, want) {
+		t.Errorf("want %v, got %v", want, warnings)
+	}
+}
@@ -0,0 +1,140 @@
+package lib
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/gosimple/slug"
+)
+
+// dataDir is the directory in the Blog directory with data files.
+const dataDir = "data"
+
+// AuthorsFile is the name of the optional file in the data directory with
+// profiles of authors keyed by the names used in the front matter of Posts:
+//
+//	jane:
+//	  name: Jane Doe
+//	  bio: Jane writes about *Go*.
+//	  avatar: /images/jane.jpg
+//	  links:
+//	    - name: Mastodon
+//	      url: https://mastodon.social/@jane
+const AuthorsFile = "authors.yaml"
+
+// Author is a writer of Posts.
+type Author struct {
+	Name string `yaml:"name"`
+	Slug string `yaml:"-"`
+
+	// Bio of the author (can use Markdown). It's empty unless it's set in the
+	// AuthorsFile.
+	Bio string `yaml:"bio"`
+
+	// Avatar is the URL of the author's picture.
+	Avatar string `yaml:"avatar"`
+	Links  []Link `yaml:"links"`
+
+	// Posts written by the author sorted by date in descending order. It's
+	// empty in Post Bylines.
+	Posts []Post `yaml:"-"`
+}
+
+// Link is a named link to a page (for example an author's profile on another
+// site).
+type Link struct {
+	Name string `yaml:"name"`
+	URL  string `yaml:"url"`
+}
+
+// URL returns the URL of the Author's first Link or an empty string if there
+// are no Links.
+func (a Author) URL() string {
+	if len(a.Links) == 0 {
+		return ""
+	}
+	return a.Links[0].URL
+}
+
+// Count returns the number of Posts written by the Author.
+func (a Author) Count() int {
+	return len(a.Posts)
+}
+
+// AuthorsWithPosts returns all authors of Posts of the Blog sorted by name
+// together with their Posts (sorted by date in descending order). If
+// includeDrafts == true draft Posts are also included.
+func (b Blog) AuthorsWithPosts(includeDrafts bool) []Author {
+	byName := map[string]*Author{}
+	for _, post := range b.PostsByDate(false, includeDrafts) {
+		for i, name := range post.Authors {
+			author, ok := byName[name]
+			if !ok {
+				profile := Author{Name: name, Slug: slug.Make(name)}
+				if i < len(post.Bylines) {
+					profile = post.Bylines[i]
+				}
+				author = &profile
+				byName[name] = author
+			}
+			author.Posts = append(author.Posts, post)
+		}
+	}
+
+	authors := make([]Author, 0, len(byName))
+	for _, author := range byName {
+		authors = append(authors, *author)
+	}
+	sort.Slice(authors, func(i, j int) bool {
+		return authors[i].Name < authors[j].Name
+	})
+	return authors
+}
+
+// readAuthors reads the author profiles from the AuthorsFile keyed by the
+// slugs of their keys.
+func (b MarkdownBlog) readAuthors() (map[string]Author, error) {
+	var profiles map[string]Author
+	err := readYAML(filepath.Join(b.dir, dataDir, AuthorsFile), &profiles)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read authors: %s", err)
+	}
+
+	authors := map[string]Author{}
+	for key, profile := range profiles {
+		if profile.Name == "" {
+			profile.Name = key
+		}
+		profile.Slug = slug.Make(profile.Name)
+		authors[slug.Make(key)] = profile
+	}
+	return authors, nil
+}
+
+// resolveAuthors replaces the Post's Authors that have profiles with the
+// profiles' names and sets its Bylines.
+func resolveAuthors(post *Post, profiles map[string]Author) {
+	if len(post.Authors) == 0 {
+		return
+	}
+
+	post.Bylines = make([]Author, len(post.Authors))
+	for i, name := range post.Authors {
+		profile, ok := profiles[slug.Make(name)]
+		if !ok {
+			profile = Author{Name: name, Slug: slug.Make(name)}
+		}
+		post.Authors[i] = profile.Name
+		post.Bylines[i] = profile
+	}
+}
+
+func (g StaticBlogGenerator) authorPath(author string) string {
+	return g.pagePath("authors/" + g.slug(author))
+}
+
+// authorURL returns the URL of the author's page relative to the Blog's root.
+func (g StaticBlogGenerator) authorURL(author string) string {
+	return g.pageURL(g.authorPath(author))
+}
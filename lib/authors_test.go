@@ -0,0 +1,70 @@
+package lib
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAuthorsWithPosts(t *testing.T) {
+	blog := Blog{
+		{Title: "Rome", Authors: []string{"Jane Doe", "John Doe"}, Written: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{Title: "Paris", Authors: []string{"Jane Doe"}, Written: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{Title: "London", Authors: []string{"John Doe"}, Draft: true},
+		{Title: "Oslo"},
+	}
+
+	authors := blog.AuthorsWithPosts(false)
+	if len(authors) != 2 {
+		t.Fatalf("want %v, got %v", 2, len(authors))
+	}
+
+	if authors[0].Name != "Jane Doe" || authors[0].Slug != "jane-doe" || authors[0].Count() != 2 ||
+		authors[0].Posts[0].Title != "Paris" {
+		t.Errorf("want %v, got %v", "Jane Doe with Paris and Rome", authors[0])
+	}
+	if authors[1].Name != "John Doe" || authors[1].Count() != 1 {
+		t.Errorf("want %v, got %v", "John Doe with Rome", authors[1])
+	}
+}
+
+func TestResolveAuthors(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, dataDir), 0700)
+	os.WriteFile(filepath.Join(dir, dataDir, AuthorsFile), []byte(`jane:
+  name: Jane Doe
+  bio: Jane writes about *Go*.
+  avatar: /images/jane.jpg
+  links:
+    - name: Mastodon
+      url: https://mastodon.social/@jane
+`), 0600)
+
+	authors, err := MarkdownBlog{dir: dir}.readAuthors()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	post := Post{Authors: []string{"Jane", "John Doe"}}
+	resolveAuthors(&post, authors)
+
+	if post.Authors[0] != "Jane Doe" || post.Authors[1] != "John Doe" {
+		t.Errorf("want %v, got %v", "[Jane Doe John Doe]", post.Authors)
+	}
+	if post.Bylines[0].Slug != "jane-doe" || post.Bylines[0].Avatar != "/images/jane.jpg" ||
+		post.Bylines[0].URL() != "https://mastodon.social/@jane" {
+		t.Errorf("want %v, got %v", "Jane Doe's profile", post.Bylines[0])
+	}
+	if post.Bylines[1].Name != "John Doe" || post.Bylines[1].Bio != "" {
+		t.Errorf("want %v, got %v", "John Doe without a profile", post.Bylines[1])
+	}
+
+	blog := Blog{post}
+	if bio := blog.AuthorsWithPosts(false)[0].Bio; bio != "Jane writes about *Go*." {
+		t.Errorf("want %v, got %v", "Jane writes about *Go*.", bio)
+	}
+	if len(post.Bylines[0].Posts) != 0 {
+		t.Errorf("want %v, got %v", 0, len(post.Bylines[0].Posts))
+	}
+}
@@ -0,0 +1,118 @@
+package lib
+
+import (
+	"encoding/json"
+	"io"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// LinkGraphFile is the name of the generated file with links between Posts.
+const LinkGraphFile = "links.json"
+
+var hrefRegexp = regexp.MustCompile(`href="([^"]*)"`)
+
+// linkGraph holds Posts (nodes) and links between them (edges) identified by
+// the Posts' URLs.
+type linkGraph struct {
+	Nodes []linkGraphNode `json:"nodes"`
+	Links []linkGraphLink `json:"links"`
+}
+
+type linkGraphNode struct {
+	URL   string `json:"url"`
+	Title string `json:"title"`
+}
+
+type linkGraphLink struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+}
+
+// postsByURL returns the Posts keyed by their URLs.
+func (g StaticBlogGenerator) postsByURL() map[string]Post {
+	byURL := make(map[string]Post, len(g.posts))
+	for _, p := range g.posts {
+		byURL[g.postURL(p)] = p
+	}
+	return byURL
+}
+
+// linkedPosts returns the Posts (of the ones keyed by their URLs) the Post
+// links to (in the order of the first link to each of them). Links to the
+// Post itself are skipped.
+func (g StaticBlogGenerator) linkedPosts(post Post, byURL map[string]Post) []Post {
+	self := g.postURL(post)
+	seen := map[string]bool{}
+	var linked []Post
+	for _, match := range hrefRegexp.FindAllStringSubmatch(string(renderPost(post)), -1) {
+		target := g.internalURL(self, match[1])
+		if target == self || seen[target] {
+			continue
+		}
+		if p, ok := byURL[target]; ok {
+			seen[target] = true
+			linked = append(linked, p)
+		}
+	}
+	return linked
+}
+
+// internalURL returns the href (found in the page at the from URL) as a URL
+// relative to the Blog's root or an empty string if it's an external link.
+func (g StaticBlogGenerator) internalURL(from, href string) string {
+	if base := strings.TrimSuffix(g.options.config.URL, "/"); base != "" &&
+		(href == base || strings.HasPrefix(href, base+"/") ||
+			strings.HasPrefix(href, base+"#") || strings.HasPrefix(href, base+"?")) {
+		href = "/" + strings.TrimPrefix(strings.TrimPrefix(href, base), "/")
+	}
+
+	u, err := url.Parse(href)
+	if err != nil || u.Scheme != "" || u.Host != "" || u.Path == "" {
+		return ""
+	}
+
+	if !strings.HasPrefix(u.Path, "/") {
+		return path.Join(path.Dir(from), u.Path)
+	}
+	return path.Clean(g.options.config.trimBasePath(u.Path))
+}
+
+// computeBacklinks returns the Posts linking to each Post keyed by the
+// linked Post's URL.
+func (g StaticBlogGenerator) computeBacklinks() map[string][]Post {
+	backlinks := map[string][]Post{}
+	byURL := g.postsByURL()
+	for _, post := range g.posts {
+		for _, linked := range g.linkedPosts(post, byURL) {
+			url := g.postURL(linked)
+			backlinks[url] = append(backlinks[url], post)
+		}
+	}
+	return backlinks
+}
+
+// backlinks returns the Posts linking to the Post.
+func (g StaticBlogGenerator) backlinks(post Post) []Post {
+	return g.linkedFrom[g.postURL(post)]
+}
+
+func (g StaticBlogGenerator) generateLinkGraph() error {
+	graph := linkGraph{Nodes: []linkGraphNode{}, Links: []linkGraphLink{}}
+	byURL := g.postsByURL()
+	for _, post := range g.posts {
+		graph.Nodes = append(graph.Nodes, linkGraphNode{g.postURL(post), post.Title})
+		for _, linked := range g.linkedPosts(post, byURL) {
+			graph.Links = append(graph.Links,
+				linkGraphLink{g.postURL(post), g.postURL(linked)})
+		}
+	}
+
+	return g.generateFile(LinkGraphFile, func(w io.Writer) error {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(graph)
+	})
+}
@@ -3,6 +3,8 @@ package lib
 import (
 	"sort"
 	"time"
+
+	"github.com/gosimple/slug"
 )
 
 // Blog is just a collection of Posts.
@@ -47,6 +49,28 @@ func (b Blog) Tags(lookInDrafts bool) []string {
 	return blogTags
 }
 
+// TagsWithPosts returns all tags used in Posts of the Blog sorted by name
+// together with the Posts tagged with them (sorted by date in descending
+// order). If includeDrafts == true draft Posts are also included. Slugs of the
+// tags are made by the default slugger (see WithSlugger).
+func (b Blog) TagsWithPosts(includeDrafts bool) []Tag {
+	return b.tagsWithPosts(includeDrafts, slug.Make)
+}
+
+// tagsWithPosts returns the tags like TagsWithPosts with their slugs made by
+// the slugger.
+func (b Blog) tagsWithPosts(includeDrafts bool, slugger func(string) string) []Tag {
+	names := b.Tags(includeDrafts)
+	sort.Strings(names)
+
+	tags := make([]Tag, len(names))
+	for i, name := range names {
+		tags[i] = Tag{Name: name, Slug: slugger(name), Title: name,
+			Posts: b.PostsByDate(false, includeDrafts, name)}
+	}
+	return tags
+}
+
 func (b Blog) Len() int {
 	return len(b)
 }
@@ -59,23 +83,181 @@ func (b Blog) Less(i, j int) bool {
 	return b[i].Written.Before(b[j].Written)
 }
 
+// Formats of Post Content.
+const (
+	FormatMarkdown = "markdown"
+	FormatHTML     = "html"
+)
+
 // Post is a Blog's post.
 type Post struct {
 	Title string
 
 	// Content of the post (can use Markdown).
 	Content string
-	Written time.Time
+
+	// Format of the Content: FormatMarkdown (if empty) or FormatHTML (for
+	// posts converted from other formats).
+	Format string
+
+	// Summary of the post (can use Markdown). It's empty unless it's set in
+	// the front matter.
+	Summary string
+
+	// Description of the post (plain text) used in meta tags and feeds. It's
+	// empty unless it's set in the front matter.
+	Description string
+	Written     time.Time
+
+	// Updated is the date of the last change of the post (it's the zero time if
+	// unknown).
+	Updated time.Time
 	Tags    []string
+
+	// Authors of the post. It's empty unless it's set in the front matter.
+	Authors []string
+
+	// Bylines holds the profiles of the Authors (in the same order) read from
+	// the AuthorsFile. Authors without a profile have just a Name and a Slug.
+	Bylines []Author
 	Draft   bool
 	IsPage  bool
+
+	// Dir is the directory of the post's file relative to the posts (or
+	// drafts) directory using forward slashes. It's empty for posts stored
+	// directly in the posts directory.
+	Dir string
+
+	// Audio is the podcast episode of the post. It's nil unless it's set in
+	// the front matter.
+	Audio *Audio
+
+	// Comments of the post sorted by date in ascending order.
+	Comments []Comment
+
+	// CommentsDisabled tells whether the comment system is disabled for the
+	// post (with comments: false in the front matter).
+	CommentsDisabled bool
+
+	// Unlisted tells whether the post's page is generated but the post isn't
+	// included in the index, tag pages, feeds and the sitemap.
+	Unlisted bool
+
+	// Expires is the date after which the post isn't generated (it's the zero
+	// time if the post doesn't expire).
+	Expires time.Time
+
+	// Password the post's content is encrypted with in generated pages. It
+	// shouldn't be rendered by templates.
+	Password string
+
+	// Output is the path of the post's page relative to the output directory
+	// using forward slashes (for example projects/widget/index.html). It's
+	// empty unless it's set in the front matter, otherwise the path is derived
+	// from the title.
+	Output string
+
+	// Aliases are former URLs of the post (relative to the Blog's root)
+	// redirecting to it. It's empty unless it's set in the front matter.
+	Aliases []string
+
+	// IsPreviewOnly tells whether the post is generated only because it's a
+	// preview build (see WithPreview): it's a draft or it's scheduled.
+	IsPreviewOnly bool
+
+	// Bundle is the path of the post's page bundle: a directory with the
+	// post's index file (for example index.md) and its assets (images and
+	// other files) copied next to the post's page. It's empty unless the post
+	// is stored in a page bundle.
+	Bundle string
+
+	// Link is the URL of the external page the post is about (the post is a
+	// linklog entry). It's empty unless it's set in the front matter.
+	Link string
+
+	// Photo is the image of a photo post with its EXIF metadata and display
+	// sizes. It's nil unless it's set in the front matter.
+	Photo *Photo
+
+	// NoIndex tells whether search engines shouldn't index the post's page
+	// (with noindex: true in the front matter). The post is listed as usual,
+	// but it isn't included in the sitemap.
+	NoIndex bool
+
+	// Encrypted tells whether the post was read from an encrypted draft file
+	// (see DraftEncryption). Its renders aren't cached on disk.
+	Encrypted bool
+}
+
+// Audio holds a podcast episode's audio file and metadata.
+type Audio struct {
+	// File is the URL of the audio file relative to the Blog's root (for
+	// example /episodes/1.mp3) or an absolute URL.
+	File string `yaml:"file"`
+
+	// Duration of the episode in the HH:MM:SS or MM:SS format.
+	Duration string `yaml:"duration"`
+
+	// Size of the file in bytes. If it's 0 the size of the file in the output
+	// directory is used.
+	Size int64 `yaml:"size"`
+
+	Episode  int  `yaml:"episode"`
+	Season   int  `yaml:"season"`
+	Explicit bool `yaml:"explicit"`
+}
+
+// expired tells whether the Post expired before now.
+func (p Post) expired(now time.Time) bool {
+	return !p.Expires.IsZero() && !p.Expires.After(now)
+}
+
+// LastModified returns the Updated date of the Post or the Written date if the
+// Updated date is unknown.
+func (p Post) LastModified() time.Time {
+	if p.Updated.IsZero() {
+		return p.Written
+	}
+	return p.Updated
+}
+
+// WordCount returns the number of words in the Content of the Post.
+func (p Post) WordCount() int {
+	if p.Format == FormatHTML {
+		return wordCount(stripTags(p.Content))
+	}
+	return wordCount(p.Content)
+}
+
+// ReadingTime returns the estimated number of minutes needed to read the
+// Content of the Post.
+func (p Post) ReadingTime() int {
+	return minutesToRead(p.WordCount())
+}
+
+// Tag is a tag together with the Posts tagged with it.
+type Tag struct {
+	Name string
+	Slug string
+
+	// Title is a human readable name of the tag (it's the Name by default).
+	Title string
+
+	// Description of the tag (plain text). It's empty unless it's configured.
+	Description string
+	Posts       []Post
+}
+
+// Count returns the number of Posts tagged with the Tag.
+func (t Tag) Count() int {
+	return len(t.Posts)
 }
 
 func sortByDate(blog Blog, asc bool) {
 	if asc {
-		sort.Sort(blog)
+		sort.Stable(blog)
 	} else {
-		sort.Sort(sort.Reverse(blog))
+		sort.Stable(sort.Reverse(blog))
 	}
 }
 
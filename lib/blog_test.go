@@ -0,0 +1,59 @@
+package lib
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTagsWithPosts(t *testing.T) {
+	now := time.Now()
+	blog := Blog{
+		{Title: "Rome", Tags: []string{"Italy", "cities"}, Written: now.Add(-2 * time.Hour)},
+		{Title: "Paris", Tags: []string{"cities"}, Written: now.Add(-time.Hour)},
+		{Title: "Milan", Tags: []string{"Italy"}, Written: now, Draft: true},
+	}
+
+	tests := []struct {
+		includeDrafts bool
+		want          map[string][]string
+	}{
+		{false, map[string][]string{"Italy": {"Rome"}, "cities": {"Paris", "Rome"}}},
+		{true, map[string][]string{"Italy": {"Milan", "Rome"}, "cities": {"Paris", "Rome"}}},
+	}
+
+	for _, test := range tests {
+		tags := blog.TagsWithPosts(test.includeDrafts)
+		if len(tags) != 2 || tags[0].Name != "Italy" || tags[1].Name != "cities" {
+			t.Fatalf("want %v, got %v", "[Italy cities]", tags)
+		}
+
+		for _, tag := range tags {
+			var titles []string
+			for _, post := range tag.Posts {
+				titles = append(titles, post.Title)
+			}
+			want := test.want[tag.Name]
+			if strings.Join(titles, ",") != strings.Join(want, ",") || tag.Count() != len(want) {
+				t.Errorf("%s: want %v, got %v (%d)", tag.Name, want, titles, tag.Count())
+			}
+			if tag.Title != tag.Name || tag.Slug != strings.ToLower(tag.Name) {
+				t.Errorf("%s: want %v, got %v and %v", tag.Name, "the default title and slug",
+					tag.Title, tag.Slug)
+			}
+		}
+	}
+}
+
+func TestTagSlugsWithSlugger(t *testing.T) {
+	blog := Blog{{Title: "Rome", Tags: []string{"Ancient Rome"}, Written: time.Now()}}
+
+	g, err := NewStaticBlogGenerator(blog, writeTestTemplates(t), t.TempDir(), func(string) {},
+		WithSlugger(func(s string) string { return strings.ReplaceAll(strings.ToLower(s), " ", "_") }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tag := g.tags[0]; tag.Slug != "ancient_rome" || g.tagURL(tag.Name) != "/tags/"+tag.Slug+".html" {
+		t.Errorf("want %v, got %v and %v", "ancient_rome", tag.Slug, g.tagURL(tag.Name))
+	}
+}
@@ -0,0 +1,267 @@
+package lib
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// BlogrollFile is the name of the optional file in the data directory with
+// the feeds of the Blog's blogroll:
+//
+//	---
+//	- title: Jane's Blog
+//	  url: https://jane.example.com
+//	  feed: https://jane.example.com/atom.xml
+//	  description: Jane writes about Go.
+//	  category: Friends
+const BlogrollFile = "blogroll.yaml"
+
+// BlogrollOPMLFile is the name of the file in the data directory with the
+// feeds of the Blog's blogroll exported from a feed reader (used if there's no
+// BlogrollFile). It's also the name of the file the blogroll is exported to in
+// the output directory.
+const BlogrollOPMLFile = "blogroll.opml"
+
+// Feed is a feed of another site in a Blogroll.
+type Feed struct {
+	Title string `yaml:"title"`
+
+	// URL of the site.
+	URL string `yaml:"url"`
+
+	// Feed is the URL of the site's Atom or RSS feed.
+	Feed string `yaml:"feed"`
+
+	Description string `yaml:"description"`
+	Category    string `yaml:"category"`
+
+	// Latest is the latest entry of the feed. It's empty unless it was fetched
+	// (see Blogroll.FetchLatest).
+	Latest Link `yaml:"-"`
+}
+
+// Blogroll holds feeds of other sites recommended by a Blog.
+type Blogroll []Feed
+
+// Categories returns the categories of the Blogroll's Feeds in the order of
+// their first Feeds. Feeds without a category are in the empty one.
+func (b Blogroll) Categories() []string {
+	seen := map[string]bool{}
+	var categories []string
+	for _, feed := range b {
+		if !seen[feed.Category] {
+			seen[feed.Category] = true
+			categories = append(categories, feed.Category)
+		}
+	}
+	return categories
+}
+
+// InCategory returns the Blogroll's Feeds in the category.
+func (b Blogroll) InCategory(category string) Blogroll {
+	var feeds Blogroll
+	for _, feed := range b {
+		if feed.Category == category {
+			feeds = append(feeds, feed)
+		}
+	}
+	return feeds
+}
+
+// ReadBlogroll reads the Blogroll of the Blog in the directory from the
+// BlogrollFile or the BlogrollOPMLFile in its data directory. If neither
+// exists it returns an empty Blogroll.
+func ReadBlogroll(dir string) (Blogroll, error) {
+	var blogroll Blogroll
+	yamlFile := filepath.Join(dir, dataDir, BlogrollFile)
+	if _, err := os.Stat(yamlFile); err == nil {
+		err = readYAML(yamlFile, &blogroll)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read blogroll: %s", err)
+		}
+		return blogroll, nil
+	}
+
+	file, err := os.Open(filepath.Join(dir, dataDir, BlogrollOPMLFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read blogroll: %s", err)
+	}
+	defer file.Close()
+
+	blogroll, err = ParseOPML(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read blogroll: %s", err)
+	}
+	return blogroll, nil
+}
+
+type opml struct {
+	XMLName  xml.Name      `xml:"opml"`
+	Version  string        `xml:"version,attr"`
+	Title    string        `xml:"head>title"`
+	Outlines []opmlOutline `xml:"body>outline"`
+}
+
+type opmlOutline struct {
+	Text        string        `xml:"text,attr"`
+	Title       string        `xml:"title,attr,omitempty"`
+	Type        string        `xml:"type,attr,omitempty"`
+	XMLURL      string        `xml:"xmlUrl,attr,omitempty"`
+	HTMLURL     string        `xml:"htmlUrl,attr,omitempty"`
+	Description string        `xml:"description,attr,omitempty"`
+	Outlines    []opmlOutline `xml:"outline"`
+}
+
+// ParseOPML parses the feeds (outlines with the xmlUrl attribute) of the OPML
+// document (for example exported from a feed reader). Feeds in outlines
+// without the attribute get their text as the category.
+func ParseOPML(r io.Reader) (Blogroll, error) {
+	var doc opml
+	err := xml.NewDecoder(r).Decode(&doc)
+	if err != nil {
+		return nil, err
+	}
+
+	var blogroll Blogroll
+	var add func(outlines []opmlOutline, category string)
+	add = func(outlines []opmlOutline, category string) {
+		for _, o := range outlines {
+			if o.XMLURL == "" {
+				add(o.Outlines, o.Text)
+				continue
+			}
+
+			title := o.Title
+			if title == "" {
+				title = o.Text
+			}
+			blogroll = append(blogroll, Feed{Title: title, URL: o.HTMLURL,
+				Feed: o.XMLURL, Description: o.Description, Category: category})
+		}
+	}
+	add(doc.Outlines, "")
+	return blogroll, nil
+}
+
+// writeOPML writes the Blogroll as an OPML document with the title. Feeds
+// are grouped in outlines of their categories.
+func (b Blogroll) writeOPML(w io.Writer, title string) error {
+	doc := opml{Version: "2.0", Title: title}
+	for _, category := range b.Categories() {
+		var outlines []opmlOutline
+		for _, feed := range b.InCategory(category) {
+			outlines = append(outlines, opmlOutline{Text: feed.Title, Title: feed.Title,
+				Type: "rss", XMLURL: feed.Feed, HTMLURL: feed.URL, Description: feed.Description})
+		}
+
+		if category == "" {
+			doc.Outlines = append(doc.Outlines, outlines...)
+		} else {
+			doc.Outlines = append(doc.Outlines, opmlOutline{Text: category, Outlines: outlines})
+		}
+	}
+
+	_, err := io.WriteString(w, xml.Header)
+	if err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	return enc.Encode(doc)
+}
+
+// latestFeed holds the entries of an Atom or the items of an RSS feed.
+type latestFeed struct {
+	Items []struct {
+		Title string `xml:"title"`
+		Link  string `xml:"link"`
+	} `xml:"channel>item"`
+	Entries []struct {
+		Title string     `xml:"title"`
+		Links []atomLink `xml:"link"`
+	} `xml:"entry"`
+}
+
+// FetchLatest returns the Blogroll with the Latest entries of its Feeds
+// fetched from their feeds. Feeds that fail to be fetched are reported to the
+// warnFunc and keep their Latest entries.
+func (b Blogroll) FetchLatest(progressFunc ProgressFunc, warnFunc func(message string)) Blogroll {
+	client := &http.Client{Timeout: 30 * time.Second}
+
+	fetched := make(Blogroll, len(b))
+	copy(fetched, b)
+	for i, feed := range fetched {
+		if feed.Feed == "" {
+			continue
+		}
+
+		progressFunc(feed.Feed)
+
+		latest, err := fetchLatest(client, feed.Feed)
+		if err != nil {
+			warnFunc(fmt.Sprintf("failed to fetch feed %s: %s", feed.Feed, err))
+			continue
+		}
+		fetched[i].Latest = latest
+	}
+	return fetched
+}
+
+// fetchLatest returns the first entry of the feed with the URL.
+func fetchLatest(client *http.Client, url string) (Link, error) {
+	resp, err := client.Get(url)
+	if err != nil {
+		return Link{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return Link{}, fmt.Errorf("unexpected status: %s", resp.Status)
+	}
+
+	var feed latestFeed
+	err = xml.NewDecoder(resp.Body).Decode(&feed)
+	if err != nil {
+		return Link{}, err
+	}
+
+	if len(feed.Items) > 0 {
+		item := feed.Items[0]
+		return Link{strings.TrimSpace(item.Title), strings.TrimSpace(item.Link)}, nil
+	}
+	if len(feed.Entries) > 0 {
+		entry := feed.Entries[0]
+		latest := Link{Name: strings.TrimSpace(entry.Title)}
+		for _, link := range entry.Links {
+			if link.Rel == "" || link.Rel == "alternate" {
+				latest.URL = link.Href
+				break
+			}
+		}
+		return latest, nil
+	}
+	return Link{}, errors.New("no entries in the feed")
+}
+
+// generateBlogroll exports the Blogroll to the BlogrollOPMLFile. Its page is
+// generated with the other Pages.
+func (g StaticBlogGenerator) generateBlogroll() error {
+	title := "Blogroll"
+	if g.options.config.Title != "" {
+		title = g.options.config.Title + " Blogroll"
+	}
+	return g.generateFile(BlogrollOPMLFile, func(w io.Writer) error {
+		return g.options.blogroll.writeOPML(w, title)
+	})
+}
@@ -0,0 +1,133 @@
+package lib
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testOPML = `<?xml version="1.0" encoding="UTF-8"?>
+<opml version="2.0">
+  <head><title>Subscriptions</title></head>
+  <body>
+    <outline text="Jane's Blog" type="rss" xmlUrl="https://jane.example.com/atom.xml" htmlUrl="https://jane.example.com"/>
+    <outline text="Friends">
+      <outline text="John" title="John's Notes" type="rss" xmlUrl="https://john.example.com/rss.xml"/>
+    </outline>
+  </body>
+</opml>`
+
+func TestParseOPML(t *testing.T) {
+	blogroll, err := ParseOPML(strings.NewReader(testOPML))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := Blogroll{
+		{Title: "Jane's Blog", URL: "https://jane.example.com", Feed: "https://jane.example.com/atom.xml"},
+		{Title: "John's Notes", Feed: "https://john.example.com/rss.xml", Category: "Friends"},
+	}
+	if !reflect.DeepEqual(blogroll, want) {
+		t.Errorf("want %v, got %v", want, blogroll)
+	}
+
+	var b strings.Builder
+	if err := blogroll.writeOPML(&b, "Blogroll"); err != nil {
+		t.Fatal(err)
+	}
+	exported, err := ParseOPML(strings.NewReader(b.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(exported, want) {
+		t.Errorf("want %v, got %v", want, exported)
+	}
+}
+
+func TestReadBlogroll(t *testing.T) {
+	dir := t.TempDir()
+	blogroll, err := ReadBlogroll(dir)
+	if err != nil || blogroll != nil {
+		t.Errorf("want %v, got %v (%v)", nil, blogroll, err)
+	}
+
+	os.MkdirAll(filepath.Join(dir, dataDir), 0700)
+	os.WriteFile(filepath.Join(dir, dataDir, BlogrollOPMLFile), []byte(testOPML), 0600)
+	blogroll, err = ReadBlogroll(dir)
+	if err != nil || len(blogroll) != 2 {
+		t.Errorf("want %v, got %v (%v)", 2, len(blogroll), err)
+	}
+
+	os.WriteFile(filepath.Join(dir, dataDir, BlogrollFile), []byte(
+		"- title: Jane's Blog\n  feed: https://jane.example.com/atom.xml\n"), 0600)
+	blogroll, err = ReadBlogroll(dir)
+	if err != nil || len(blogroll) != 1 || blogroll[0].Title != "Jane's Blog" {
+		t.Errorf("want %v, got %v (%v)", "Jane's Blog", blogroll, err)
+	}
+}
+
+func TestFetchLatest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/atom.xml":
+			w.Write([]byte(`<feed xmlns="http://www.w3.org/2005/Atom"><entry><title>Rome</title>` +
+				`<link rel="alternate" href="https://jane.example.com/rome.html"/></entry></feed>`))
+		case "/rss.xml":
+			w.Write([]byte(`<rss><channel><item><title>Milan</title>` +
+				`<link>https://john.example.com/milan</link></item></channel></rss>`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	blogroll := Blogroll{{Feed: server.URL + "/atom.xml"}, {Feed: server.URL + "/rss.xml"},
+		{Feed: server.URL + "/missing.xml"}, {Title: "No feed"}}
+	var warnings []string
+	fetched := blogroll.FetchLatest(func(string) {},
+		func(message string) { warnings = append(warnings, message) })
+
+	want := []Link{{"Rome", "https://jane.example.com/rome.html"},
+		{"Milan", "https://john.example.com/milan"}, {}, {}}
+	for i, feed := range fetched {
+		if feed.Latest != want[i] {
+			t.Errorf("want %v, got %v", want[i], feed.Latest)
+		}
+	}
+	if len(warnings) != 1 {
+		t.Errorf("want %v, got %v", 1, warnings)
+	}
+	if blogroll[0].Latest != (Link{}) {
+		t.Errorf("want %v, got %v", Link{}, blogroll[0].Latest)
+	}
+}
+
+func TestGenerateBlogroll(t *testing.T) {
+	templates := writeTestTemplates(t)
+	os.WriteFile(filepath.Join(templates, "blogroll.tmpl"), []byte(
+		`{{define "content"}}{{range .Categories}}{{.}}:{{range $.InCategory .}}{{.Title}};{{end}}{{end}}{{end}}`), 0600)
+	blogroll, _ := ParseOPML(strings.NewReader(testOPML))
+
+	output := t.TempDir()
+	g, err := NewStaticBlogGenerator(Blog{}, templates, output, func(string) {},
+		WithBlogroll(blogroll))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Generate(); err != nil {
+		t.Fatal(err)
+	}
+
+	bytes, _ := os.ReadFile(filepath.Join(output, "blogroll.html"))
+	if got, want := string(bytes), ":Jane&#39;s Blog;Friends:John&#39;s Notes;"; got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+	bytes, _ = os.ReadFile(filepath.Join(output, BlogrollOPMLFile))
+	if !strings.Contains(string(bytes), `<outline text="Friends">`) {
+		t.Errorf("want %v, got %s", "the Friends outline", bytes)
+	}
+}
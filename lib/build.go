@@ -0,0 +1,58 @@
+package lib
+
+import (
+	"encoding/json"
+	"io"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// Version is the version of LitePub.
+const Version = "0.5.7"
+
+// BuildFile is the name of the file in the output directory with the Build
+// metadata (see Config.BuildFile).
+const BuildFile = "build.json"
+
+// Build holds metadata of a build of a Blog, so templates can show when it
+// was built and deploys can be traced to commits.
+type Build struct {
+	// Version of LitePub that built the Blog.
+	Version string `json:"version"`
+
+	// Commit is the Git commit of the Blog's directory. It's empty if the
+	// directory isn't in a Git repository.
+	Commit string `json:"commit,omitempty"`
+
+	Time time.Time `json:"time"`
+}
+
+// NewBuild returns the Build of the Blog in the directory started now.
+func NewBuild(dir string) Build {
+	build := Build{Version: Version, Time: time.Now()}
+
+	cmd := exec.Command("git", "rev-parse", "HEAD")
+	cmd.Dir = dir
+	if out, err := cmd.Output(); err == nil {
+		build.Commit = strings.TrimSpace(string(out))
+	}
+	return build
+}
+
+// ShortCommit returns the first 7 characters of the Commit.
+func (b Build) ShortCommit() string {
+	if len(b.Commit) > 7 {
+		return b.Commit[:7]
+	}
+	return b.Commit
+}
+
+// generateBuildFile writes the Build to the BuildFile.
+func (g StaticBlogGenerator) generateBuildFile() error {
+	return g.generateFile(BuildFile, func(w io.Writer) error {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(g.options.build)
+	})
+}
@@ -0,0 +1,59 @@
+package lib
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestNewBuild(t *testing.T) {
+	dir := t.TempDir()
+	if build := NewBuild(dir); build.Version != Version || build.Commit != "" || build.Time.IsZero() {
+		t.Errorf("want %v, got %v", "a build without a commit", build)
+	}
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git isn't available")
+	}
+	for _, args := range [][]string{{"init", "--quiet"},
+		{"-c", "user.name=Jane", "-c", "user.email=jane@example.com",
+			"commit", "--quiet", "--allow-empty", "-m", "Rome"}} {
+		if err := git(dir, args...); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if build := NewBuild(dir); len(build.Commit) != 40 || len(build.ShortCommit()) != 7 {
+		t.Errorf("want %v, got %v", "a commit", build.Commit)
+	}
+}
+
+func TestBuildMetadata(t *testing.T) {
+	templates := writeTestTemplates(t)
+	os.WriteFile(filepath.Join(templates, "index.tmpl"), []byte(
+		`{{define "content"}}{{site.Build.Version}} {{site.Build.ShortCommit}} {{formatYear site.Build.Time}}{{end}}`), 0600)
+	build := Build{Version: Version, Commit: "8c9f86f7a55f", Time: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)}
+
+	output := t.TempDir()
+	g, err := NewStaticBlogGenerator(Blog{}, templates, output, func(string) {},
+		WithConfig(Config{BuildFile: true}), WithBuild(build))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Generate(); err != nil {
+		t.Fatal(err)
+	}
+
+	bytes, _ := os.ReadFile(filepath.Join(output, "index.html"))
+	if got, want := string(bytes), Version+" 8c9f86f 2024"; got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+
+	var written Build
+	bytes, _ = os.ReadFile(filepath.Join(output, BuildFile))
+	if err := json.Unmarshal(bytes, &written); err != nil || written != build {
+		t.Errorf("want %v, got %v (%v)", build, written, err)
+	}
+}
@@ -0,0 +1,113 @@
+package lib
+
+import (
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// postExtensions are the extensions of files read as Posts by their formats.
+// Files with extensions of processors (see Config.Processors) are also read as
+// Posts.
+var postExtensions = []string{".md", ".markdown", ".adoc", ".asciidoc", ".org",
+	".html", ".htm"}
+
+// bundleIndex returns the name of the index file of the page bundle in the
+// directory (a directory with a post's index file and its assets) or an empty
+// string if the directory isn't a page bundle.
+func (c Config) bundleIndex(dir string) string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return ""
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() && c.isBundleIndex(entry.Name()) {
+			return entry.Name()
+		}
+	}
+	return ""
+}
+
+// isBundleIndex tells whether the file with the name is the index file of a
+// page bundle.
+func (c Config) isBundleIndex(name string) bool {
+	ext := filepath.Ext(name)
+	if strings.TrimSuffix(name, ext) != "index" {
+		return false
+	}
+
+	ext = strings.ToLower(ext)
+	for _, e := range postExtensions {
+		if e == ext {
+			return true
+		}
+	}
+	return c.processor(ext) != ""
+}
+
+// bundlePath returns the directory in the output directory the assets of the
+// Post's page bundle are copied to: the directory of its page if the page is
+// an index.html file, otherwise a directory named like the page without its
+// extension.
+func (g StaticBlogGenerator) bundlePath(post Post) string {
+	p := g.postPath(post)
+	if filepath.Base(p) == "index.html" {
+		return filepath.Dir(p)
+	}
+	return strings.TrimSuffix(p, filepath.Ext(p))
+}
+
+// copyBundles copies the assets of the Posts' page bundles (all their files
+// except the index files) to their bundlePaths.
+func (g StaticBlogGenerator) copyBundles() error {
+	for _, post := range append(g.posts[:len(g.posts):len(g.posts)], g.unlisted...) {
+		if post.Bundle == "" {
+			continue
+		}
+
+		assets, err := newCopier(g.options.config.Symlinks, func(rel string, isDir bool) bool {
+			return !isDir && !strings.Contains(rel, "/") && g.options.config.isBundleIndex(rel)
+		})
+		if err != nil {
+			return err
+		}
+
+		err = assets.copy(post.Bundle, filepath.Join(g.outputDir, g.bundlePath(post)))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolveBundleURLs rewrites URLs relative to the Post's page bundle in the
+// HTML to URLs of the copied assets (relative to the Blog's root), so they
+// work wherever the HTML is shown (for example in the index or the feed).
+func (g StaticBlogGenerator) resolveBundleURLs(post Post, html string) string {
+	if post.Bundle == "" {
+		return html
+	}
+
+	base := "/" + filepath.ToSlash(g.bundlePath(post)) + "/"
+	return rewriteURLs(html, true, func(href string) string {
+		u, err := url.Parse(href)
+		if err != nil || u.Scheme != "" || u.Host != "" || u.Path == "" ||
+			strings.HasPrefix(u.Path, "/") {
+			return href
+		}
+
+		asset := path.Clean(u.Path)
+		if asset == ".." || strings.HasPrefix(asset, "../") {
+			return href
+		}
+		if _, err := os.Stat(filepath.Join(post.Bundle, filepath.FromSlash(asset))); err != nil {
+			return href
+		}
+
+		u.Path = base + asset
+		return u.String()
+	})
+}
@@ -0,0 +1,61 @@
+package lib
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPageBundles(t *testing.T) {
+	dir := t.TempDir()
+	bundle := filepath.Join(dir, postsDir, "2024", "trip")
+	os.MkdirAll(filepath.Join(dir, postsDir, draftDir), 0700)
+	os.MkdirAll(filepath.Join(bundle, "docs"), 0700)
+	os.WriteFile(filepath.Join(bundle, "index.md"), []byte("# My Trip\n\n*Jan 2, 2024*\n\n"+
+		"![A photo](photo.jpg) [Notes](./docs/notes.txt) [Rome](rome.html)"), 0600)
+	os.WriteFile(filepath.Join(bundle, "photo.jpg"), []byte("jpg"), 0600)
+	os.WriteFile(filepath.Join(bundle, "docs", "notes.txt"), []byte("notes"), 0600)
+
+	blog, err := NewMarkdownBlog(dir).Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(blog) != 1 || blog[0].Title != "My Trip" || blog[0].Dir != "2024" || blog[0].Bundle != bundle {
+		t.Fatalf("want %v, got %v", "the bundle's post", blog)
+	}
+
+	templates := writeTestTemplates(t)
+	os.WriteFile(filepath.Join(templates, "post.tmpl"), []byte(`{{define "content"}}{{. | html}}{{end}}`), 0600)
+
+	for style, want := range map[string][]string{
+		"":            {"my-trip.html", "my-trip"},
+		URLStyleSlash: {"my-trip/index.html", "my-trip"},
+	} {
+		output := t.TempDir()
+		g, err := NewStaticBlogGenerator(blog, templates, output, func(string) {},
+			WithConfig(Config{URLStyle: style}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := g.Generate(); err != nil {
+			t.Fatal(err)
+		}
+
+		bytes, _ := os.ReadFile(filepath.Join(output, want[0]))
+		page := string(bytes)
+		if !strings.Contains(page, `src="/my-trip/photo.jpg"`) ||
+			!strings.Contains(page, `href="/my-trip/docs/notes.txt"`) || !strings.Contains(page, `href="rome.html"`) {
+			t.Errorf("%s: want %v, got %s", style, "resolved asset URLs", page)
+		}
+
+		for _, asset := range []string{"photo.jpg", filepath.Join("docs", "notes.txt")} {
+			if _, err := os.Stat(filepath.Join(output, want[1], asset)); err != nil {
+				t.Errorf("%s: want %v, got %v", style, asset, err)
+			}
+		}
+		if _, err := os.Stat(filepath.Join(output, want[1], "index.md")); err == nil {
+			t.Errorf("%s: want %v, got %v", style, "the index file not copied", "index.md")
+		}
+	}
+}
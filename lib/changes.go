@@ -0,0 +1,129 @@
+package lib
+
+import (
+	"crypto/sha256"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Statuses of files in the output directory (see ProgressEvent).
+const (
+	// OutputAdded is the status of files the previous build didn't generate.
+	OutputAdded = "added"
+
+	// OutputChanged is the status of files whose content changed.
+	OutputChanged = "changed"
+
+	// OutputUnchanged is the status of files with the same content as after
+	// the previous build.
+	OutputUnchanged = "unchanged"
+
+	// OutputRemoved is the status of files the previous build generated but
+	// the build didn't.
+	OutputRemoved = "removed"
+)
+
+// ProgressEvent describes a file generated or copied (from the templates or
+// static directory) by a build compared with the file with the same path left
+// in the output directory by the previous build.
+type ProgressEvent struct {
+	// Path of the file relative to the output directory.
+	Path string
+
+	// Status is OutputAdded, OutputChanged, OutputUnchanged or OutputRemoved.
+	Status string
+}
+
+// ProgressEventFunc is used to monitor which files a build of a Blog changed
+// (for example in watch mode). It's called with the ProgressEvent of each file
+// in the output directory after the build and each file removed since the
+// previous build (sorted by their paths).
+type ProgressEventFunc func(event ProgressEvent)
+
+// hashOutput records the hashes of the files in the output directory before
+// it's cleared, so the files of the build can be compared with them.
+func (g StaticBlogGenerator) hashOutput() error {
+	return hashFiles(g.outputDir, g.previous)
+}
+
+// reportChanges calls the ProgressEventFunc with the ProgressEvents of the
+// files in the output directory and the files removed from it.
+func (g StaticBlogGenerator) reportChanges() error {
+	current := map[string][32]byte{}
+	err := hashFiles(g.outputDir, current)
+	if err != nil {
+		return err
+	}
+
+	var paths []string
+	for path := range current {
+		paths = append(paths, path)
+	}
+	for path := range g.previous {
+		if _, ok := current[path]; !ok {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		hash, ok := current[path]
+		previous, existed := g.previous[path]
+
+		status := OutputAdded
+		switch {
+		case !ok:
+			status = OutputRemoved
+		case existed && previous == hash:
+			status = OutputUnchanged
+		case existed:
+			status = OutputChanged
+		}
+		g.options.progressEvents(ProgressEvent{filepath.ToSlash(path), status})
+	}
+	return nil
+}
+
+// hashFiles adds the hashes of the files in the directory (and its
+// subdirectories) keyed by their paths relative to it to the hashes. Symbolic
+// links are hashed by their targets.
+func hashFiles(dir string, hashes map[string][32]byte) error {
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+
+		var hash [32]byte
+		if d.Type()&fs.ModeSymlink != 0 {
+			var link string
+			link, err = os.Readlink(p)
+			hash = sha256.Sum256([]byte(link))
+		} else {
+			hash, err = hashFile(p)
+		}
+		if err != nil {
+			return err
+		}
+		hashes[rel] = hash
+		return nil
+	})
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+func hashFile(path string) ([32]byte, error) {
+	bytes, err := os.ReadFile(path)
+	if err != nil {
+		return [32]byte{}, err
+	}
+	return sha256.Sum256(bytes), nil
+}
@@ -0,0 +1,55 @@
+package lib
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestGenerateProgressEvents(t *testing.T) {
+	templates := writeTestTemplates(t)
+	output, static := t.TempDir(), t.TempDir()
+	written := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	build := func(blog Blog) []ProgressEvent {
+		var events []ProgressEvent
+		g, err := NewStaticBlogGenerator(blog, templates, output, func(string) {},
+			WithStaticDir(static),
+			WithProgressEvents(func(event ProgressEvent) { events = append(events, event) }))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := g.Generate(); err != nil {
+			t.Fatal(err)
+		}
+		return events
+	}
+
+	os.WriteFile(filepath.Join(static, "style.css"), []byte("a {}"), 0600)
+	os.WriteFile(filepath.Join(static, "logo.svg"), []byte("<svg/>"), 0600)
+	build(Blog{
+		{Title: "Rome", Tags: []string{"cities"}, Written: written},
+		{Title: "Paris", Written: written.Add(-time.Hour)},
+	})
+
+	os.WriteFile(filepath.Join(static, "style.css"), []byte("a { color: red; }"), 0600)
+	events := build(Blog{
+		{Title: "Rome", Tags: []string{"cities"}, Written: written},
+		{Title: "Oslo", Written: written.Add(time.Hour)},
+	})
+
+	want := []ProgressEvent{
+		{"index.html", OutputChanged},
+		{"logo.svg", OutputUnchanged},
+		{"oslo.html", OutputAdded},
+		{"paris.html", OutputRemoved},
+		{"rome.html", OutputUnchanged},
+		{"style.css", OutputChanged},
+		{"tags/cities.html", OutputUnchanged},
+	}
+	if !reflect.DeepEqual(events, want) {
+		t.Errorf("want %v, got %v", want, events)
+	}
+}
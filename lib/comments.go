@@ -0,0 +1,85 @@
+package lib
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// commentsDir is the directory in the Blog directory with comments stored in
+// subdirectories named after the slugs of the commented Posts' titles.
+const commentsDir = "comments"
+
+// Comment is a moderated comment of a Post. Each comment is stored in a YAML
+// file (compatible with Staticman) in the Post's comments directory:
+//
+//	name: Jane Doe
+//	url: https://example.com
+//	date: 2024-05-01 10:30
+//	message: Great post!
+type Comment struct {
+	ID   string `yaml:"_id"`
+	Name string `yaml:"name"`
+
+	// Email is usually stored hashed for use with avatar services.
+	Email string `yaml:"email"`
+	URL   string `yaml:"url"`
+
+	// Date in one of the dateLayouts or as a Unix timestamp.
+	Date time.Time `yaml:"-"`
+
+	// Message of the comment (can use Markdown).
+	Message string `yaml:"message"`
+}
+
+type commentFile struct {
+	Comment `yaml:",inline"`
+	Date    string `yaml:"date"`
+}
+
+// readComments reads the comments of the Post sorted by date in ascending
+// order. Dates without a time zone are in the loc.
+func (b MarkdownBlog) readComments(post Post, loc *time.Location) ([]Comment, error) {
+	dir := filepath.Join(b.dir, commentsDir, b.options.slug(post.Title))
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, nil
+	}
+
+	var comments []Comment
+	for _, file := range files {
+		ext := strings.ToLower(filepath.Ext(file.Name()))
+		if file.IsDir() || (ext != ".yaml" && ext != ".yml") {
+			continue
+		}
+
+		path := filepath.Join(dir, file.Name())
+		var cf commentFile
+		err := readYAML(path, &cf)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read comment %s: %s", path, err)
+		}
+
+		cf.Comment.Date, err = parseCommentDate(cf.Date, loc)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse date of comment %s: %s", path, err)
+		}
+		comments = append(comments, cf.Comment)
+	}
+
+	sort.SliceStable(comments, func(i, j int) bool {
+		return comments[i].Date.Before(comments[j].Date)
+	})
+	return comments, nil
+}
+
+func parseCommentDate(value string, loc *time.Location) (time.Time, error) {
+	if unix, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(unix, 0).In(loc), nil
+	}
+	return parseDate(value, loc)
+}
@@ -0,0 +1,42 @@
+package lib
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestReadComments(t *testing.T) {
+	dir := t.TempDir()
+	commentDir := filepath.Join(dir, commentsDir, "a-title")
+	os.MkdirAll(commentDir, 0700)
+	os.WriteFile(filepath.Join(commentDir, "2.yaml"),
+		[]byte("name: Jane\ndate: 2021-08-12 10:30\nmessage: Second\n"), 0600)
+	os.WriteFile(filepath.Join(commentDir, "1.yml"),
+		[]byte("_id: abc\nname: John\ndate: 1628510400\nmessage: First\n"), 0600)
+	os.WriteFile(filepath.Join(commentDir, "notes.txt"), []byte("ignored"), 0600)
+
+	b := MarkdownBlog{dir: dir}
+	comments, err := b.readComments(Post{Title: "A Title"}, time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(comments) != 2 {
+		t.Fatalf("want %v, got %v", 2, len(comments))
+	}
+	if comments[0].Name != "John" || comments[0].ID != "abc" || comments[1].Message != "Second" {
+		t.Errorf("want %v, got %v", "[John Jane]", comments)
+	}
+
+	want := time.Date(2021, 8, 12, 10, 30, 0, 0, time.UTC)
+	if !comments[1].Date.Equal(want) {
+		t.Errorf("want %v, got %v", want, comments[1].Date)
+	}
+
+	none, err := b.readComments(Post{Title: "Another Title"}, time.UTC)
+	if err != nil || none != nil {
+		t.Errorf("want %v, got %v (%v)", nil, none, err)
+	}
+}
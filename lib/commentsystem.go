@@ -0,0 +1,53 @@
+package lib
+
+import "fmt"
+
+// Supported comment systems.
+const (
+	CommentSystemGiscus     = "giscus"
+	CommentSystemUtterances = "utterances"
+	CommentSystemIsso       = "isso"
+)
+
+// CommentSystem holds settings of a third party comment system embedded in
+// Post pages.
+type CommentSystem struct {
+	// Name of the system: CommentSystemGiscus, CommentSystemUtterances or
+	// CommentSystemIsso.
+	Name string `yaml:"name"`
+
+	// Repo (owner/name) storing comments in GitHub discussions (giscus) or
+	// issues (utterances). RepoID, Category and CategoryID are used by giscus.
+	Repo       string `yaml:"repo"`
+	RepoID     string `yaml:"repoID"`
+	Category   string `yaml:"category"`
+	CategoryID string `yaml:"categoryID"`
+
+	// Mapping tells how pages are mapped to discussions or issues (for
+	// example pathname).
+	Mapping string `yaml:"mapping"`
+
+	// Theme of the embed (for example light or github-dark).
+	Theme string `yaml:"theme"`
+
+	// URL of the Isso server.
+	URL string `yaml:"url"`
+}
+
+func (c CommentSystem) validate() error {
+	switch c.Name {
+	case "", CommentSystemGiscus, CommentSystemUtterances, CommentSystemIsso:
+		return nil
+	}
+	return fmt.Errorf("unsupported comment system: %s", c.Name)
+}
+
+// commentSystem returns the CommentSystem if it's configured and the Post
+// doesn't disable comments, nil otherwise.
+func (g StaticBlogGenerator) commentSystem(post Post) *CommentSystem {
+	system := g.options.config.CommentSystem
+	if system.Name == "" || post.CommentsDisabled {
+		return nil
+	}
+	return &system
+}
@@ -0,0 +1,507 @@
+package lib
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/gosimple/slug"
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigFile is the name of the optional configuration file stored in the Blog
+// directory.
+const ConfigFile = "litepub.yaml"
+
+// TagsFile is the name of the optional file with TagMeta stored in the Blog
+// directory. It looks like the tags section of the ConfigFile.
+const TagsFile = "tags.yaml"
+
+// Config holds optional settings of a Blog. The zero value is a valid Config
+// that keeps the default behavior.
+//
+// The configuration file looks like this:
+//
+//	url: https://example.com
+//	title: My Blog
+//	author: John Doe
+//	summaryLength: 50
+//	dateFormat: 2 January 2006
+//	locale: de
+//	timezone: Europe/Berlin
+//	gitDates: true
+//	tags:
+//	  go:
+//	    title: The Go Programming Language
+//	    description: Posts about Go.
+//	tagAliases:
+//	  golang: go
+//	ignore:
+//	  - "*.tmpl"
+//	  - node_modules/
+//	symlinks: follow
+//	nestedURLs: true
+//	asciidoctor: asciidoctor -a icons=font
+//	brokenLinks: warn
+//	linkGraph: true
+//	checkLinks: true
+//	linkCheck:
+//	  concurrency: 8
+//	  ignore:
+//	    - https://twitter.com/
+//	archiveLinks: true
+//	externalLinks:
+//	  noreferrer: true
+//	  newTab: true
+//	  exclude:
+//	    - github.com
+//	lazyImages: true
+//	localizeImages: true
+//	thumbnailSize: 200
+//	podcast:
+//	  description: A podcast about Go.
+//	  image: /images/cover.jpg
+//	newsletter:
+//	  command: ./send-newsletter.sh
+//	commentSystem:
+//	  name: giscus
+//	  repo: johndoe/blog
+//	tombstones: true
+//	hooks:
+//	  before:
+//	    - npm run build
+//	  after:
+//	    - ./deploy.sh
+//	plugins:
+//	  - ./plugins/acronyms
+//	processors:
+//	  rst: rst2html5 --template='{body}'
+//	sources:
+//	  - ../team-blog
+//	  - https://github.com/johndoe/posts.git#main
+//	  - s3://my-bucket/posts
+//	s3:
+//	  endpoint: https://s3.eu-central-1.amazonaws.com
+//	  region: eu-central-1
+//	micropub:
+//	  tokenEndpoint: https://tokens.indieauth.com/token
+//	redirects:
+//	  /about-me.html: /about.html
+//	redirectFiles:
+//	  - netlify
+//	urlStyle: slash
+//	relativeURLs: true
+//	basePath: /blog/
+//	audit: true
+//	validateHTML: true
+//	fetchBlogroll: true
+//	buildFile: true
+//	env:
+//	  - ANALYTICS_ID
+//	scheduledPosts: true
+//	pwa:
+//	  icons:
+//	    - /images/icon-512.png
+//	slug:
+//	  unicode: true
+//	  maxLength: 60
+//	  stopwords: [a, an, the]
+//	yearlyFeeds: true
+//	strict: true
+//	templatePosts: true
+//	photoSizes: [640, 1280]
+//	photoLocation: true
+//	heatmap: true
+//	stale:
+//	  years: 3
+//	  markers: ['Go 1\.\d+']
+//	index:
+//	  excludeTags: [notes]
+//	  excludeDirs: [til]
+//	tagFeeds: true
+//	draftEncryption:
+//	  tool: age
+//	  recipients: [age1ql3z7hjy54pw3hyww5ayyfg7zqgvc7w3j2elw8zmrj2kg5sfn9aqmcac8p]
+//	  identity: ~/.config/litepub/drafts.txt
+//	csp:
+//	  output: headers
+//	  inlineCSS: 4096
+//	headers:
+//	  generate: true
+//	  htmlMaxAge: 300
+//	  immutable: [/fonts/*]
+//	  security:
+//	    Strict-Transport-Security: max-age=31536000
+//	taxonomies:
+//	  tags:
+//	    exclude: [status]
+//	  authors:
+//	    feeds: true
+//	    sitemap: true
+//	feeds:
+//	  site:
+//	    items: 20
+//	    drafts: false
+//	  tags:
+//	    content: summary
+//	    summaryLength: 30
+//	  sections:
+//	    notes:
+//	      unlisted: true
+//	      future: false
+//	strictTemplates: true
+//	theme:
+//	  accentColor: "#cc3300"
+//	  showAuthor: false
+//	renderCache: true
+type Config struct {
+	// URL is the absolute URL the Blog is published at. It's required for
+	// generating the sitemap and the Atom feed.
+	URL string `yaml:"url"`
+
+	// Title and Author are used in the Atom feed.
+	Title  string `yaml:"title"`
+	Author string `yaml:"author"`
+
+	// SummaryLength is the maximum number of words of summaries computed from
+	// the first paragraph of a Post. There's no limit if it's 0.
+	SummaryLength int `yaml:"summaryLength"`
+
+	// DateFormat is the default layout (see time.Layout) used by the
+	// formatDate template function.
+	DateFormat string `yaml:"dateFormat"`
+
+	// Locale selects built-in month and day names and their abbreviations
+	// (de, es, fr, it, nl or pt) used when formatting dates. MonthNames (12,
+	// starting with January) and DayNames (7, starting with Sunday) replace
+	// them; they're abbreviated to their first three letters unless
+	// ShortMonthNames and ShortDayNames are set.
+	Locale          string   `yaml:"locale"`
+	MonthNames      []string `yaml:"monthNames"`
+	DayNames        []string `yaml:"dayNames"`
+	ShortMonthNames []string `yaml:"shortMonthNames"`
+	ShortDayNames   []string `yaml:"shortDayNames"`
+
+	// Timezone is the IANA name of the time zone (for example Europe/Berlin)
+	// of Post dates without an explicit zone. Dates in templates, feeds and
+	// sitemaps are formatted in it, too. It's UTC if empty.
+	Timezone string `yaml:"timezone"`
+
+	// GitDates tells whether Post dates should be read from the Git history
+	// of the Blog directory (if it's a Git repository): Updated is the date of
+	// the last commit of the Post's file (unless it's set in the front matter)
+	// and Written gets the time of the commit that added the file if it was on
+	// the day the Post is dated with.
+	GitDates bool `yaml:"gitDates"`
+
+	// Tags holds optional metadata of tags keyed by the tag name.
+	Tags map[string]TagMeta `yaml:"tags"`
+
+	// TagAliases maps alternative tag names to canonical ones (for example
+	// golang to go). Posts tagged with an alias are tagged with the canonical
+	// tag instead and a redirect page is generated for the alias.
+	TagAliases map[string]string `yaml:"tagAliases"`
+
+	// Ignore holds glob patterns (see filepath.Match) of files that aren't
+	// copied from the templates directory to the output directory. Patterns
+	// ending with / match only directories, patterns containing / are matched
+	// against the path relative to the templates directory.
+	Ignore []string `yaml:"ignore"`
+
+	// Symlinks tells how symlinks in the templates and static directories are
+	// copied: SymlinksPreserve (the default), SymlinksFollow or SymlinksError.
+	Symlinks string `yaml:"symlinks"`
+
+	// NestedURLs tells whether Posts stored in subdirectories of the posts
+	// directory are generated to the same subdirectories of the output
+	// directory (for example posts/2024/rome.md to 2024/rome.html).
+	NestedURLs bool `yaml:"nestedURLs"`
+
+	// Asciidoctor is the command (with optional arguments) used to convert
+	// AsciiDoc posts to HTML. It's asciidoctor if empty.
+	Asciidoctor string `yaml:"asciidoctor"`
+
+	// BrokenLinks tells how wiki links to Posts that don't exist are handled:
+	// BrokenLinksError (the default) or BrokenLinksWarn.
+	BrokenLinks string `yaml:"brokenLinks"`
+
+	// LinkGraph tells whether the LinkGraphFile with links between Posts is
+	// generated.
+	LinkGraph bool `yaml:"linkGraph"`
+
+	// CheckLinks tells whether internal links in the generated HTML files are
+	// checked. Broken links are handled according to BrokenLinks.
+	CheckLinks bool `yaml:"checkLinks"`
+
+	// LinkCheck holds settings of the external link checker.
+	LinkCheck LinkCheckConfig `yaml:"linkCheck"`
+
+	// ArchiveLinks tells whether external links of published Posts are
+	// submitted to the Wayback Machine when building the Blog.
+	ArchiveLinks bool `yaml:"archiveLinks"`
+
+	// ExternalLinks holds settings of rewriting links to other sites.
+	ExternalLinks ExternalLinksConfig `yaml:"externalLinks"`
+
+	// LazyImages tells whether images in rendered Posts are loaded lazily and
+	// get their measured dimensions.
+	LazyImages bool `yaml:"lazyImages"`
+
+	// LocalizeImages tells whether images from other sites referenced in Posts
+	// are downloaded to the output directory.
+	LocalizeImages bool `yaml:"localizeImages"`
+
+	// ThumbnailSize is the maximum width and height of thumbnails generated
+	// by the gallery shortcode. It's 300 if it's 0.
+	ThumbnailSize int `yaml:"thumbnailSize"`
+
+	// Podcast holds the metadata of the podcast feed generated for Posts with
+	// Audio.
+	Podcast PodcastConfig `yaml:"podcast"`
+
+	// Newsletter holds settings of newsletters.
+	Newsletter NewsletterConfig `yaml:"newsletter"`
+
+	// CommentSystem is the third party comment system embedded in Post pages.
+	CommentSystem CommentSystem `yaml:"commentSystem"`
+
+	// Tombstones tells whether pages of expired Posts are replaced with
+	// tombstone pages (rendered with the optional expired.tmpl template)
+	// instead of not being generated.
+	Tombstones bool `yaml:"tombstones"`
+
+	// Hooks holds shell commands the build command runs (in the Blog
+	// directory) before and after generating the Blog.
+	Hooks HooksConfig `yaml:"hooks"`
+
+	// Plugins holds commands (with optional arguments) of Plugins the build
+	// and deploy commands start (in the Blog directory).
+	Plugins []string `yaml:"plugins"`
+
+	// Processors maps file extensions of Posts (with or without the leading
+	// dot) to shell commands converting their content (without the front
+	// matter) from the standard input to HTML on the standard output. They
+	// take precedence over the built-in formats.
+	Processors map[string]string `yaml:"processors"`
+
+	// Sources holds directories (relative to the Blog directory), URLs of
+	// Git repositories (see GitBlog) or S3 buckets (see S3Store) of other
+	// Blogs whose Posts are merged into the Blog (see MergedBlog).
+	Sources []string `yaml:"sources"`
+
+	// S3 holds settings of the object storage of S3 Sources.
+	S3 S3Config `yaml:"s3"`
+
+	// Micropub holds the authentication settings of the Micropub endpoint of
+	// the serve command.
+	Micropub MicropubConfig `yaml:"micropub"`
+
+	// Redirects maps URLs (relative to the Blog's root) of moved pages to the
+	// URLs they moved to. A redirect page is generated for each of them.
+	Redirects map[string]string `yaml:"redirects"`
+
+	// RedirectFiles holds the hosts whose redirect files (with the redirects
+	// of tag aliases, Post aliases and Redirects) are generated:
+	// RedirectsNetlify, RedirectsApache or RedirectsCaddy.
+	RedirectFiles []string `yaml:"redirectFiles"`
+
+	// URLStyle tells how pages are generated and linked: URLStyleHTML (the
+	// default), URLStyleSlash or URLStyleClean.
+	URLStyle string `yaml:"urlStyle"`
+
+	// RelativeURLs tells whether internal links in the generated HTML and CSS
+	// files are rewritten to relative paths, so the Blog can be browsed from
+	// the file system without a web server.
+	RelativeURLs bool `yaml:"relativeURLs"`
+
+	// BasePath is the path (for example /blog/) the Blog is hosted at if it
+	// isn't the root of its host. It's prepended to root-relative links in the
+	// generated HTML and CSS files and to the URL if it doesn't end with it.
+	BasePath string `yaml:"basePath"`
+
+	// Audit tells whether the generated HTML files are checked for
+	// accessibility issues (images without alt text, skipped heading levels,
+	// pages without the lang attribute and links without text). The issues
+	// are reported as warnings.
+	Audit bool `yaml:"audit"`
+
+	// ValidateHTML tells whether the generated HTML files are checked for
+	// structural errors (unclosed elements and duplicate IDs). The errors are
+	// reported as warnings or, if the CI environment variable is set, fail
+	// the build.
+	ValidateHTML bool `yaml:"validateHTML"`
+
+	// FetchBlogroll tells whether the latest entries of the Blogroll's Feeds
+	// are fetched when building the Blog.
+	FetchBlogroll bool `yaml:"fetchBlogroll"`
+
+	// BuildFile tells whether the BuildFile with the Build metadata is
+	// generated.
+	BuildFile bool `yaml:"buildFile"`
+
+	// Env holds the names of environment variables templates can read with
+	// the env function (so builds can differ between staging and production).
+	// Other variables can't be read, so templates can't leak secrets.
+	Env []string `yaml:"env"`
+
+	// ScheduledPosts tells whether Posts written in the future are left out
+	// of the Blog until their date (except in preview builds).
+	ScheduledPosts bool `yaml:"scheduledPosts"`
+
+	// PWA holds settings of the web app manifest and the service worker.
+	PWA PWAConfig `yaml:"pwa"`
+
+	// Slug holds settings of the slugs used in URLs.
+	Slug SlugConfig `yaml:"slug"`
+
+	// YearlyFeeds tells whether an Atom feed of the Posts written in each year
+	// is generated besides the feed of all Posts.
+	YearlyFeeds bool `yaml:"yearlyFeeds"`
+
+	// Strict tells whether problems of Posts reported as warnings (like
+	// duplicate titles) fail the build instead.
+	Strict bool `yaml:"strict"`
+
+	// TemplatePosts tells whether the Content of Posts is executed as a
+	// text/template (with the PostTemplateData) before it's rendered.
+	TemplatePosts bool `yaml:"templatePosts"`
+
+	// PhotoSizes are the maximum widths and heights of the display sizes
+	// generated for photo Posts. Defaults to 640 and 1280.
+	PhotoSizes []int `yaml:"photoSizes"`
+
+	// PhotoLocation tells whether the GPS location of photos is read from
+	// their EXIF data. Off by default so photos don't reveal where they were
+	// taken.
+	PhotoLocation bool `yaml:"photoLocation"`
+
+	// Heatmap tells whether the HeatmapFile with the numbers of Posts written
+	// each day and week is generated.
+	Heatmap bool `yaml:"heatmap"`
+
+	// Stale holds settings of the stale content report.
+	Stale StaleConfig `yaml:"stale"`
+
+	// Index holds settings of the index page.
+	Index IndexConfig `yaml:"index"`
+
+	// TagFeeds tells whether an Atom feed of the Posts tagged with each tag is
+	// generated besides the feed of all Posts.
+	TagFeeds bool `yaml:"tagFeeds"`
+
+	// DraftEncryption holds settings of the encryption of draft files.
+	DraftEncryption DraftEncryption `yaml:"draftEncryption"`
+
+	// CSP holds settings of the Content Security Policies of pages and of
+	// inlining stylesheets.
+	CSP CSPConfig `yaml:"csp"`
+
+	// Headers holds settings of the HeadersFile.
+	Headers HeadersConfig `yaml:"headers"`
+
+	// Taxonomies holds settings of the feeds and the sitemap entries of tag
+	// and author pages.
+	Taxonomies TaxonomiesConfig `yaml:"taxonomies"`
+
+	// Feeds holds settings of the feed of all Posts and of the tag, author
+	// and section feeds.
+	Feeds FeedsConfig `yaml:"feeds"`
+
+	// StrictTemplates tells whether templates (and Posts executed as
+	// templates) referencing missing keys of maps, like data files, fail the
+	// build with the name of the template and the line instead of printing
+	// nothing (or <no value> in Posts).
+	StrictTemplates bool `yaml:"strictTemplates"`
+
+	// Theme holds values of parameters of the theme (see ThemeConfig)
+	// overriding their defaults.
+	Theme map[string]interface{} `yaml:"theme"`
+
+	// RenderCache tells whether results of the Markdown render, transform
+	// plugins, Asciidoctor, Processors and scaled images (photo sizes and
+	// gallery thumbnails) are cached (keyed by hashes of their commands and
+	// inputs) in the render cache directory (see RenderCacheDir), so rebuilds
+	// don't run them again for unchanged Posts. Posts read from encrypted
+	// drafts aren't cached.
+	RenderCache bool `yaml:"renderCache"`
+}
+
+// TagMeta holds a human readable title and a description of a tag.
+type TagMeta struct {
+	Title       string `yaml:"title"`
+	Description string `yaml:"description"`
+}
+
+// ReadConfig reads the Config from the ConfigFile in the provided directory.
+// Tags from the TagsFile are added to the Config's Tags.
+//
+// If the files don't exist it returns the zero Config.
+func ReadConfig(dir string) (Config, error) {
+	var config Config
+
+	err := readYAML(filepath.Join(dir, ConfigFile), &config)
+	if err != nil {
+		return config, fmt.Errorf("failed to read config: %s", err)
+	}
+
+	var tags map[string]TagMeta
+	err = readYAML(filepath.Join(dir, TagsFile), &tags)
+	if err != nil {
+		return config, fmt.Errorf("failed to read tags: %s", err)
+	}
+
+	if len(tags) > 0 && config.Tags == nil {
+		config.Tags = map[string]TagMeta{}
+	}
+	for name, meta := range tags {
+		config.Tags[name] = meta
+	}
+
+	return config, nil
+}
+
+// readYAML decodes the YAML file at the path into v. A missing file is not an
+// error and leaves v unchanged.
+func readYAML(path string, v interface{}) error {
+	bytes, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return yaml.Unmarshal(bytes, v)
+}
+
+// canonicalTag returns the canonical name of the tag if it's an alias.
+// Aliases are matched by their slugs.
+func (c Config) canonicalTag(tag string) string {
+	for alias, canonical := range c.TagAliases {
+		if slug.Make(alias) == slug.Make(tag) {
+			return canonical
+		}
+	}
+	return tag
+}
+
+// missingKey returns the option (see template.Template.Option) telling how
+// templates handle missing map keys according to StrictTemplates.
+func (c Config) missingKey() string {
+	if c.StrictTemplates {
+		return "missingkey=error"
+	}
+	return "missingkey=default"
+}
+
+// location returns the time.Location of the Timezone.
+func (c Config) location() (*time.Location, error) {
+	loc, err := time.LoadLocation(c.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("unsupported timezone: %s", c.Timezone)
+	}
+	return loc, nil
+}
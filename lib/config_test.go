@@ -0,0 +1,53 @@
+package lib
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestReadConfigTags(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, ConfigFile), []byte(
+		"tags:\n  go:\n    title: Golang\n  web:\n    title: The Web\n"), 0600)
+	os.WriteFile(filepath.Join(dir, TagsFile), []byte(
+		"go:\n  title: Go\n  description: Posts about Go.\ncss:\n  description: Styles.\n"), 0600)
+
+	config, err := ReadConfig(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	blog := Blog{{Title: "Rome", Tags: []string{"go", "web", "css", "rust"}, Written: time.Now()}}
+	g, err := NewStaticBlogGenerator(blog, writeTestTemplates(t), t.TempDir(), func(string) {},
+		WithConfig(config))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the TagsFile overrides tags of the ConfigFile, tags it doesn't mention
+	// keep their defaults
+	want := map[string]TagMeta{
+		"go":   {Title: "Go", Description: "Posts about Go."},
+		"web":  {Title: "The Web"},
+		"css":  {Title: "css", Description: "Styles."},
+		"rust": {Title: "rust"},
+	}
+	for _, tag := range g.tags {
+		if got := (TagMeta{tag.Title, tag.Description}); got != want[tag.Name] {
+			t.Errorf("%s: want %v, got %v", tag.Name, want[tag.Name], got)
+		}
+	}
+	if len(g.tags) != len(want) {
+		t.Errorf("want %v, got %v", len(want), len(g.tags))
+	}
+}
+
+func TestReadConfigInvalidTags(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, TagsFile), []byte("go: [\n"), 0600)
+	if _, err := ReadConfig(dir); err == nil {
+		t.Errorf("want %v, got %v", "an error", err)
+	}
+}
@@ -0,0 +1,344 @@
+package lib
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	stdhtml "html"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Outputs of the Content Security Policies of pages (see CSPConfig).
+const (
+	// CSPMeta adds the policy of each page to it as a meta tag.
+	CSPMeta = "meta"
+
+	// CSPHeaders writes the policies to the HeadersFile.
+	CSPHeaders = "headers"
+
+	// CSPReport writes the policies to the CSPReportFile.
+	CSPReport = "report"
+)
+
+// CSPReportFile is the name of the file in the output directory with the
+// Content Security Policies of pages (see CSPReport).
+const CSPReportFile = "csp.json"
+
+var (
+	cspScriptRegexp     = regexp.MustCompile(`(?is)<script\b([^>]*)>(.*?)</script>`)
+	cspStyleRegexp      = regexp.MustCompile(`(?is)<style\b[^>]*>(.*?)</style>`)
+	cspLinkRegexp       = regexp.MustCompile(`(?is)<link\b[^>]*>`)
+	cspMediaRegexp      = regexp.MustCompile(`(?is)<(img|iframe|audio|video|source|embed)\b[^>]*>`)
+	cspTagRegexp        = regexp.MustCompile(`(?is)<[a-z][^>]*>`)
+	cspStyleAttrRegexp  = regexp.MustCompile(`(?is)\sstyle="([^"]*)"`)
+	cspHandlerRegexp    = regexp.MustCompile(`(?is)\son[a-z]+="([^"]*)"`)
+	cspHeadRegexp       = regexp.MustCompile(`(?is)<head\b[^>]*>`)
+	cspStylesheetRegexp = regexp.MustCompile(`(?i)\brel="[^"]*\bstylesheet\b[^"]*"`)
+)
+
+// CSPConfig holds settings of the Content Security Policies recommended for
+// the generated pages and of inlining small stylesheets into them.
+type CSPConfig struct {
+	// Output is CSPMeta, CSPHeaders or CSPReport. No policies are computed if
+	// it's empty.
+	Output string `yaml:"output"`
+
+	// InlineCSS is the maximum size (in bytes) of the Blog's stylesheets
+	// inlined into the pages linking them (to save requests for critical
+	// CSS). Stylesheets with relative url() references aren't inlined. No
+	// stylesheets are inlined if it's 0.
+	InlineCSS int `yaml:"inlineCSS"`
+}
+
+// ContentSecurityPolicy holds the sources of a page's resources allowed by
+// its Content Security Policy besides the Blog itself.
+type ContentSecurityPolicy struct {
+	// Scripts are the origins of external scripts and the hashes of inline
+	// ones.
+	Scripts []string
+
+	// Styles are the origins of external stylesheets and the hashes of
+	// inline ones.
+	Styles []string
+
+	Images []string
+	Media  []string
+	Frames []string
+}
+
+// String returns the policy as the value of the Content-Security-Policy
+// header.
+func (p ContentSecurityPolicy) String() string {
+	directives := []string{"default-src 'self'"}
+	for _, d := range []struct {
+		name    string
+		sources []string
+	}{
+		{"script-src", p.Scripts},
+		{"style-src", p.Styles},
+		{"img-src", p.Images},
+		{"media-src", p.Media},
+		{"frame-src", p.Frames},
+	} {
+		if len(d.sources) > 0 {
+			directives = append(directives, d.name+" 'self' "+strings.Join(d.sources, " "))
+		}
+	}
+	directives = append(directives, "object-src 'none'", "base-uri 'self'")
+	return strings.Join(directives, "; ")
+}
+
+// inlineCSS replaces links of the Blog's stylesheets not bigger than the
+// CSPConfig's InlineCSS in the generated pages with style elements.
+func (g StaticBlogGenerator) inlineCSS() error {
+	return g.processOutputPages(func(from, content string) (string, error) {
+		return cspLinkRegexp.ReplaceAllStringFunc(content, func(link string) string {
+			if !cspStylesheetRegexp.MatchString(link) {
+				return link
+			}
+
+			css, ok := g.inlinableCSS(from, htmlAttr(link, "href"))
+			if !ok {
+				return link
+			}
+			if media := htmlAttr(link, "media"); media != "" && media != "all" {
+				return `<style media="` + stdhtml.EscapeString(media) + `">` + css + "</style>"
+			}
+			return "<style>" + css + "</style>"
+		}), nil
+	})
+}
+
+// inlinableCSS returns the content of the Blog's stylesheet with the URL
+// linked from the page with the URL (relative to the Blog's root) if it can
+// be inlined.
+func (g StaticBlogGenerator) inlinableCSS(from, href string) (string, bool) {
+	if base := strings.TrimSuffix(g.options.config.URL, "/"); base != "" &&
+		strings.HasPrefix(href, base+"/") {
+		href = strings.TrimPrefix(href, base)
+	}
+	u, err := url.Parse(href)
+	if err != nil || href == "" || u.Scheme != "" || u.Host != "" {
+		return "", false
+	}
+
+	p := u.Path
+	if !strings.HasPrefix(p, "/") {
+		p = path.Join(path.Dir(from), p)
+	}
+	file := filepath.Join(g.outputDir, filepath.FromSlash(path.Clean(p)))
+	info, err := os.Stat(file)
+	if err != nil || info.IsDir() || info.Size() > int64(g.options.config.CSP.InlineCSS) {
+		return "", false
+	}
+
+	bytes, err := os.ReadFile(file)
+	if err != nil {
+		return "", false
+	}
+	css := string(bytes)
+	if strings.Contains(css, "</style") || strings.Contains(css, "@import") {
+		return "", false
+	}
+	for _, match := range urlCSSRegexp.FindAllStringSubmatch(css, -1) {
+		ref := match[2]
+		if !strings.HasPrefix(ref, "/") && !strings.HasPrefix(ref, "data:") &&
+			!strings.HasPrefix(ref, "#") && !strings.Contains(ref, "://") {
+			return "", false
+		}
+	}
+	return css, true
+}
+
+// generateCSP computes the ContentSecurityPolicy of each generated page and
+// outputs the policies according to the CSPConfig's Output. It returns the
+// policies by the URLs of the pages, so they can be written to the
+// HeadersFile.
+func (g StaticBlogGenerator) generateCSP() (map[string]string, error) {
+	output := g.options.config.CSP.Output
+	if output != CSPMeta && output != CSPHeaders && output != CSPReport {
+		return nil, fmt.Errorf("unsupported CSP output: %s", output)
+	}
+
+	policies := map[string]string{}
+	err := g.processOutputPages(func(from, content string) (string, error) {
+		policy := g.contentSecurityPolicy(content).String()
+		policies[g.options.config.withBasePath(g.pageURL(strings.TrimPrefix(from, "/")))] = policy
+		if output != CSPMeta {
+			return content, nil
+		}
+
+		meta := `<meta http-equiv="Content-Security-Policy" content="` +
+			stdhtml.EscapeString(policy) + `">`
+		if loc := cspHeadRegexp.FindStringIndex(content); loc != nil {
+			return content[:loc[1]] + meta + content[loc[1]:], nil
+		}
+		return content, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if output == CSPReport {
+		err = g.generateFile(CSPReportFile, func(w io.Writer) error {
+			enc := json.NewEncoder(w)
+			enc.SetIndent("", "  ")
+			return enc.Encode(policies)
+		})
+	}
+	return policies, err
+}
+
+// contentSecurityPolicy returns the ContentSecurityPolicy allowing the
+// external resources and the inline scripts and styles of the HTML content.
+// Inline event handlers and style attributes are allowed by their hashes
+// with 'unsafe-hashes'.
+func (g StaticBlogGenerator) contentSecurityPolicy(content string) ContentSecurityPolicy {
+	var p ContentSecurityPolicy
+	add := func(sources *[]string, source string) {
+		if source == "" {
+			return
+		}
+		for _, s := range *sources {
+			if s == source {
+				return
+			}
+		}
+		*sources = append(*sources, source)
+	}
+
+	for _, match := range cspScriptRegexp.FindAllStringSubmatch(content, -1) {
+		attrs := "<script" + match[1] + ">"
+		if src := htmlAttr(attrs, "src"); src != "" {
+			add(&p.Scripts, g.cspOrigin(src))
+		} else if !strings.Contains(strings.ToLower(htmlAttr(attrs, "type")), "json") &&
+			strings.TrimSpace(match[2]) != "" {
+			add(&p.Scripts, cspHash(match[2]))
+		}
+	}
+	for _, match := range cspStyleRegexp.FindAllStringSubmatch(content, -1) {
+		add(&p.Styles, cspHash(match[1]))
+	}
+	for _, link := range cspLinkRegexp.FindAllString(content, -1) {
+		if cspStylesheetRegexp.MatchString(link) {
+			add(&p.Styles, g.cspOrigin(htmlAttr(link, "href")))
+		}
+	}
+
+	var handlers, styles []string
+	for _, tag := range cspTagRegexp.FindAllString(content, -1) {
+		for _, match := range cspHandlerRegexp.FindAllStringSubmatch(tag, -1) {
+			add(&handlers, cspHash(stdhtml.UnescapeString(match[1])))
+		}
+		for _, match := range cspStyleAttrRegexp.FindAllStringSubmatch(tag, -1) {
+			add(&styles, cspHash(stdhtml.UnescapeString(match[1])))
+		}
+	}
+	if len(handlers) > 0 {
+		p.Scripts = append(append(p.Scripts, "'unsafe-hashes'"), handlers...)
+	}
+	if len(styles) > 0 {
+		p.Styles = append(append(p.Styles, "'unsafe-hashes'"), styles...)
+	}
+
+	for _, match := range cspMediaRegexp.FindAllStringSubmatch(content, -1) {
+		sources := &p.Media
+		switch strings.ToLower(match[1]) {
+		case "img":
+			sources = &p.Images
+		case "iframe", "embed":
+			sources = &p.Frames
+		}
+		add(sources, g.cspOrigin(htmlAttr(match[0], "src")))
+		add(&p.Images, g.cspOrigin(htmlAttr(match[0], "poster")))
+		for _, candidate := range strings.Split(htmlAttr(match[0], "srcset"), ",") {
+			if fields := strings.Fields(candidate); len(fields) > 0 {
+				add(&p.Images, g.cspOrigin(fields[0]))
+			}
+		}
+	}
+	return p
+}
+
+// cspOrigin returns the source allowing the resource with the URL in a
+// Content Security Policy or an empty string if it's the Blog's resource.
+func (g StaticBlogGenerator) cspOrigin(href string) string {
+	href = stdhtml.UnescapeString(href)
+	if strings.HasPrefix(href, "data:") {
+		return "data:"
+	}
+
+	u, err := url.Parse(href)
+	if err != nil || u.Host == "" {
+		return ""
+	}
+	if site, err := url.Parse(g.options.config.URL); err == nil && site.Host == u.Host {
+		return ""
+	}
+	if u.Scheme == "" {
+		return u.Host
+	}
+	return u.Scheme + "://" + u.Host
+}
+
+// cspHash returns the source allowing the inline script or style in a
+// Content Security Policy.
+func cspHash(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return "'sha256-" + base64.StdEncoding.EncodeToString(sum[:]) + "'"
+}
+
+// htmlAttrRegexps match the attributes read by htmlAttr.
+var htmlAttrRegexps = func() map[string]*regexp.Regexp {
+	regexps := map[string]*regexp.Regexp{}
+	for _, name := range []string{"src", "srcset", "href", "media", "type", "poster"} {
+		regexps[name] = regexp.MustCompile(`(?i)\s` + name +
+			`\s*=\s*("([^"]*)"|'([^']*)'|([^\s>]+))`)
+	}
+	return regexps
+}()
+
+// htmlAttr returns the unescaped value of the attribute with the name (one of
+// the htmlAttrRegexps) in the HTML tag or an empty string if it's not there.
+func htmlAttr(tag, name string) string {
+	match := htmlAttrRegexps[name].FindStringSubmatch(tag)
+	if match == nil {
+		return ""
+	}
+	return stdhtml.UnescapeString(match[2] + match[3] + match[4])
+}
+
+// processOutputPages replaces the content of each generated HTML file with the
+// result of the process function called with its URL (relative to the Blog's
+// root) and content.
+func (g StaticBlogGenerator) processOutputPages(process func(from, content string) (string, error)) error {
+	return filepath.WalkDir(g.outputDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || strings.ToLower(filepath.Ext(p)) != ".html" {
+			return err
+		}
+
+		bytes, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(g.outputDir, p)
+		if err != nil {
+			return err
+		}
+
+		content := string(bytes)
+		processed, err := process("/"+filepath.ToSlash(rel), content)
+		if err != nil || processed == content {
+			return err
+		}
+		return os.WriteFile(p, []byte(processed), 0600)
+	})
+}
@@ -0,0 +1,94 @@
+package lib
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestContentSecurityPolicy(t *testing.T) {
+	g := StaticBlogGenerator{options: newOptions([]Option{
+		WithConfig(Config{URL: "https://example.com"})})}
+
+	p := g.contentSecurityPolicy(`<head>
+<script src="https://cdn.example.org/a.js"></script>
+<script src="/local.js"></script>
+<script>alert(1)</script>
+<script type="application/ld+json">{"@type": "BlogPosting"}</script>
+<link rel="stylesheet" href="https://fonts.example.net/css">
+<link rel="alternate" href="https://feeds.example.net/atom.xml">
+<style>p{color:red}</style>
+</head>
+<body>
+<img src="https://example.com/rome.jpg" srcset="//img.example.org/a.jpg 2x">
+<img src="data:image/png;base64,AAAA">
+<iframe src="https://www.youtube.com/embed/1"></iframe>
+<button onclick="go()">Go</button>
+<p style="color: blue" onmouseover="hover()">Hi</p>
+</body>`)
+
+	want := ContentSecurityPolicy{
+		Scripts: []string{"https://cdn.example.org", cspHash("alert(1)"), "'unsafe-hashes'",
+			cspHash("go()"), cspHash("hover()")},
+		Styles: []string{cspHash("p{color:red}"), "https://fonts.example.net", "'unsafe-hashes'",
+			cspHash("color: blue")},
+		Images: []string{"img.example.org", "data:"},
+		Frames: []string{"https://www.youtube.com"},
+	}
+	if !reflect.DeepEqual(p, want) {
+		t.Errorf("want %+v, got %+v", want, p)
+	}
+
+	frames := ContentSecurityPolicy{Frames: p.Frames}.String()
+	if want := "default-src 'self'; frame-src 'self' https://www.youtube.com; " +
+		"object-src 'none'; base-uri 'self'"; frames != want {
+		t.Errorf("want %q, got %q", want, frames)
+	}
+}
+
+func TestGenerateCSP(t *testing.T) {
+	templates := writeTestTemplates(t)
+	os.WriteFile(filepath.Join(templates, "layout.tmpl"), []byte(`<html><head>`+
+		`<link rel="stylesheet" href="/small.css"><link rel="stylesheet" href="/big.css">`+
+		`</head><body>{{template "content" .}}<script>go()</script></body></html>`), 0600)
+	os.WriteFile(filepath.Join(templates, "small.css"), []byte("p{margin:0}"), 0600)
+	os.WriteFile(filepath.Join(templates, "big.css"), []byte(strings.Repeat("p{margin:0}", 10)), 0600)
+	blog := Blog{{Title: "Rome", Written: time.Now()}}
+
+	for _, output := range []string{CSPMeta, CSPHeaders} {
+		dir := t.TempDir()
+		g, err := NewStaticBlogGenerator(blog, templates, dir, func(string) {},
+			WithConfig(Config{CSP: CSPConfig{Output: output, InlineCSS: 20}}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := g.Generate(); err != nil {
+			t.Fatal(err)
+		}
+
+		policy := "default-src 'self'; script-src 'self' " + cspHash("go()") +
+			"; style-src 'self' " + cspHash("p{margin:0}") + "; object-src 'none'; base-uri 'self'"
+		index, _ := os.ReadFile(filepath.Join(dir, "index.html"))
+		if !strings.Contains(string(index), "<style>p{margin:0}</style>") ||
+			!strings.Contains(string(index), `href="/big.css"`) {
+			t.Errorf("%s: want small.css inlined, got %s", output, index)
+		}
+
+		if output == CSPMeta {
+			meta := `<head><meta http-equiv="Content-Security-Policy" content="` +
+				strings.ReplaceAll(policy, "'", "&#39;") + `">`
+			if !strings.Contains(string(index), meta) {
+				t.Errorf("want %s, got %s", meta, index)
+			}
+			continue
+		}
+
+		headers, _ := os.ReadFile(filepath.Join(dir, HeadersFile))
+		if want := "/index.html\n  Content-Security-Policy: " + policy + "\n"; !strings.Contains(string(headers), want) {
+			t.Errorf("want %q in %q", want, headers)
+		}
+	}
+}
@@ -0,0 +1,237 @@
+package lib
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// defaultDateFormat is the layout used by the formatDate template function if
+// no other layout is configured.
+const defaultDateFormat = "02 Jan, 2006"
+
+// dateLayouts are the layouts accepted for dates in the front matter.
+var dateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// parseDate parses a date in one of the dateLayouts. Dates without a time zone
+// are in the loc.
+func parseDate(value string, loc *time.Location) (time.Time, error) {
+	for _, layout := range dateLayouts {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unsupported date format: %s", value)
+}
+
+// parsePostDate parses the date of a post written in a format with its
+// metadata in a header: either in the Jan 2, 2006 format (like in Markdown
+// posts) or in one of the dateLayouts.
+func parsePostDate(value string, loc *time.Location) (time.Time, error) {
+	if t, err := time.ParseInLocation("Jan 2, 2006", value, loc); err == nil {
+		return t, nil
+	}
+	return parseDate(value, loc)
+}
+
+// dateLocale holds localized month and day names and their abbreviations.
+// Days start with Sunday like time.Weekday.
+type dateLocale struct {
+	months      []string
+	days        []string
+	shortMonths []string
+	shortDays   []string
+}
+
+var dateLocales = map[string]dateLocale{
+	"de": {
+		[]string{"Januar", "Februar", "März", "April", "Mai", "Juni", "Juli",
+			"August", "September", "Oktober", "November", "Dezember"},
+		[]string{"Sonntag", "Montag", "Dienstag", "Mittwoch", "Donnerstag",
+			"Freitag", "Samstag"},
+		[]string{"Jan", "Feb", "Mär", "Apr", "Mai", "Jun", "Jul", "Aug", "Sep",
+			"Okt", "Nov", "Dez"},
+		[]string{"So", "Mo", "Di", "Mi", "Do", "Fr", "Sa"},
+	},
+	"es": {
+		[]string{"enero", "febrero", "marzo", "abril", "mayo", "junio", "julio",
+			"agosto", "septiembre", "octubre", "noviembre", "diciembre"},
+		[]string{"domingo", "lunes", "martes", "miércoles", "jueves", "viernes",
+			"sábado"},
+		[]string{"ene", "feb", "mar", "abr", "may", "jun", "jul", "ago", "sept",
+			"oct", "nov", "dic"},
+		[]string{"dom", "lun", "mar", "mié", "jue", "vie", "sáb"},
+	},
+	"fr": {
+		[]string{"janvier", "février", "mars", "avril", "mai", "juin", "juillet",
+			"août", "septembre", "octobre", "novembre", "décembre"},
+		[]string{"dimanche", "lundi", "mardi", "mercredi", "jeudi", "vendredi",
+			"samedi"},
+		[]string{"janv.", "févr.", "mars", "avr.", "mai", "juin", "juil.", "août",
+			"sept.", "oct.", "nov.", "déc."},
+		[]string{"dim.", "lun.", "mar.", "mer.", "jeu.", "ven.", "sam."},
+	},
+	"it": {
+		[]string{"gennaio", "febbraio", "marzo", "aprile", "maggio", "giugno",
+			"luglio", "agosto", "settembre", "ottobre", "novembre", "dicembre"},
+		[]string{"domenica", "lunedì", "martedì", "mercoledì", "giovedì",
+			"venerdì", "sabato"},
+		[]string{"gen", "feb", "mar", "apr", "mag", "giu", "lug", "ago", "set",
+			"ott", "nov", "dic"},
+		[]string{"dom", "lun", "mar", "mer", "gio", "ven", "sab"},
+	},
+	"nl": {
+		[]string{"januari", "februari", "maart", "april", "mei", "juni", "juli",
+			"augustus", "september", "oktober", "november", "december"},
+		[]string{"zondag", "maandag", "dinsdag", "woensdag", "donderdag",
+			"vrijdag", "zaterdag"},
+		[]string{"jan", "feb", "mrt", "apr", "mei", "jun", "jul", "aug", "sep",
+			"okt", "nov", "dec"},
+		[]string{"zo", "ma", "di", "wo", "do", "vr", "za"},
+	},
+	"pt": {
+		[]string{"janeiro", "fevereiro", "março", "abril", "maio", "junho",
+			"julho", "agosto", "setembro", "outubro", "novembro", "dezembro"},
+		[]string{"domingo", "segunda-feira", "terça-feira", "quarta-feira",
+			"quinta-feira", "sexta-feira", "sábado"},
+		[]string{"jan", "fev", "mar", "abr", "mai", "jun", "jul", "ago", "set",
+			"out", "nov", "dez"},
+		[]string{"dom", "seg", "ter", "qua", "qui", "sex", "sáb"},
+	},
+}
+
+// dateFormatter formats dates using a default layout and optionally localized
+// month and day names.
+type dateFormatter struct {
+	layout   string
+	locale   *dateLocale
+	location *time.Location
+}
+
+func newDateFormatter(config Config) (dateFormatter, error) {
+	f := dateFormatter{layout: config.DateFormat}
+	if f.layout == "" {
+		f.layout = defaultDateFormat
+	}
+
+	var err error
+	f.location, err = config.location()
+	if err != nil {
+		return f, err
+	}
+
+	var locale dateLocale
+	if config.Locale != "" {
+		var ok bool
+		locale, ok = dateLocales[config.Locale]
+		if !ok {
+			return f, fmt.Errorf("unsupported locale: %s", config.Locale)
+		}
+	}
+
+	if len(config.MonthNames) > 0 {
+		if len(config.MonthNames) != 12 {
+			return f, fmt.Errorf("want 12 month names, got %d",
+				len(config.MonthNames))
+		}
+		locale.months = config.MonthNames
+		locale.shortMonths = abbreviateAll(config.MonthNames)
+	}
+	if len(config.ShortMonthNames) > 0 {
+		if len(config.ShortMonthNames) != 12 {
+			return f, fmt.Errorf("want 12 short month names, got %d",
+				len(config.ShortMonthNames))
+		}
+		locale.shortMonths = config.ShortMonthNames
+	}
+
+	if len(config.DayNames) > 0 {
+		if len(config.DayNames) != 7 {
+			return f, fmt.Errorf("want 7 day names, got %d", len(config.DayNames))
+		}
+		locale.days = config.DayNames
+		locale.shortDays = abbreviateAll(config.DayNames)
+	}
+	if len(config.ShortDayNames) > 0 {
+		if len(config.ShortDayNames) != 7 {
+			return f, fmt.Errorf("want 7 short day names, got %d", len(config.ShortDayNames))
+		}
+		locale.shortDays = config.ShortDayNames
+	}
+
+	if locale.months != nil || locale.days != nil || locale.shortMonths != nil ||
+		locale.shortDays != nil {
+		f.locale = &locale
+	}
+	return f, nil
+}
+
+var nameLayoutRegexp = regexp.MustCompile(`January|Jan|Monday|Mon`)
+
+// format formats the time in the configured location using the layout or the
+// default layout if it's empty. Month and day names in the layout are
+// localized.
+func (f dateFormatter) format(t time.Time, layout string) string {
+	if layout == "" {
+		layout = f.layout
+	}
+	t = t.In(f.location)
+
+	if f.locale == nil {
+		return t.Format(layout)
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, loc := range nameLayoutRegexp.FindAllStringIndex(layout, -1) {
+		b.WriteString(t.Format(layout[last:loc[0]]))
+		b.WriteString(f.name(t, layout[loc[0]:loc[1]]))
+		last = loc[1]
+	}
+	b.WriteString(t.Format(layout[last:]))
+	return b.String()
+}
+
+// rfc3339 formats the time in the configured location for feeds and sitemaps.
+// The zero time is formatted as an empty string.
+func (f dateFormatter) rfc3339(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.In(f.location).Format(time.RFC3339)
+}
+
+func (f dateFormatter) name(t time.Time, token string) string {
+	switch {
+	case token == "January" && f.locale.months != nil:
+		return f.locale.months[t.Month()-1]
+	case token == "Jan" && f.locale.shortMonths != nil:
+		return f.locale.shortMonths[t.Month()-1]
+	case token == "Monday" && f.locale.days != nil:
+		return f.locale.days[t.Weekday()]
+	case token == "Mon" && f.locale.shortDays != nil:
+		return f.locale.shortDays[t.Weekday()]
+	}
+	return t.Format(token)
+}
+
+// abbreviateAll returns the first three letters of the custom names, which
+// are used if their abbreviations aren't configured.
+func abbreviateAll(names []string) []string {
+	short := make([]string, len(names))
+	for i, name := range names {
+		runes := []rune(name)
+		if len(runes) > 3 {
+			runes = runes[:3]
+		}
+		short[i] = string(runes)
+	}
+	return short
+}
@@ -0,0 +1,76 @@
+package lib
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDateFormatterWithLocale(t *testing.T) {
+	f, err := newDateFormatter(Config{Locale: "de"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	date := time.Date(2021, 3, 10, 0, 0, 0, 0, time.UTC)
+	if s := f.format(date, "Monday, 2. January 2006 (Jan)"); s != "Mittwoch, 10. März 2021 (Mär)" {
+		t.Errorf("want %q, got %q", "Mittwoch, 10. März 2021 (Mär)", s)
+	}
+
+	if s := f.format(date, ""); s != "10 Mär, 2021" {
+		t.Errorf("want %q, got %q", "10 Mär, 2021", s)
+	}
+}
+
+func TestDateFormatterAbbreviations(t *testing.T) {
+	f, err := newDateFormatter(Config{Locale: "fr"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for date, want := range map[time.Time]string{
+		time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC): "mar. 01 juin, 2021",
+		time.Date(2021, 7, 1, 0, 0, 0, 0, time.UTC): "jeu. 01 juil., 2021",
+	} {
+		if s := f.format(date, "Mon 02 Jan, 2006"); s != want {
+			t.Errorf("want %q, got %q", want, s)
+		}
+	}
+
+	f, err = newDateFormatter(Config{MonthNames: []string{"Jänner", "Feber", "März",
+		"April", "Mai", "Juni", "Juli", "August", "September", "Oktober", "November",
+		"Dezember"}, ShortDayNames: []string{"So.", "Mo.", "Di.", "Mi.", "Do.", "Fr.", "Sa."}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	date := time.Date(2021, 1, 10, 0, 0, 0, 0, time.UTC)
+	if s := f.format(date, "Mon 2 Jan"); s != "So. 10 Jän" {
+		t.Errorf("want %q, got %q", "So. 10 Jän", s)
+	}
+
+	if _, err := newDateFormatter(Config{ShortDayNames: []string{"So"}}); err == nil {
+		t.Errorf("want an error, got nil")
+	}
+}
+
+func TestParsePostDate(t *testing.T) {
+	loc := time.FixedZone("CET", 3600)
+	for value, want := range map[string]time.Time{
+		"Jan 2, 2009":               time.Date(2009, 1, 2, 0, 0, 0, 0, loc),
+		"2009-01-02":                time.Date(2009, 1, 2, 0, 0, 0, 0, loc),
+		"2009-01-02 10:30":          time.Date(2009, 1, 2, 10, 30, 0, 0, loc),
+		"2009-01-02T10:30:00Z":      time.Date(2009, 1, 2, 10, 30, 0, 0, time.UTC),
+		"2009-01-02T10:30:00+02:00": time.Date(2009, 1, 2, 8, 30, 0, 0, time.UTC),
+	} {
+		written, err := parsePostDate(value, loc)
+		if err != nil {
+			t.Errorf("%s: want nil, got %v", value, err)
+		} else if !written.Equal(want) {
+			t.Errorf("%s: want %v, got %v", value, want, written)
+		}
+	}
+
+	for _, value := range []string{"", "2 Jan 2009", "January 2, 2009"} {
+		if _, err := parsePostDate(value, loc); err == nil {
+			t.Errorf("%q: want error, got nil", value)
+		}
+	}
+}
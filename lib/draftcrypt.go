@@ -0,0 +1,236 @@
+package lib
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Tools encrypting drafts (see DraftEncryption).
+const (
+	DraftEncryptionAge = "age"
+	DraftEncryptionGPG = "gpg"
+)
+
+// DraftEncryption holds settings of the encryption of draft files at rest, so
+// drafts can be synced through public repositories. Encrypted drafts are
+// stored with the tool's extension appended (for example idea.md.age) and
+// decrypted when they're read. The age or gpg command has to be installed.
+type DraftEncryption struct {
+	// Tool is DraftEncryptionAge or DraftEncryptionGPG.
+	Tool string `yaml:"tool"`
+
+	// Recipients are the age public keys or GPG key IDs (or emails) drafts
+	// are encrypted to.
+	Recipients []string `yaml:"recipients"`
+
+	// Identity is the path of the age identity file (the private key)
+	// decrypting drafts (~/ is the home directory). The LITEPUB_DRAFT_IDENTITY
+	// environment variable is used if it's empty. GPG uses its keyring
+	// instead.
+	Identity string `yaml:"identity"`
+}
+
+func (e DraftEncryption) validate() error {
+	if e.Tool != DraftEncryptionAge && e.Tool != DraftEncryptionGPG {
+		return fmt.Errorf("unsupported draft encryption tool: %s", e.Tool)
+	}
+	if len(e.Recipients) == 0 {
+		return errors.New("draft encryption recipients are missing")
+	}
+	return nil
+}
+
+// ext returns the extension of files encrypted with the Tool.
+func (e DraftEncryption) ext() string {
+	return "." + e.Tool
+}
+
+// encryptedExt returns the extension of the encrypted file with the name (.age
+// or .gpg) or an empty string if the file isn't encrypted.
+func encryptedExt(name string) string {
+	ext := strings.ToLower(path.Ext(name))
+	if ext == "."+DraftEncryptionAge || ext == "."+DraftEncryptionGPG {
+		return ext
+	}
+	return ""
+}
+
+// encrypt encrypts the data to the Recipients.
+func (e DraftEncryption) encrypt(data []byte) ([]byte, error) {
+	err := e.validate()
+	if err != nil {
+		return nil, err
+	}
+
+	var args []string
+	if e.Tool == DraftEncryptionAge {
+		args = []string{"--encrypt", "--armor"}
+	} else {
+		args = []string{"--batch", "--yes", "--quiet", "--encrypt", "--armor"}
+	}
+	for _, recipient := range e.Recipients {
+		args = append(args, "-r", recipient)
+	}
+	return runCrypto(e.Tool, args, data)
+}
+
+// decrypt decrypts the data of a file encrypted with the tool of the
+// extension.
+func (e DraftEncryption) decrypt(data []byte, ext string) ([]byte, error) {
+	if ext == "."+DraftEncryptionGPG {
+		return runCrypto(DraftEncryptionGPG, []string{"--batch", "--quiet", "--decrypt"}, data)
+	}
+
+	identity := e.Identity
+	if identity == "" {
+		identity = os.Getenv("LITEPUB_DRAFT_IDENTITY")
+	}
+	if identity == "" {
+		return nil, errors.New("age identity is missing")
+	}
+	if strings.HasPrefix(identity, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			identity = filepath.Join(home, identity[2:])
+		}
+	}
+	return runCrypto(DraftEncryptionAge, []string{"--decrypt", "-i", identity}, data)
+}
+
+func runCrypto(tool string, args []string, data []byte) ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(tool, args...)
+	cmd.Stdin = bytes.NewReader(data)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+	if err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return nil, fmt.Errorf("%s failed: %s", tool, message)
+		}
+		return nil, fmt.Errorf("%s failed: %s", tool, err)
+	}
+	return stdout.Bytes(), nil
+}
+
+// EncryptDrafts encrypts the plain draft files in the draft directory of the
+// posts directory (and its subdirectories) with the DraftEncryption and
+// removes them. It returns the paths of the encrypted files.
+func EncryptDrafts(postsDir string, encryption DraftEncryption) ([]string, error) {
+	err := encryption.validate()
+	if err != nil {
+		return nil, err
+	}
+
+	var encrypted []string
+	err = filepath.WalkDir(filepath.Join(postsDir, draftDir), func(p string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() || strings.HasPrefix(d.Name(), ".") ||
+			encryptedExt(d.Name()) != "" {
+			return err
+		}
+
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		ciphertext, err := encryption.encrypt(data)
+		if err != nil {
+			return fmt.Errorf("failed to encrypt %s: %s", p, err)
+		}
+
+		err = os.WriteFile(p+encryption.ext(), ciphertext, 0600)
+		if err != nil {
+			return err
+		}
+		encrypted = append(encrypted, p+encryption.ext())
+		return os.Remove(p)
+	})
+	return encrypted, err
+}
+
+// EncryptedStore is a PostStore encrypting draft files (the ones in the draft
+// directory) of another PostStore with a DraftEncryption. Encrypted files are
+// listed and read without the encryption extension, so they're transparent
+// to its users.
+type EncryptedStore struct {
+	store      PostStore
+	encryption DraftEncryption
+}
+
+// NewEncryptedStore creates an EncryptedStore storing files in the store.
+func NewEncryptedStore(store PostStore, encryption DraftEncryption) EncryptedStore {
+	return EncryptedStore{store, encryption}
+}
+
+// List returns all files in the store, encrypted ones without the encryption
+// extension.
+func (s EncryptedStore) List() ([]StoredFile, error) {
+	files, err := s.store.List()
+	if err != nil {
+		return nil, err
+	}
+
+	for i, file := range files {
+		if encryptedExt(file.Name) != "" {
+			files[i].Name = strings.TrimSuffix(file.Name, path.Ext(file.Name))
+		}
+	}
+	return files, nil
+}
+
+// Read returns the content of the file with the name, decrypted if it's
+// encrypted.
+func (s EncryptedStore) Read(name string) ([]byte, error) {
+	for _, ext := range []string{"." + DraftEncryptionAge, "." + DraftEncryptionGPG} {
+		data, err := s.store.Read(name + ext)
+		if err == nil {
+			return s.encryption.decrypt(data, ext)
+		}
+	}
+	return s.store.Read(name)
+}
+
+// Write creates or replaces the file with the name, encrypted if it's a draft.
+// Other versions of an encrypted draft (its plaintext file or a file
+// encrypted with the other tool) are removed, so the plaintext doesn't stay on
+// disk and readers see only one file.
+func (s EncryptedStore) Write(name string, data []byte) error {
+	if !strings.HasPrefix(path.Clean("/"+name), "/"+draftDir+"/") {
+		return s.store.Write(name, data)
+	}
+
+	ciphertext, err := s.encryption.encrypt(data)
+	if err != nil {
+		return err
+	}
+	err = s.store.Write(name+s.encryption.ext(), ciphertext)
+	if err != nil {
+		return err
+	}
+
+	for _, twin := range []string{name, name + "." + DraftEncryptionAge, name + "." + DraftEncryptionGPG} {
+		if twin == name+s.encryption.ext() {
+			continue
+		}
+		if err := s.store.Remove(twin); err != nil {
+			return fmt.Errorf("failed to remove %s: %s", twin, err)
+		}
+	}
+	return nil
+}
+
+// Remove removes the file with the name and its encrypted versions.
+func (s EncryptedStore) Remove(name string) error {
+	for _, file := range []string{name, name + "." + DraftEncryptionAge, name + "." + DraftEncryptionGPG} {
+		if err := s.store.Remove(file); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package lib
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fakeAge puts an age command "encrypting" with base64 on the PATH.
+func fakeAge(t *testing.T) {
+	bin := t.TempDir()
+	script := "#!/bin/sh\ncase \"$1\" in --decrypt) base64 -d ;; *) base64 ;; esac\n"
+	os.WriteFile(filepath.Join(bin, "age"), []byte(script), 0700)
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+func TestEncryptDrafts(t *testing.T) {
+	fakeAge(t)
+	t.Setenv("LITEPUB_DRAFT_IDENTITY", "drafts.txt")
+
+	dir := t.TempDir()
+	draft := filepath.Join(dir, postsDir, draftDir, "idea.md")
+	os.MkdirAll(filepath.Dir(draft), 0700)
+	os.WriteFile(draft, []byte("# Idea\n\n*Jan 2, 2009*\n\nSecret.\n"), 0600)
+
+	encryption := DraftEncryption{Tool: DraftEncryptionAge, Recipients: []string{"age1example"}}
+	encrypted, err := EncryptDrafts(filepath.Join(dir, postsDir), encryption)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(encrypted) != 1 || encrypted[0] != draft+".age" {
+		t.Errorf("want %v, got %v", []string{draft + ".age"}, encrypted)
+	}
+	if _, err := os.Stat(draft); err == nil {
+		t.Errorf("want the plain draft removed")
+	}
+	if bytes, _ := os.ReadFile(draft + ".age"); strings.Contains(string(bytes), "Secret") {
+		t.Errorf("want the draft encrypted, got %q", bytes)
+	}
+
+	blog, err := NewMarkdownBlog(dir, WithConfig(Config{DraftEncryption: encryption})).Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(blog) != 1 || blog[0].Title != "Idea" || !blog[0].Draft {
+		t.Errorf("want %v, got %v", "Idea", blog)
+	}
+
+	t.Setenv("LITEPUB_DRAFT_IDENTITY", "")
+	var warnings []string
+	blog, err = NewMarkdownBlog(dir, WithConfig(Config{DraftEncryption: encryption}),
+		WithWarnFunc(func(message string) { warnings = append(warnings, message) })).Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(blog) != 0 || len(warnings) != 1 {
+		t.Errorf("want the draft skipped with a warning, got %v and %v", blog, warnings)
+	}
+
+	if _, err := EncryptDrafts(dir, DraftEncryption{Tool: "rot13"}); err == nil {
+		t.Errorf("want an error, got nil")
+	}
+}
+
+func TestEncryptedStore(t *testing.T) {
+	fakeAge(t)
+	t.Setenv("LITEPUB_DRAFT_IDENTITY", "drafts.txt")
+
+	dir := t.TempDir()
+	store := NewEncryptedStore(NewDirStore(dir),
+		DraftEncryption{Tool: DraftEncryptionAge, Recipients: []string{"age1example"}})
+
+	for _, name := range []string{"draft/idea.md", "rome.md"} {
+		if err := store.Write(name, []byte("# "+name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "draft", "idea.md.age")); err != nil {
+		t.Errorf("want the encrypted draft, got %v", err)
+	}
+	if bytes, _ := os.ReadFile(filepath.Join(dir, "rome.md")); string(bytes) != "# rome.md" {
+		t.Errorf("want %q, got %q", "# rome.md", bytes)
+	}
+
+	// writing a draft removes its plaintext file
+	os.WriteFile(filepath.Join(dir, "draft", "plan.md"), []byte("# Plan"), 0600)
+	if err := store.Write("draft/plan.md", []byte("# draft/plan.md")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "draft", "plan.md")); err == nil {
+		t.Errorf("want the plaintext draft removed")
+	}
+
+	files, err := store.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 3 {
+		t.Errorf("want %v files, got %v", 3, files)
+	}
+	for _, file := range files {
+		data, err := store.Read(file.Name)
+		if err != nil || string(data) != "# "+file.Name {
+			t.Errorf("want %q, got %q (%v)", "# "+file.Name, data, err)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package lib
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+// titleKey returns the title in lowercase without punctuation and spaces, so
+// titles differing only in them have the same key.
+func titleKey(title string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, title)
+}
+
+// checkDuplicateTitles reports Posts with identical titles or titles differing
+// only in case or punctuation and Posts with the same page (their slugs
+// collide) as warnings or, in the strict mode (see Config.Strict), fails.
+func (g StaticBlogGenerator) checkDuplicateTitles() error {
+	titles := map[string]Post{}
+	pages := map[string]Post{}
+	duplicates := 0
+	for _, post := range append(g.posts[:len(g.posts):len(g.posts)], g.unlisted...) {
+		if first, ok := titles[titleKey(post.Title)]; ok {
+			g.options.warn(fmt.Sprintf("duplicate title %q (same as %q)", post.Title, first.Title))
+			duplicates++
+		} else {
+			titles[titleKey(post.Title)] = post
+		}
+
+		page := filepath.ToSlash(g.postPath(post))
+		if first, ok := pages[page]; ok {
+			if titleKey(first.Title) != titleKey(post.Title) {
+				g.options.warn(fmt.Sprintf("%q has the same page %s as %q", post.Title, page, first.Title))
+				duplicates++
+			}
+		} else {
+			pages[page] = post
+		}
+	}
+
+	if duplicates > 0 && g.options.config.Strict {
+		return fmt.Errorf("found %d duplicate title(s)", duplicates)
+	}
+	return nil
+}
+
+// checkTagPages reports tags whose page is the page of the tags index (like a
+// tag with the slug index and the default URL style) as warnings or, in the
+// strict mode (see Config.Strict), fails.
+func (g StaticBlogGenerator) checkTagPages() error {
+	if g.tagsTemplate == nil {
+		return nil
+	}
+
+	index := filepath.Join("tags", "index.html")
+	for _, tag := range g.tags {
+		if g.tagPath(tag.Name) != index {
+			continue
+		}
+
+		message := fmt.Sprintf("tag %q has the same page %s as the tags index", tag.Name,
+			filepath.ToSlash(index))
+		if g.options.config.Strict {
+			return fmt.Errorf("%s", message)
+		}
+		g.options.warn(message)
+	}
+	return nil
+}
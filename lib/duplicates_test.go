@@ -0,0 +1,68 @@
+package lib
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCheckDuplicateTitles(t *testing.T) {
+	templates := writeTestTemplates(t)
+	blog := Blog{
+		{Title: "Rome", Written: time.Now()},
+		{Title: "rome!", Written: time.Now()},
+		{Title: "Café", Written: time.Now()},
+		{Title: "Cafe", Written: time.Now(), Unlisted: true},
+		{Title: "Paris", Written: time.Now()},
+	}
+
+	var warnings []string
+	_, err := NewStaticBlogGenerator(blog, templates, t.TempDir(), func(string) {},
+		WithWarnFunc(func(message string) { warnings = append(warnings, message) }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(warnings) != 2 {
+		t.Errorf("want %v, got %v", 2, warnings)
+	}
+
+	_, err = NewStaticBlogGenerator(blog, templates, t.TempDir(), func(string) {},
+		WithConfig(Config{Strict: true}))
+	if err == nil || err.Error() != "found 2 duplicate title(s)" {
+		t.Errorf("want %v, got %v", "found 2 duplicate title(s)", err)
+	}
+}
+
+func TestCheckTagPages(t *testing.T) {
+	templates := writeTestTemplates(t)
+	os.WriteFile(filepath.Join(templates, "tags.tmpl"),
+		[]byte(`{{define "content"}}{{range .}}{{.Name}};{{end}}{{end}}`), 0600)
+	blog := Blog{{Title: "Rome", Tags: []string{"Index"}, Written: time.Now()}}
+
+	var warnings []string
+	_, err := NewStaticBlogGenerator(blog, templates, t.TempDir(), func(string) {},
+		WithWarnFunc(func(message string) { warnings = append(warnings, message) }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `tag "Index" has the same page tags/index.html as the tags index`; len(warnings) != 1 ||
+		warnings[0] != want {
+		t.Errorf("want %v, got %v", want, warnings)
+	}
+
+	_, err = NewStaticBlogGenerator(blog, templates, t.TempDir(), func(string) {},
+		WithConfig(Config{Strict: true}))
+	if err == nil {
+		t.Errorf("want %v, got %v", "an error", err)
+	}
+
+	// with the slash URL style the tag's page is tags/index/index.html
+	warnings = nil
+	_, err = NewStaticBlogGenerator(blog, templates, t.TempDir(), func(string) {},
+		WithConfig(Config{URLStyle: URLStyleSlash}),
+		WithWarnFunc(func(message string) { warnings = append(warnings, message) }))
+	if err != nil || len(warnings) != 0 {
+		t.Errorf("want no warnings, got %v and %v", err, warnings)
+	}
+}
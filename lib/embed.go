@@ -0,0 +1,124 @@
+package lib
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	stdhtml "html"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+var (
+	youtubeThumbnailURL = "https://i.ytimg.com/vi/%s/hqdefault.jpg"
+	vimeoOEmbedURL      = "https://vimeo.com/api/oembed.json?url=https://vimeo.com/%s"
+)
+
+var videoIDRegexp = regexp.MustCompile(`^[\w-]+$`)
+
+// youtube renders a click-to-load embed of the YouTube video with the ID and
+// an optional title.
+func youtube(g StaticBlogGenerator, args []string) (string, error) {
+	id, title, err := videoArgs(args)
+	if err != nil {
+		return "", err
+	}
+
+	return g.videoEmbed("youtube", "YouTube",
+		"https://www.youtube-nocookie.com/embed/"+id+"?autoplay=1",
+		"https://www.youtube.com/watch?v="+id,
+		fmt.Sprintf(youtubeThumbnailURL, id), title), nil
+}
+
+// vimeo renders a click-to-load embed of the Vimeo video with the ID and an
+// optional title.
+func vimeo(g StaticBlogGenerator, args []string) (string, error) {
+	id, title, err := videoArgs(args)
+	if err != nil {
+		return "", err
+	}
+
+	thumbnail, err := vimeoThumbnailURL(id)
+	if err != nil {
+		g.options.warn(fmt.Sprintf("failed to get thumbnail of Vimeo video %s: %s", id, err))
+	}
+
+	return g.videoEmbed("vimeo", "Vimeo",
+		"https://player.vimeo.com/video/"+id+"?autoplay=1&dnt=1",
+		"https://vimeo.com/"+id, thumbnail, title), nil
+}
+
+// mastodon renders a click-to-load embed of the Mastodon post at the URL.
+func mastodon(g StaticBlogGenerator, args []string) (string, error) {
+	if len(args) != 1 {
+		return "", errors.New("want a post URL")
+	}
+
+	u, err := url.Parse(args[0])
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		return "", fmt.Errorf("invalid post URL: %s", args[0])
+	}
+	link := strings.TrimSuffix(u.String(), "/")
+
+	return fmt.Sprintf(`<div class="embed embed-mastodon" data-embed-src="%s">`+
+		`<a class="embed-load" href="%s">View post on %s</a></div>`,
+		stdhtml.EscapeString(link+"/embed"), stdhtml.EscapeString(link),
+		stdhtml.EscapeString(u.Host)), nil
+}
+
+func videoArgs(args []string) (string, string, error) {
+	if len(args) < 1 || len(args) > 2 || !videoIDRegexp.MatchString(args[0]) {
+		return "", "", errors.New("want a video ID and an optional title")
+	}
+
+	title := "Video"
+	if len(args) == 2 {
+		title = args[1]
+	}
+	return args[0], title, nil
+}
+
+// videoEmbed renders a link to the video that's replaced with the embedded
+// player when clicked (with a script handling .embed-load links). The
+// thumbnail is downloaded to the output directory, so no third party is
+// contacted before the click.
+func (g StaticBlogGenerator) videoEmbed(class, site, embedURL, link,
+	thumbnail, title string) string {
+	var img string
+	if thumbnail != "" {
+		src, err := g.localizeImage(thumbnail)
+		if err != nil {
+			g.options.warn(fmt.Sprintf("failed to download thumbnail %s: %s", thumbnail, err))
+		} else {
+			img = fmt.Sprintf(`<img src="%s" alt="%s">`, stdhtml.EscapeString(src),
+				stdhtml.EscapeString(title))
+		}
+	}
+
+	return fmt.Sprintf(`<div class="embed embed-%s" data-embed-src="%s">`+
+		`<a class="embed-load" href="%s">%s<span>Play %s on %s</span></a></div>`,
+		class, stdhtml.EscapeString(embedURL), stdhtml.EscapeString(link), img,
+		stdhtml.EscapeString(title), site)
+}
+
+// vimeoThumbnailURL returns the URL of the Vimeo video's thumbnail provided
+// by the Vimeo oEmbed API.
+func vimeoThumbnailURL(id string) (string, error) {
+	resp, err := remoteImagesClient.Get(fmt.Sprintf(vimeoOEmbedURL, id))
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status: %s", resp.Status)
+	}
+
+	var oembed struct {
+		ThumbnailURL string `json:"thumbnail_url"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&oembed)
+	return oembed.ThumbnailURL, err
+}
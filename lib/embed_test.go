@@ -0,0 +1,56 @@
+package lib
+
+import (
+	"image"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestYouTubeShortcode(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/jpeg")
+		w.Write([]byte("jpeg"))
+	}))
+	defer server.Close()
+
+	defer func(url string) { youtubeThumbnailURL = url }(youtubeThumbnailURL)
+	youtubeThumbnailURL = server.URL + "/vi/%s/hqdefault.jpg"
+
+	g := StaticBlogGenerator{outputDir: t.TempDir(), progressFunc: func(string) {},
+		localized: map[string]string{}, thumbnails: map[string]image.Point{},
+		options: options{cacheDir: t.TempDir()}}
+
+	html, err := g.expandShortcodes(`{{< youtube dQw4w9WgXcQ "Never Gonna" >}}`)
+	if err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+
+	for _, want := range []string{
+		`data-embed-src="https://www.youtube-nocookie.com/embed/dQw4w9WgXcQ?autoplay=1"`,
+		`href="https://www.youtube.com/watch?v=dQw4w9WgXcQ"`,
+		`<img src="/images/remote/`,
+		`<span>Play Never Gonna on YouTube</span>`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("want %q in %q", want, html)
+		}
+	}
+	if strings.Contains(html, server.URL) {
+		t.Errorf("want no remote URLs in %q", html)
+	}
+}
+
+func TestMastodonShortcode(t *testing.T) {
+	html, err := mastodon(StaticBlogGenerator{}, []string{"https://mastodon.social/@rome/123"})
+	if err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+
+	want := `<div class="embed embed-mastodon" data-embed-src="https://mastodon.social/@rome/123/embed">` +
+		`<a class="embed-load" href="https://mastodon.social/@rome/123">View post on mastodon.social</a></div>`
+	if html != want {
+		t.Errorf("want %q, got %q", want, html)
+	}
+}
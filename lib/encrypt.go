@@ -0,0 +1,89 @@
+package lib
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"html/template"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// pbkdf2Iterations is the number of PBKDF2-HMAC-SHA256 iterations deriving
+// keys of encrypted Posts from their passwords.
+const pbkdf2Iterations = 600000
+
+// protectedSummary replaces summaries of password protected Posts (including
+// ones set in the front matter).
+const protectedSummary = "This post is password protected."
+
+// encryptedPost is the wrapper of an encrypted Post's content. The script
+// decrypts the content with the Web Crypto API and replaces the wrapper with
+// it.
+const encryptedPost = `<div class="encrypted" data-salt="%s" data-iv="%s" data-iterations="%d" data-ciphertext="%s">
+  <form>
+    <p>` + protectedSummary + `</p>
+    <input type="password" placeholder="Password" required>
+    <button type="submit">Unlock</button>
+    <p class="encrypted-error" hidden>Wrong password.</p>
+  </form>
+</div>
+<script>
+(function () {
+  var wrapper = document.currentScript.previousElementSibling;
+  var form = wrapper.querySelector('form');
+  function bytes(b64) {
+    return Uint8Array.from(atob(b64), function (c) { return c.charCodeAt(0); });
+  }
+  form.addEventListener('submit', function (event) {
+    event.preventDefault();
+    var password = new TextEncoder().encode(form.querySelector('input').value);
+    crypto.subtle.importKey('raw', password, 'PBKDF2', false, ['deriveKey'])
+      .then(function (key) {
+        return crypto.subtle.deriveKey({name: 'PBKDF2', hash: 'SHA-256',
+          salt: bytes(wrapper.dataset.salt), iterations: +wrapper.dataset.iterations},
+          key, {name: 'AES-GCM', length: 256}, false, ['decrypt']);
+      })
+      .then(function (key) {
+        return crypto.subtle.decrypt({name: 'AES-GCM', iv: bytes(wrapper.dataset.iv)},
+          key, bytes(wrapper.dataset.ciphertext));
+      })
+      .then(function (content) {
+        wrapper.outerHTML = new TextDecoder().decode(content);
+      })
+      .catch(function () {
+        form.querySelector('.encrypted-error').hidden = false;
+      });
+  });
+})();
+</script>`
+
+// encrypt encrypts the HTML with AES-GCM using a key derived from the
+// password and returns it wrapped in encryptedPost.
+func encrypt(html template.HTML, password string) (template.HTML, error) {
+	salt := make([]byte, 16)
+	iv := make([]byte, 12)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	if _, err := rand.Read(iv); err != nil {
+		return "", err
+	}
+
+	block, err := aes.NewCipher(pbkdf2.Key([]byte(password), salt, pbkdf2Iterations, 32, sha256.New))
+	if err != nil {
+		return "", err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return "", err
+	}
+	ciphertext := gcm.Seal(nil, iv, []byte(html), nil)
+
+	b64 := base64.StdEncoding.EncodeToString
+	return template.HTML(fmt.Sprintf(encryptedPost, b64(salt), b64(iv),
+		pbkdf2Iterations, b64(ciphertext))), nil
+}
@@ -0,0 +1,54 @@
+package lib
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha256"
+	"encoding/base64"
+	"html/template"
+	"regexp"
+	"testing"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+func TestEncrypt(t *testing.T) {
+	encrypted, err := encrypt(template.HTML("<p>Secret</p>"), "rome")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	match := regexp.MustCompile(`data-salt="([^"]+)" data-iv="([^"]+)" data-iterations="\d+" data-ciphertext="([^"]+)"`).
+		FindStringSubmatch(string(encrypted))
+	if match == nil {
+		t.Fatalf("want encrypted data, got %q", encrypted)
+	}
+
+	bytes := func(s string) []byte {
+		b, _ := base64.StdEncoding.DecodeString(s)
+		return b
+	}
+	block, _ := aes.NewCipher(pbkdf2.Key([]byte("rome"), bytes(match[1]), pbkdf2Iterations, 32, sha256.New))
+	gcm, _ := cipher.NewGCM(block)
+
+	content, err := gcm.Open(nil, bytes(match[2]), bytes(match[3]), nil)
+	if err != nil || string(content) != "<p>Secret</p>" {
+		t.Errorf("want %q, got %q (%v)", "<p>Secret</p>", content, err)
+	}
+}
+
+func TestProtectedSummary(t *testing.T) {
+	g := StaticBlogGenerator{}
+	for _, post := range []Post{
+		{Content: "Secret", Password: "rome"},
+		{Content: "Secret", Summary: "Secret summary", Password: "rome"},
+	} {
+		summary, err := g.summary(post)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if summary != protectedSummary {
+			t.Errorf("want %v, got %v", protectedSummary, summary)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package lib
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"strings"
+)
+
+// EXIF tags read from photos.
+const (
+	exifMake             = 0x010f
+	exifModel            = 0x0110
+	exifIFDPointer       = 0x8769
+	exifGPSPointer       = 0x8825
+	exifExposureTime     = 0x829a
+	exifFNumber          = 0x829d
+	exifISO              = 0x8827
+	exifDateTimeOriginal = 0x9003
+	exifFocalLength      = 0x920a
+	exifLensModel        = 0xa434
+
+	gpsLatitudeRef  = 1
+	gpsLatitude     = 2
+	gpsLongitudeRef = 3
+	gpsLongitude    = 4
+)
+
+// exifTypeSizes are the sizes of the values of the EXIF types.
+var exifTypeSizes = map[uint16]int{1: 1, 2: 1, 3: 2, 4: 4, 5: 8, 7: 1, 9: 4, 10: 8}
+
+// exifEntry is an entry of an image file directory (IFD).
+type exifEntry struct {
+	typ   uint16
+	count uint32
+	value []byte
+}
+
+// exifReader reads the IFDs of the TIFF structure of EXIF data.
+type exifReader struct {
+	tiff  []byte
+	order binary.ByteOrder
+}
+
+// jpegEXIF returns the TIFF structure of the EXIF data (the APP1 segment) of
+// the JPEG image.
+func jpegEXIF(jpeg []byte) ([]byte, error) {
+	if len(jpeg) < 4 || jpeg[0] != 0xff || jpeg[1] != 0xd8 {
+		return nil, errors.New("not a JPEG image")
+	}
+
+	for i := 2; i+4 <= len(jpeg); {
+		if jpeg[i] != 0xff {
+			return nil, errors.New("invalid JPEG marker")
+		}
+		marker := jpeg[i+1]
+		if marker == 0xda || marker == 0xd9 {
+			break
+		}
+		length := int(binary.BigEndian.Uint16(jpeg[i+2:]))
+		if length < 2 || i+2+length > len(jpeg) {
+			return nil, errors.New("invalid JPEG segment")
+		}
+
+		segment := jpeg[i+4 : i+2+length]
+		if marker == 0xe1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return segment[6:], nil
+		}
+		i += 2 + length
+	}
+	return nil, nil
+}
+
+func newEXIFReader(tiff []byte) (exifReader, error) {
+	if len(tiff) < 8 {
+		return exifReader{}, errors.New("invalid EXIF data")
+	}
+
+	r := exifReader{tiff: tiff}
+	switch string(tiff[:2]) {
+	case "II":
+		r.order = binary.LittleEndian
+	case "MM":
+		r.order = binary.BigEndian
+	default:
+		return exifReader{}, errors.New("invalid EXIF byte order")
+	}
+	if r.order.Uint16(tiff[2:]) != 42 {
+		return exifReader{}, errors.New("invalid EXIF data")
+	}
+	return r, nil
+}
+
+// ifd returns the entries of the IFD at the offset keyed by their tags.
+// Entries with unknown types or out of bounds values are left out.
+func (r exifReader) ifd(offset uint32) map[uint16]exifEntry {
+	entries := map[uint16]exifEntry{}
+	if int(offset)+2 > len(r.tiff) {
+		return entries
+	}
+
+	n := int(r.order.Uint16(r.tiff[offset:]))
+	for i := 0; i < n; i++ {
+		start := int(offset) + 2 + i*12
+		if start+12 > len(r.tiff) {
+			break
+		}
+		raw := r.tiff[start : start+12]
+
+		typ, count := r.order.Uint16(raw[2:]), r.order.Uint32(raw[4:])
+		size, ok := exifTypeSizes[typ]
+		if !ok || count > 1<<16 {
+			continue
+		}
+
+		value := raw[8:12]
+		if length := size * int(count); length > 4 {
+			valueOffset := int(r.order.Uint32(raw[8:]))
+			if valueOffset+length > len(r.tiff) {
+				continue
+			}
+			value = r.tiff[valueOffset : valueOffset+length]
+		}
+		entries[r.order.Uint16(raw)] = exifEntry{typ, count, value}
+	}
+	return entries
+}
+
+func (r exifReader) string(e exifEntry) string {
+	if e.typ != 2 {
+		return ""
+	}
+	return strings.TrimSpace(strings.TrimRight(string(e.value[:min(len(e.value), int(e.count))]), "\x00"))
+}
+
+// uint returns the i-th value of the SHORT or LONG entry.
+func (r exifReader) uint(e exifEntry, i int) (uint32, bool) {
+	switch {
+	case e.typ == 3 && (i+1)*2 <= len(e.value):
+		return uint32(r.order.Uint16(e.value[i*2:])), true
+	case e.typ == 4 && (i+1)*4 <= len(e.value):
+		return r.order.Uint32(e.value[i*4:]), true
+	}
+	return 0, false
+}
+
+// rational returns the numerator and the denominator of the i-th value of the
+// RATIONAL entry.
+func (r exifReader) rational(e exifEntry, i int) (uint32, uint32, bool) {
+	if e.typ != 5 || (i+1)*8 > len(e.value) {
+		return 0, 0, false
+	}
+	num, den := r.order.Uint32(e.value[i*8:]), r.order.Uint32(e.value[i*8+4:])
+	return num, den, den != 0
+}
+
+// coordinate returns the GPS coordinate of the entry (degrees, minutes and
+// seconds) in degrees, negative if the ref is S or W.
+func (r exifReader) coordinate(e exifEntry, ref string) (float64, bool) {
+	var degrees float64
+	for i, unit := range []float64{1, 60, 3600} {
+		num, den, ok := r.rational(e, i)
+		if !ok {
+			return 0, false
+		}
+		degrees += float64(num) / float64(den) / unit
+	}
+	if ref == "S" || ref == "W" {
+		degrees = -degrees
+	}
+	return degrees, true
+}
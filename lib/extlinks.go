@@ -0,0 +1,261 @@
+package lib
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LinkCheckFile is the name of the file in the Blog directory that caches
+// results of the external link checker.
+const LinkCheckFile = ".linkcheck.json"
+
+const (
+	defaultLinkCheckConcurrency = 4
+	defaultLinkCheckTimeout     = 10
+	defaultLinkCheckCacheHours  = 24
+)
+
+// LinkCheckConfig holds settings of the external link checker.
+type LinkCheckConfig struct {
+	// Concurrency is the maximum number of simultaneous requests (4 if it's
+	// 0).
+	Concurrency int `yaml:"concurrency"`
+
+	// Timeout is the number of seconds to wait for a response (10 if it's 0).
+	Timeout int `yaml:"timeout"`
+
+	// CacheHours is the number of hours results are cached in the
+	// LinkCheckFile (24 if it's 0). Results aren't cached if it's negative.
+	CacheHours int `yaml:"cacheHours"`
+
+	// Hosts are the only hosts (including their subdomains) links to which
+	// are checked. All links are checked if it's empty.
+	Hosts []string `yaml:"hosts"`
+
+	// Ignore holds URL prefixes of links that aren't checked.
+	Ignore []string `yaml:"ignore"`
+}
+
+// ExternalLink is a link to another site found in Posts together with the
+// result of checking it.
+type ExternalLink struct {
+	URL   string    `json:"-"`
+	Posts []Post    `json:"-"`
+	Code  int       `json:"code"`
+	Error string    `json:"error,omitempty"`
+	Time  time.Time `json:"time"`
+}
+
+// Dead tells whether the checked link's site didn't respond or responded
+// with an error status code.
+func (l ExternalLink) Dead() bool {
+	return l.Error != "" || l.Code >= http.StatusBadRequest
+}
+
+// ExternalLinks returns the http and https links (href and src attributes)
+// in the rendered Posts sorted by URL. If includeDrafts == true links in
+// draft Posts are also included.
+func (b Blog) ExternalLinks(includeDrafts bool) []ExternalLink {
+	byURL := map[string]*ExternalLink{}
+	var urls []string
+	for _, post := range b {
+		if post.Draft && !includeDrafts {
+			continue
+		}
+
+		seen := map[string]bool{}
+		for _, match := range linkRegexp.FindAllStringSubmatch(string(renderPost(post)), -1) {
+			u, err := url.Parse(match[1])
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") || seen[match[1]] {
+				continue
+			}
+			seen[match[1]] = true
+
+			link, ok := byURL[match[1]]
+			if !ok {
+				link = &ExternalLink{URL: match[1]}
+				byURL[match[1]] = link
+				urls = append(urls, match[1])
+			}
+			link.Posts = append(link.Posts, post)
+		}
+	}
+
+	sort.Strings(urls)
+	links := make([]ExternalLink, len(urls))
+	for i, u := range urls {
+		links[i] = *byURL[u]
+	}
+	return links
+}
+
+// LinkChecker checks whether external links are alive.
+type LinkChecker struct {
+	config    LinkCheckConfig
+	cacheFile string
+	client    *http.Client
+}
+
+// NewLinkChecker creates a LinkChecker that caches results in the cacheFile.
+func NewLinkChecker(cacheFile string, config LinkCheckConfig) LinkChecker {
+	if config.Concurrency <= 0 {
+		config.Concurrency = defaultLinkCheckConcurrency
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = defaultLinkCheckTimeout
+	}
+	if config.CacheHours == 0 {
+		config.CacheHours = defaultLinkCheckCacheHours
+	}
+
+	return LinkChecker{config: config, cacheFile: cacheFile,
+		client: &http.Client{Timeout: time.Duration(config.Timeout) * time.Second}}
+}
+
+// Check checks the links and returns them with their results. Links that
+// are ignored (see LinkCheckConfig) aren't returned.
+func (c LinkChecker) Check(links []ExternalLink) ([]ExternalLink, error) {
+	cache, err := c.readCache()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cache: %s", err)
+	}
+
+	var checked []ExternalLink
+	for _, link := range links {
+		if c.ignored(link.URL) {
+			continue
+		}
+		checked = append(checked, link)
+	}
+
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, c.config.Concurrency)
+	for i := range checked {
+		if cached, ok := cache[checked[i].URL]; ok && c.fresh(cached) {
+			checked[i].Code, checked[i].Error, checked[i].Time =
+				cached.Code, cached.Error, cached.Time
+			continue
+		}
+
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(link *ExternalLink) {
+			defer func() { <-sem; wg.Done() }()
+
+			link.Code, link.Error = c.check(link.URL)
+			link.Time = time.Now()
+
+			mutex.Lock()
+			cache[link.URL] = *link
+			mutex.Unlock()
+		}(&checked[i])
+	}
+	wg.Wait()
+
+	err = c.writeCache(cache)
+	if err != nil {
+		return nil, fmt.Errorf("failed to write cache: %s", err)
+	}
+
+	return checked, nil
+}
+
+// check requests the URL with HEAD and falls back to GET for sites that
+// don't support HEAD.
+func (c LinkChecker) check(url string) (int, string) {
+	code, err := c.request(http.MethodHead, url)
+	if err != nil || code >= http.StatusBadRequest {
+		code, err = c.request(http.MethodGet, url)
+	}
+	if err != nil {
+		return 0, err.Error()
+	}
+	return code, ""
+}
+
+func (c LinkChecker) request(method, url string) (int, error) {
+	req, err := http.NewRequest(method, url, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("User-Agent", "LitePub link checker")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+
+	return resp.StatusCode, nil
+}
+
+// ignored tells whether the URL isn't checked according to the
+// LinkCheckConfig.
+func (c LinkChecker) ignored(link string) bool {
+	for _, prefix := range c.config.Ignore {
+		if strings.HasPrefix(link, prefix) {
+			return true
+		}
+	}
+
+	if len(c.config.Hosts) == 0 {
+		return false
+	}
+
+	u, err := url.Parse(link)
+	if err != nil {
+		return true
+	}
+	for _, host := range c.config.Hosts {
+		if u.Hostname() == host || strings.HasSuffix(u.Hostname(), "."+host) {
+			return false
+		}
+	}
+	return true
+}
+
+// fresh tells whether the cached result can be used. Dead links are always
+// checked again.
+func (c LinkChecker) fresh(link ExternalLink) bool {
+	return c.config.CacheHours > 0 && !link.Dead() &&
+		time.Since(link.Time) < time.Duration(c.config.CacheHours)*time.Hour
+}
+
+func (c LinkChecker) readCache() (map[string]ExternalLink, error) {
+	cache := map[string]ExternalLink{}
+	if c.cacheFile == "" || c.config.CacheHours < 0 {
+		return cache, nil
+	}
+
+	bytes, err := os.ReadFile(c.cacheFile)
+	if errors.Is(err, fs.ErrNotExist) {
+		return cache, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return cache, json.Unmarshal(bytes, &cache)
+}
+
+func (c LinkChecker) writeCache(cache map[string]ExternalLink) error {
+	if c.cacheFile == "" || c.config.CacheHours < 0 {
+		return nil
+	}
+
+	bytes, err := json.MarshalIndent(cache, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(c.cacheFile, bytes, 0644)
+}
@@ -0,0 +1,42 @@
+package lib
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestLinkCheckerCheck(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/head-only" && r.Method == http.MethodHead:
+		case r.URL.Path == "/get-only" && r.Method == http.MethodGet:
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	blog := Blog{
+		{Title: "Rome", Content: "[a](" + server.URL + "/head-only) [b](" + server.URL + "/get-only)"},
+		{Title: "Paris", Content: "[c](" + server.URL + "/missing) [d](" + server.URL + "/ignored) [e](/rome.html)"},
+	}
+
+	checker := NewLinkChecker("", LinkCheckConfig{Ignore: []string{server.URL + "/ignored"}})
+	links, err := checker.Check(blog.ExternalLinks(false))
+	if err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+
+	if len(links) != 3 {
+		t.Fatalf("want %v, got %v", 3, len(links))
+	}
+
+	dead := map[string]bool{"/get-only": false, "/head-only": false, "/missing": true}
+	for _, link := range links {
+		path := link.URL[len(server.URL):]
+		if link.Dead() != dead[path] {
+			t.Errorf("%s: want %v, got %v", path, dead[path], link.Dead())
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package lib
+
+import (
+	"net/url"
+	"strings"
+)
+
+// ExternalLinksConfig holds settings of rewriting links to other sites in
+// rendered Posts.
+type ExternalLinksConfig struct {
+	// NoReferrer adds rel="noopener noreferrer".
+	NoReferrer bool `yaml:"noreferrer"`
+
+	// NoFollow adds rel="nofollow".
+	NoFollow bool `yaml:"nofollow"`
+
+	// NewTab adds target="_blank" (and rel="noopener").
+	NewTab bool `yaml:"newTab"`
+
+	// Exclude holds hosts (including their subdomains) links to which aren't
+	// rewritten.
+	Exclude []string `yaml:"exclude"`
+}
+
+// rewriteExternalLinks adds the rel and target attributes to links to other
+// sites according to the ExternalLinksConfig.
+func (g StaticBlogGenerator) rewriteExternalLinks(html string) string {
+	config := g.options.config.ExternalLinks
+
+	var rels []string
+	if config.NoReferrer || config.NewTab {
+		rels = append(rels, "noopener")
+	}
+	if config.NoReferrer {
+		rels = append(rels, "noreferrer")
+	}
+	if config.NoFollow {
+		rels = append(rels, "nofollow")
+	}
+	if len(rels) == 0 {
+		return html
+	}
+
+	return rewriteStartTags(html, "a", func(attrs *attributes) {
+		href, _ := attrs.get("href")
+		if !g.externalURL(href, config.Exclude) {
+			return
+		}
+
+		rel, _ := attrs.get("rel")
+		values := strings.Fields(rel)
+		for _, r := range rels {
+			if !containsFold(values, r) {
+				values = append(values, r)
+			}
+		}
+		attrs.set("rel", strings.Join(values, " "))
+
+		if config.NewTab {
+			attrs.set("target", "_blank")
+		}
+	})
+}
+
+// externalURL tells whether the URL points to another site than the Blog's
+// one and its host isn't one of the excluded hosts (or their subdomains).
+func (g StaticBlogGenerator) externalURL(link string, exclude []string) bool {
+	u, err := url.Parse(link)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return false
+	}
+
+	if site, err := url.Parse(g.options.config.URL); err == nil && site.Host != "" {
+		exclude = append([]string{site.Hostname()}, exclude...)
+	}
+	for _, host := range exclude {
+		if strings.EqualFold(u.Hostname(), host) ||
+			strings.HasSuffix(strings.ToLower(u.Hostname()), "."+strings.ToLower(host)) {
+			return false
+		}
+	}
+	return true
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,381 @@
+package lib
+
+import (
+	"encoding/xml"
+	"fmt"
+	"html/template"
+	"io"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Contents of feed entries (see FeedConfig).
+const (
+	FeedContentFull    = "full"
+	FeedContentSummary = "summary"
+)
+
+// FeedsConfig holds settings of the Atom feeds.
+type FeedsConfig struct {
+	// Site holds settings of the feed of all Posts and of the yearly feeds
+	// (whose Items aren't limited).
+	Site FeedConfig `yaml:"site"`
+
+	// Tags and Authors hold settings of the feeds of tags and authors (see
+	// TaxonomyConfig.Feeds).
+	Tags    FeedConfig `yaml:"tags"`
+	Authors FeedConfig `yaml:"authors"`
+
+	// Sections maps directories of the posts directory (see Post.Dir) to
+	// settings of feeds of their Posts (including the ones in their
+	// subdirectories). The feeds are generated to atom.xml in the same
+	// directories of the output directory.
+	Sections map[string]FeedConfig `yaml:"sections"`
+}
+
+// FeedConfig holds settings of an Atom feed.
+type FeedConfig struct {
+	// Items is the maximum number of the latest Posts in the feed. All Posts
+	// are in it if it's 0.
+	Items int `yaml:"items"`
+
+	// Content tells what entries contain besides the summary: the rendered
+	// Post (FeedContentFull, the default) or nothing (FeedContentSummary).
+	Content string `yaml:"content"`
+
+	// SummaryLength is the maximum number of words of summaries of entries
+	// computed from the first paragraph of a Post. Config.SummaryLength is
+	// used if it's 0.
+	SummaryLength int `yaml:"summaryLength"`
+
+	// Drafts and Future tell whether draft Posts (which are in preview builds
+	// only) and Posts written in the future (unless Config.ScheduledPosts
+	// leaves them out) can be in the feed. They can if these aren't set.
+	Drafts *bool `yaml:"drafts"`
+	Future *bool `yaml:"future"`
+
+	// Unlisted tells whether unlisted Posts are in the feed.
+	Unlisted bool `yaml:"unlisted"`
+}
+
+func (c FeedsConfig) validate() error {
+	configs := []FeedConfig{c.Site, c.Tags, c.Authors}
+	for dir, config := range c.Sections {
+		if !validSectionDir(dir) {
+			return fmt.Errorf("invalid feed section: %q", dir)
+		}
+		configs = append(configs, config)
+	}
+
+	for _, config := range configs {
+		if config.Content != "" && config.Content != FeedContentFull &&
+			config.Content != FeedContentSummary {
+			return fmt.Errorf("unsupported feed content: %s", config.Content)
+		}
+		if config.Items < 0 {
+			return fmt.Errorf("invalid number of feed items: %d", config.Items)
+		}
+	}
+	return nil
+}
+
+// validSectionDir reports whether the directory of a section feed is a
+// directory of the output directory (so its feed can't overwrite atom.xml or
+// files outside of the output directory).
+func validSectionDir(dir string) bool {
+	dir = strings.Trim(dir, "/")
+	if dir == "" {
+		return false
+	}
+	for _, name := range strings.Split(strings.ReplaceAll(dir, `\`, "/"), "/") {
+		if name == "" || name == "." || name == ".." {
+			return false
+		}
+	}
+	return true
+}
+
+// summaryLength returns the maximum number of words of summaries of entries.
+func (c FeedConfig) summaryLength(config Config) int {
+	if c.SummaryLength > 0 {
+		return c.SummaryLength
+	}
+	return config.SummaryLength
+}
+
+// feedPosts returns the latest Posts (and, if the FeedConfig's Unlisted is
+// set, unlisted Posts) for which in returns true that can be in a feed with
+// the FeedConfig. The number of Posts is limited to its Items if limit ==
+// true.
+func (g StaticBlogGenerator) feedPosts(config FeedConfig, limit bool, in func(Post) bool) []Post {
+	candidates := g.posts
+	if config.Unlisted && len(g.unlisted) > 0 {
+		candidates = append(append([]Post{}, g.posts...), g.unlisted...)
+		sort.SliceStable(candidates, func(i, j int) bool {
+			return candidates[i].Written.After(candidates[j].Written)
+		})
+	}
+
+	var posts []Post
+	for _, post := range candidates {
+		if in != nil && !in(post) {
+			continue
+		}
+		if post.Draft && config.Drafts != nil && !*config.Drafts {
+			continue
+		}
+		if post.Written.After(g.options.build.Time) && config.Future != nil && !*config.Future {
+			continue
+		}
+		posts = append(posts, post)
+		if limit && len(posts) == config.Items {
+			break
+		}
+	}
+	return posts
+}
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"feed"`
+	Xmlns   string      `xml:"xmlns,attr"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Links   []atomLink  `xml:"link"`
+	Author  *atomAuthor `xml:"author,omitempty"`
+	Entries atomEntries `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+	URI  string `xml:"uri,omitempty"`
+}
+
+type atomEntry struct {
+	ID        string       `xml:"id"`
+	Title     string       `xml:"title"`
+	Updated   string       `xml:"updated"`
+	Published string       `xml:"published"`
+	Authors   []atomAuthor `xml:"author"`
+	Links     []atomLink   `xml:"link"`
+	Summary   string       `xml:"summary,omitempty"`
+	Content   *atomContent `xml:"content,omitempty"`
+}
+
+type atomContent struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+// atomEntries renders the entries of the Posts while they're encoded, so only
+// one rendered Post at a time is held in memory.
+type atomEntries struct {
+	g      StaticBlogGenerator
+	posts  []Post
+	config FeedConfig
+}
+
+func (e atomEntries) MarshalXML(enc *xml.Encoder, start xml.StartElement) error {
+	for _, post := range e.posts {
+		entry, err := e.g.atomEntry(post, e.config)
+		if err != nil {
+			return err
+		}
+
+		err = enc.EncodeElement(entry, start)
+		if err != nil {
+			return err
+		}
+
+		err = enc.Flush()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (g StaticBlogGenerator) generateFeed() error {
+	config := g.options.config.Feeds.Site
+	return g.generateAtomFeed("atom.xml", g.absURL(""), g.options.config.Title,
+		g.feedPosts(config, true, nil), config)
+}
+
+// yearlyFeedPath returns the path of the feed of the Posts written in the
+// year (an int or a string like the Key of a PostGroup) in the output
+// directory.
+func yearlyFeedPath(year interface{}) string {
+	return fmt.Sprintf("atom-%v.xml", year)
+}
+
+// yearlyFeedURL returns the URL of the feed of the Posts written in the year
+// relative to the Blog's root.
+func yearlyFeedURL(year interface{}) string {
+	return "/" + yearlyFeedPath(year)
+}
+
+// generateYearlyFeeds generates a feed of the Posts written in each year (see
+// Config.YearlyFeeds).
+func (g StaticBlogGenerator) generateYearlyFeeds() error {
+	config := g.options.config.Feeds.Site
+	byYear := map[int][]Post{}
+	for _, post := range g.feedPosts(config, false, nil) {
+		byYear[post.Written.Year()] = append(byYear[post.Written.Year()], post)
+	}
+
+	for year, posts := range byYear {
+		path := yearlyFeedPath(year)
+		title := fmt.Sprintf("%s (%d)", g.options.config.Title, year)
+		err := g.generateAtomFeed(path, g.absURL(path), title, posts, config)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// tagFeedPath returns the path of the feed of the Posts tagged with the tag in
+// the output directory.
+func (g StaticBlogGenerator) tagFeedPath(tag string) string {
+	return filepath.Join("tags", g.slug(tag)+".xml")
+}
+
+// tagFeedURL returns the URL of the feed of the Posts tagged with the tag
+// relative to the Blog's root.
+func (g StaticBlogGenerator) tagFeedURL(tag string) string {
+	return "/" + filepath.ToSlash(g.tagFeedPath(tag))
+}
+
+// generateTagFeeds generates a feed of the Posts tagged with each tag (see
+// Config.TagFeeds and TaxonomyConfig.Feeds).
+func (g StaticBlogGenerator) generateTagFeeds() error {
+	for _, tag := range g.tags {
+		if !g.options.config.tagTaxonomy().feed(tag.Name) {
+			continue
+		}
+
+		name := tag.Name
+		config := g.options.config.Feeds.Tags
+		posts := g.feedPosts(config, true, func(post Post) bool {
+			return slices.Contains(post.Tags, name)
+		})
+
+		path := g.tagFeedPath(tag.Name)
+		title := fmt.Sprintf("%s: %s", g.options.config.Title, tag.Title)
+		err := g.generateAtomFeed(path, g.absURL(path), title, posts, config)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sectionFeedPath returns the path of the feed of the Posts in the directory
+// of the posts directory in the output directory. Its parts are slugged like
+// the directories of Posts' pages (see Config.NestedURLs).
+func (g StaticBlogGenerator) sectionFeedPath(dir string) string {
+	return filepath.Join(filepath.FromSlash(g.slugDir(strings.Trim(dir, "/"))), "atom.xml")
+}
+
+// sectionFeedURL returns the URL of the feed of the Posts in the directory of
+// the posts directory relative to the Blog's root.
+func (g StaticBlogGenerator) sectionFeedURL(dir string) string {
+	return "/" + filepath.ToSlash(g.sectionFeedPath(dir))
+}
+
+// generateSectionFeeds generates a feed of the Posts in each directory of the
+// posts directory with a FeedConfig in FeedsConfig.Sections.
+func (g StaticBlogGenerator) generateSectionFeeds() error {
+	for dir, config := range g.options.config.Feeds.Sections {
+		dir = strings.Trim(dir, "/")
+		posts := g.feedPosts(config, true, func(post Post) bool {
+			return post.Dir == dir || strings.HasPrefix(post.Dir, dir+"/")
+		})
+
+		path := g.sectionFeedPath(dir)
+		title := fmt.Sprintf("%s: %s", g.options.config.Title, dir)
+		err := g.generateAtomFeed(path, g.absURL(path), title, posts, config)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// generateAtomFeed generates the Atom feed of the Posts to the path with the
+// FeedConfig. The id identifies the feed.
+func (g StaticBlogGenerator) generateAtomFeed(path, id, title string, posts []Post,
+	config FeedConfig) error {
+	feed := atomFeed{
+		Xmlns: "http://www.w3.org/2005/Atom",
+		ID:    id,
+		Title: title,
+		Links: []atomLink{
+			{Href: g.absURL("")},
+			{Href: g.absURL(path), Rel: "self"},
+		},
+	}
+
+	if g.options.config.Author != "" {
+		feed.Author = &atomAuthor{Name: g.options.config.Author}
+	}
+
+	feed.Entries = atomEntries{g, posts, config}
+	feed.Updated = g.dates.rfc3339(lastModified(posts))
+
+	return g.generateFile(path, func(w io.Writer) error {
+		return writeXML(w, feed)
+	})
+}
+
+func (g StaticBlogGenerator) atomEntry(post Post, config FeedConfig) (atomEntry, error) {
+	description, err := g.descriptionOfLength(post, config.summaryLength(g.options.config))
+	if err != nil {
+		return atomEntry{}, err
+	}
+
+	var content *atomContent
+	if config.Content != FeedContentSummary {
+		body := template.HTML(protectedSummary)
+		if post.Password == "" {
+			body, err = g.render(post)
+			if err != nil {
+				return atomEntry{}, err
+			}
+			body = template.HTML(rewriteURLs(string(body), true, g.options.config.withBasePath))
+		}
+		content = &atomContent{"html", string(body)}
+	}
+
+	var authors []atomAuthor
+	for i, author := range post.Authors {
+		entryAuthor := atomAuthor{Name: author}
+		if i < len(post.Bylines) {
+			entryAuthor.URI = post.Bylines[i].URL()
+		}
+		authors = append(authors, entryAuthor)
+	}
+
+	url := g.absPageURL(g.postPath(post))
+	links := []atomLink{{Href: url}}
+	if post.Link != "" {
+		links = []atomLink{{Href: post.Link}, {Href: url, Rel: "related"}}
+	}
+	return atomEntry{
+		ID:        url,
+		Title:     post.Title,
+		Updated:   g.dates.rfc3339(post.LastModified()),
+		Published: g.dates.rfc3339(post.Written),
+		Authors:   authors,
+		Links:     links,
+		Summary:   description,
+		Content:   content,
+	}, nil
+}
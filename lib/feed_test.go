@@ -0,0 +1,154 @@
+package lib
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGenerateFeedsConfig(t *testing.T) {
+	templates := writeTestTemplates(t)
+	now := time.Now()
+	blog := Blog{
+		{Title: "Rome", Tags: []string{"cities"}, Content: "Rome is the capital of Italy.",
+			Written: now.Add(-time.Hour)},
+		{Title: "Paris", Tags: []string{"cities"}, Content: "Paris is the capital of France.",
+			Written: now.Add(-2 * time.Hour)},
+		{Title: "Oslo", Dir: "notes/travel", Content: "Oslo is the capital of Norway.",
+			Written: now.Add(-3 * time.Hour)},
+		{Title: "Bern", Dir: "notes", Content: "Bern is the capital of Switzerland.",
+			Written: now.Add(-4 * time.Hour), Unlisted: true},
+		{Title: "Vienna", Dir: "notes", Content: "Vienna is the capital of Austria.",
+			Written: now.Add(24 * time.Hour)},
+	}
+
+	output := t.TempDir()
+	future := false
+	g, err := NewStaticBlogGenerator(blog, templates, output, func(string) {},
+		WithConfig(Config{URL: "https://example.com", Title: "Cities", TagFeeds: true,
+			Feeds: FeedsConfig{
+				Site: FeedConfig{Items: 2},
+				Tags: FeedConfig{Content: FeedContentSummary, SummaryLength: 3},
+				Sections: map[string]FeedConfig{
+					"notes": {Unlisted: true, Future: &future},
+				},
+			}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Generate(); err != nil {
+		t.Fatal(err)
+	}
+
+	for path, titles := range map[string]map[string]bool{
+		"atom.xml":                         {"Vienna": true, "Rome": true, "Paris": false},
+		filepath.Join("notes", "atom.xml"): {"Oslo": true, "Bern": true, "Vienna": false},
+	} {
+		feed, _ := os.ReadFile(filepath.Join(output, path))
+		for title, want := range titles {
+			if strings.Contains(string(feed), "<title>"+title+"</title>") != want {
+				t.Errorf("%s: want %v for %s, got %s", path, want, title, feed)
+			}
+		}
+	}
+
+	feed, _ := os.ReadFile(filepath.Join(output, "tags", "cities.xml"))
+	if want := "<summary>Rome is the…</summary>"; !strings.Contains(string(feed), want) {
+		t.Errorf("want %s, got %s", want, feed)
+	}
+	if strings.Contains(string(feed), "<content") {
+		t.Errorf("want no content, got %s", feed)
+	}
+}
+
+func TestGenerateFeedsConfigInvalidContent(t *testing.T) {
+	_, err := NewStaticBlogGenerator(Blog{}, writeTestTemplates(t), t.TempDir(), func(string) {},
+		WithConfig(Config{Feeds: FeedsConfig{Tags: FeedConfig{Content: "excerpt"}}}))
+	if err == nil {
+		t.Errorf("want error, got nil")
+	}
+}
+
+func TestGenerateFeedsConfigInvalidSections(t *testing.T) {
+	for _, dir := range []string{"", "/", "../x", "notes/../..", "notes//travel", `..\x`} {
+		_, err := NewStaticBlogGenerator(Blog{}, writeTestTemplates(t), t.TempDir(), func(string) {},
+			WithConfig(Config{Feeds: FeedsConfig{Sections: map[string]FeedConfig{dir: {}}}}))
+		if want := fmt.Sprintf("invalid feed section: %q", dir); err == nil || err.Error() != want {
+			t.Errorf("want %v, got %v", want, err)
+		}
+	}
+
+	_, err := NewStaticBlogGenerator(Blog{}, writeTestTemplates(t), t.TempDir(), func(string) {},
+		WithConfig(Config{Feeds: FeedsConfig{Sections: map[string]FeedConfig{"/notes/travel/": {}}}}))
+	if err != nil {
+		t.Errorf("want %v, got %v", nil, err)
+	}
+}
+
+func TestGenerateSectionFeedsSlugged(t *testing.T) {
+	blog := Blog{{Title: "Rome", Dir: "My Notes/Trips", Content: "Rome", Written: time.Now().Add(-time.Hour)}}
+
+	output := t.TempDir()
+	g, err := NewStaticBlogGenerator(blog, writeTestTemplates(t), output, func(string) {},
+		WithConfig(Config{URL: "https://example.com", NestedURLs: true,
+			Feeds: FeedsConfig{Sections: map[string]FeedConfig{"My Notes": {}}}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Generate(); err != nil {
+		t.Fatal(err)
+	}
+
+	if want := "/my-notes/atom.xml"; g.sectionFeedURL("My Notes") != want {
+		t.Errorf("want %v, got %v", want, g.sectionFeedURL("My Notes"))
+	}
+	feed, err := os.ReadFile(filepath.Join(output, "my-notes", "atom.xml"))
+	if err != nil || !strings.Contains(string(feed), "https://example.com/my-notes/trips/rome.html") {
+		t.Errorf("want %v, got %s (%v)", "the feed next to the section's pages", feed, err)
+	}
+}
+
+// renderCountWriter records how many Posts were rendered when each feed entry
+// was written.
+type renderCountWriter struct {
+	rendered *int
+	counts   []int
+}
+
+func (w *renderCountWriter) Write(p []byte) (int, error) {
+	if strings.Contains(string(p), "<entry>") {
+		w.counts = append(w.counts, *w.rendered)
+	}
+	return len(p), nil
+}
+
+func TestAtomEntriesRenderedOneAtATime(t *testing.T) {
+	now := time.Now()
+	blog := Blog{
+		{Title: "Rome", Content: "Rome", Written: now.Add(-time.Hour)},
+		{Title: "Paris", Content: "Paris", Written: now.Add(-2 * time.Hour)},
+		{Title: "Oslo", Content: "Oslo", Written: now.Add(-3 * time.Hour)},
+	}
+
+	rendered := 0
+	g, err := NewStaticBlogGenerator(blog, writeTestTemplates(t), t.TempDir(), func(string) {},
+		WithTransformer(StageMarkdown, TransformerFunc(func(post Post) (string, error) {
+			rendered++
+			return post.Content, nil
+		})))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := &renderCountWriter{rendered: &rendered}
+	feed := atomFeed{Entries: atomEntries{g, g.posts, FeedConfig{}}}
+	if err := writeXML(w, feed); err != nil {
+		t.Fatal(err)
+	}
+	if want := []int{1, 2, 3}; fmt.Sprint(w.counts) != fmt.Sprint(want) {
+		t.Errorf("want %v, got %v", want, w.counts)
+	}
+}
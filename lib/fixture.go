@@ -0,0 +1,141 @@
+package lib
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gosimple/slug"
+)
+
+// fixtureWords are the words synthesized Posts are made of.
+var fixtureWords = strings.Fields(`the a of and to in is for on with as that it by
+	from at this we be are or an can not you your our all more one new when
+	which their about into than them then some what only also how use other
+	time first way over just like most work well even any these after make
+	static site blog post page template build server feed markdown content
+	code project release version performance cache index archive link image
+	data file directory config option function test design photo travel city
+	book music review note idea story weekend morning garden coffee bicycle
+	library network browser language compiler framework database deploy
+	cloud writing reading learning history journey mountain river ocean`)
+
+// fixtureTags are the tags of synthesized Posts. Tags at the start are used
+// more often.
+var fixtureTags = strings.Fields(`Go Programming Web Travel Photography Books
+	Notes Linux Tutorial Music JavaScript Design Personal Tools Reviews
+	Performance Databases Cooking Hiking Security Testing Open-Source Writing
+	Productivity Cities Rust Python Cloud DevOps History Science Gardening
+	Cycling Films Games Hardware Networking Privacy Mobile Accessibility
+	Typography Art Architecture Education Health Running Coffee Podcasts
+	Minimalism Maps Weather Astronomy Languages Family Events Release-Notes`)
+
+// fixtureEpoch is the date of the first synthesized Post.
+var fixtureEpoch = time.Date(2010, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// Fixture synthesizes a Blog of n Posts with realistic titles, tags and
+// Markdown content (paragraphs, headings, lists, code blocks and links to
+// other Posts) written over the years since 2010. The same seed always
+// synthesizes the same Blog, so it can be used to benchmark generating Blogs
+// reproducibly.
+func Fixture(n int, seed int64) Blog {
+	r := rand.New(rand.NewSource(seed))
+	tags := rand.NewZipf(r, 1.2, 2, uint64(len(fixtureTags)-1))
+
+	blog := make(Blog, 0, n)
+	slugs := map[string]bool{}
+	written := fixtureEpoch
+	for i := 0; i < n; i++ {
+		title := fixtureSentence(r, 3+r.Intn(6))
+		title = strings.TrimSuffix(title, ".")
+		if slugs[slug.Make(title)] {
+			title = fmt.Sprintf("%s %d", title, i)
+		}
+		slugs[slug.Make(title)] = true
+
+		post := Post{Title: title, Written: written, IsPage: r.Intn(50) == 0}
+		for j := 1 + r.Intn(4); j > 0; j-- {
+			tag := fixtureTags[tags.Uint64()]
+			if !containsFold(post.Tags, tag) {
+				post.Tags = append(post.Tags, tag)
+			}
+		}
+		post.Content = fixtureContent(r, blog)
+
+		blog = append(blog, post)
+		written = written.Add(time.Duration(6+r.Intn(72)) * time.Hour)
+	}
+	return blog
+}
+
+// WriteFixture writes a Blog synthesized by Fixture to the posts directory in
+// the directory as Markdown files.
+func WriteFixture(dir string, n int, seed int64) error {
+	posts := filepath.Join(dir, postsDir)
+	err := os.MkdirAll(filepath.Join(posts, draftDir), 0700)
+	if err != nil {
+		return fmt.Errorf("failed to create posts directory: %s", err)
+	}
+
+	for _, post := range Fixture(n, seed) {
+		source, err := postToMarkdown(nil, post)
+		if err != nil {
+			return fmt.Errorf("failed to write post %s: %s", post.Title, err)
+		}
+
+		err = os.WriteFile(filepath.Join(posts, slug.Make(post.Title)+".md"),
+			[]byte(source), 0600)
+		if err != nil {
+			return fmt.Errorf("failed to write post %s: %s", post.Title, err)
+		}
+	}
+	return nil
+}
+
+// fixtureContent synthesizes Markdown content linking to the earlier Posts.
+func fixtureContent(r *rand.Rand, earlier Blog) string {
+	var paras []string
+	for i := 2 + r.Intn(10); i > 0; i-- {
+		switch n := r.Intn(10); {
+		case n == 0:
+			paras = append(paras, "## "+strings.TrimSuffix(fixtureSentence(r, 2+r.Intn(4)), "."))
+		case n == 1:
+			var items []string
+			for j := 2 + r.Intn(4); j > 0; j-- {
+				items = append(items, "- "+fixtureSentence(r, 3+r.Intn(8)))
+			}
+			paras = append(paras, strings.Join(items, "\n"))
+		case n == 2:
+			paras = append(paras, "```go\nfunc main() {\n\tfmt.Println(\""+
+				fixtureSentence(r, 3)+"\")\n}\n```")
+		default:
+			var sentences []string
+			for j := 2 + r.Intn(6); j > 0; j-- {
+				sentences = append(sentences, fixtureSentence(r, 5+r.Intn(15)))
+			}
+			if len(earlier) > 0 && r.Intn(3) == 0 {
+				linked := earlier[r.Intn(len(earlier))]
+				sentences = append(sentences, fmt.Sprintf("See also [%s](/%s.html).",
+					linked.Title, slug.Make(linked.Title)))
+			}
+			if r.Intn(4) == 0 {
+				sentences[0] = "*" + strings.TrimSuffix(sentences[0], ".") + "*."
+			}
+			paras = append(paras, strings.Join(sentences, " "))
+		}
+	}
+	return strings.Join(paras, "\n\n")
+}
+
+// fixtureSentence synthesizes a capitalized sentence of n words.
+func fixtureSentence(r *rand.Rand, n int) string {
+	words := make([]string, n)
+	for i := range words {
+		words[i] = fixtureWords[r.Intn(len(fixtureWords))]
+	}
+	words[0] = strings.ToUpper(words[0][:1]) + words[0][1:]
+	return strings.Join(words, " ") + "."
+}
@@ -0,0 +1,63 @@
+package lib
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestFixture(t *testing.T) {
+	blog := Fixture(50, 1)
+	if len(blog) != 50 {
+		t.Fatalf("want %v, got %v", 50, len(blog))
+	}
+	if !reflect.DeepEqual(blog, Fixture(50, 1)) {
+		t.Errorf("want the same blog for the same seed")
+	}
+	if reflect.DeepEqual(blog, Fixture(50, 2)) {
+		t.Errorf("want another blog for another seed")
+	}
+
+	dir := t.TempDir()
+	if err := WriteFixture(dir, 50, 1); err != nil {
+		t.Fatal(err)
+	}
+	read, err := NewMarkdownBlog(dir).Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(read) != 50 {
+		t.Errorf("want %v, got %v", 50, len(read))
+	}
+	for _, post := range read {
+		if len(post.Tags) == 0 || post.Content == "" {
+			t.Errorf("want %v, got %v", "tags and content", post)
+		}
+	}
+}
+
+func BenchmarkGenerate(b *testing.B) {
+	templates := b.TempDir()
+	for name, content := range map[string]string{
+		"layout.tmpl": `<html><body>{{template "content" .}}</body></html>`,
+		"index.tmpl":  `{{define "content"}}{{range .}}<a href="{{postURL .}}">{{.Title}}</a>{{end}}{{end}}`,
+		"post.tmpl":   `{{define "content"}}<h1>{{.Title}}</h1>{{. | html}}{{end}}`,
+		"tag.tmpl":    `{{define "content"}}{{range .Posts}}{{summary .}}{{end}}{{end}}`,
+	} {
+		os.WriteFile(filepath.Join(templates, name), []byte(content), 0600)
+	}
+
+	blog := Fixture(500, 1)
+	output := b.TempDir()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		g, err := NewStaticBlogGenerator(blog, templates, output, func(string) {})
+		if err != nil {
+			b.Fatal(err)
+		}
+		if err := g.Generate(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package lib
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const frontMatterDelim = "---"
+
+// frontMatter holds the optional metadata of a post. It's stored as YAML
+// between two --- lines at the very start of the post's file:
+//
+//	---
+//	updated: 2021-08-12
+//	---
+//
+//	# Title
+//	...
+type frontMatter struct {
+	Updated     string   `yaml:"updated"`
+	Summary     string   `yaml:"summary"`
+	Description string   `yaml:"description"`
+	Audio       *Audio   `yaml:"audio"`
+	Comments    *bool    `yaml:"comments"`
+	Password    string   `yaml:"password"`
+	Unlisted    bool     `yaml:"unlisted"`
+	Expires     string   `yaml:"expires"`
+	Authors     []string `yaml:"authors"`
+	Output      string   `yaml:"output"`
+	Aliases     []string `yaml:"aliases"`
+	Link        string   `yaml:"link"`
+	Photo       string   `yaml:"photo"`
+	NoIndex     bool     `yaml:"noindex"`
+}
+
+// splitFrontMatter separates the front matter from the rest of the markdown.
+// If there's no front matter it returns the zero frontMatter and the unchanged
+// markdown.
+func splitFrontMatter(markdown string) (frontMatter, string, error) {
+	var fm frontMatter
+	body, err := cutFrontMatter(markdown, &fm)
+	return fm, body, err
+}
+
+// cutFrontMatter decodes the YAML front matter of the source into v and
+// returns the rest of the source. If there's no front matter v is unchanged
+// and it returns the unchanged source.
+func cutFrontMatter(source string, v interface{}) (string, error) {
+	if !strings.HasPrefix(source, frontMatterDelim+"\n") {
+		return source, nil
+	}
+
+	rest := strings.TrimPrefix(source, frontMatterDelim+"\n")
+	end := strings.Index(rest, "\n"+frontMatterDelim+"\n")
+	if end == -1 {
+		return source, fmt.Errorf("front matter isn't closed")
+	}
+
+	err := yaml.Unmarshal([]byte(rest[:end]), v)
+	if err != nil {
+		return source, fmt.Errorf("failed to parse front matter: %s", err)
+	}
+
+	body := rest[end+len(frontMatterDelim)+2:]
+	return strings.TrimLeft(body, "\n"), nil
+}
+
+// apply sets the Post's fields from the front matter. Dates without a time
+// zone are in the loc.
+func (fm frontMatter) apply(post *Post, loc *time.Location) error {
+	if fm.Updated != "" {
+		updated, err := parseDate(fm.Updated, loc)
+		if err != nil {
+			return fmt.Errorf("failed to parse updated date: %s", err)
+		}
+		post.Updated = updated
+	}
+	if fm.Expires != "" {
+		expires, err := parseDate(fm.Expires, loc)
+		if err != nil {
+			return fmt.Errorf("failed to parse expires date: %s", err)
+		}
+		post.Expires = expires
+	}
+	post.Summary = fm.Summary
+	post.Description = fm.Description
+	post.Audio = fm.Audio
+	post.CommentsDisabled = fm.Comments != nil && !*fm.Comments
+	post.Password = fm.Password
+	post.Unlisted = fm.Unlisted
+	post.NoIndex = fm.NoIndex
+	post.Authors = fm.Authors
+	post.Aliases = fm.Aliases
+	if fm.Link != "" {
+		u, err := url.Parse(fm.Link)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid link: %s", fm.Link)
+		}
+		post.Link = fm.Link
+	}
+	if fm.Photo != "" {
+		post.Photo = &Photo{Src: fm.Photo}
+	}
+	if fm.Output != "" {
+		output, err := cleanOutputPath(fm.Output)
+		if err != nil {
+			return err
+		}
+		post.Output = output
+	}
+
+	return nil
+}
+
+// cleanOutputPath returns the output front matter field as a path relative to
+// the output directory. Paths ending with a slash are directories with the
+// index.html file.
+func cleanOutputPath(output string) (string, error) {
+	output = strings.TrimSpace(output)
+	p := path.Clean("/" + output)
+	if strings.HasSuffix(output, "/") {
+		p = path.Join(p, "index.html")
+	}
+
+	if p == "/index.html" || p == "/" {
+		return "", fmt.Errorf("invalid output path: %s", output)
+	}
+	return strings.TrimPrefix(p, "/"), nil
+}
@@ -0,0 +1,224 @@
+package lib
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	stdhtml "html"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// defaultThumbnailSize is the maximum width and height of thumbnails if the
+// Config doesn't set it.
+const defaultThumbnailSize = 300
+
+// thumbnailsDir is the subdirectory of a gallery's directory thumbnails are
+// generated to.
+const thumbnailsDir = "thumbs"
+
+var galleryImageExtensions = map[string]bool{
+	".gif": true, ".jpeg": true, ".jpg": true, ".png": true,
+}
+
+// gallery renders the GIF, JPEG and PNG images in the directory (relative to
+// the Blog's root) as a gallery of thumbnails linking to the images.
+func gallery(g StaticBlogGenerator, args []string) (string, error) {
+	if len(args) != 1 {
+		return "", errors.New("want a directory")
+	}
+	dir := strings.Trim(path.Clean("/"+args[0]), "/")
+
+	entries, err := os.ReadDir(filepath.Join(g.outputDir, filepath.FromSlash(dir)))
+	if err != nil {
+		return "", err
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if !entry.IsDir() && galleryImageExtensions[strings.ToLower(filepath.Ext(entry.Name()))] {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "<div class=\"gallery\">\n")
+	for _, name := range names {
+		imagePath := path.Join(dir, name)
+		thumbnailPath := path.Join(dir, thumbnailsDir, name)
+
+		width, height, err := g.generateThumbnail(imagePath, thumbnailPath)
+		if err != nil {
+			return "", fmt.Errorf("failed to generate thumbnail of %s: %s", imagePath, err)
+		}
+
+		alt := strings.TrimSuffix(name, filepath.Ext(name))
+		fmt.Fprintf(&b, `  <a class="gallery-item" href="/%s" data-lightbox="%s">`+
+			`<img src="/%s" alt="%s" width="%d" height="%d" loading="lazy"></a>`+"\n",
+			stdhtml.EscapeString(imagePath), stdhtml.EscapeString(dir),
+			stdhtml.EscapeString(thumbnailPath), stdhtml.EscapeString(alt),
+			width, height)
+	}
+	fmt.Fprintf(&b, "</div>")
+
+	return b.String(), nil
+}
+
+// generateThumbnail generates the thumbnail of the image (both relative to
+// the output directory) and returns the thumbnail's dimensions. Thumbnails
+// are cached in the cache directory.
+func (g StaticBlogGenerator) generateThumbnail(imagePath, thumbnailPath string) (int, int, error) {
+	size := g.options.config.ThumbnailSize
+	if size <= 0 {
+		size = defaultThumbnailSize
+	}
+	return g.generateScaled(imagePath, thumbnailPath, size)
+}
+
+// generateScaled generates the image (both relative to the output directory)
+// scaled down to fit in a square with the size and returns the scaled image's
+// dimensions. Scaled images are cached in the cache directory (or in the
+// render cache if the Config's RenderCache is set).
+func (g StaticBlogGenerator) generateScaled(imagePath, scaledPath string, size int) (int, int, error) {
+	if size, ok := g.thumbnails[scaledPath]; ok {
+		return size.X, size.Y, nil
+	}
+
+	source := filepath.Join(g.outputDir, filepath.FromSlash(imagePath))
+	info, err := os.Stat(source)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var cached string
+	if cache := newRenderCache(g.options); cache.dir != "" {
+		// the render cache is keyed by the image's content, so it survives
+		// checkouts and copies changing modification times
+		content, err := os.ReadFile(source)
+		if err != nil {
+			return 0, 0, err
+		}
+		cached, err = cache.file(path.Ext(imagePath), func(target string) error {
+			return createThumbnail(source, target, size)
+		}, "scaled", string(content), strconv.Itoa(size))
+		if err != nil {
+			return 0, 0, err
+		}
+	} else {
+		sum := sha1.Sum([]byte(fmt.Sprintf("%s|%d|%d|%d", imagePath, info.Size(),
+			info.ModTime().UnixNano(), size)))
+		cached = g.options.cachePath("thumbnails", hex.EncodeToString(sum[:])+path.Ext(imagePath))
+
+		if _, err := os.Stat(cached); err != nil {
+			err = createThumbnail(source, cached, size)
+			if err != nil {
+				return 0, 0, err
+			}
+		}
+	}
+
+	file, err := os.Open(cached)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer file.Close()
+
+	config, _, err := image.DecodeConfig(file)
+	if err != nil {
+		return 0, 0, err
+	}
+	file.Seek(0, io.SeekStart)
+
+	err = g.generateFile(filepath.FromSlash(scaledPath), func(w io.Writer) error {
+		_, err := io.Copy(w, file)
+		return err
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+
+	g.thumbnails[scaledPath] = image.Pt(config.Width, config.Height)
+	return config.Width, config.Height, nil
+}
+
+// createThumbnail scales the image at the source path down to fit in a
+// square with the size and writes it to the target path in the image's
+// format.
+func createThumbnail(source, target string, size int) error {
+	file, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	img, format, err := image.Decode(file)
+	if err != nil {
+		return err
+	}
+	thumbnail := scaleDown(img, size)
+
+	err = os.MkdirAll(filepath.Dir(target), 0700)
+	if err != nil {
+		return err
+	}
+
+	out, err := os.Create(target)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	switch format {
+	case "jpeg":
+		return jpeg.Encode(out, thumbnail, &jpeg.Options{Quality: 85})
+	case "gif":
+		return gif.Encode(out, thumbnail, nil)
+	}
+	return png.Encode(out, thumbnail)
+}
+
+// scaleDown scales the image down (averaging pixels) to fit in a square with
+// the size. Smaller images are returned unchanged.
+func scaleDown(img image.Image, size int) image.Image {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	if w <= size && h <= size {
+		return img
+	}
+
+	tw, th := size, max(1, h*size/w)
+	if h > w {
+		tw, th = max(1, w*size/h), size
+	}
+
+	thumbnail := image.NewRGBA64(image.Rect(0, 0, tw, th))
+	for y := 0; y < th; y++ {
+		y0, y1 := bounds.Min.Y+y*h/th, bounds.Min.Y+(y+1)*h/th
+		for x := 0; x < tw; x++ {
+			x0, x1 := bounds.Min.X+x*w/tw, bounds.Min.X+(x+1)*w/tw
+
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					cr, cg, cb, ca := img.At(sx, sy).RGBA()
+					r, g, b, a, n = r+uint64(cr), g+uint64(cg), b+uint64(cb), a+uint64(ca), n+1
+				}
+			}
+			thumbnail.SetRGBA64(x, y, color.RGBA64{uint16(r / n), uint16(g / n),
+				uint16(b / n), uint16(a / n)})
+		}
+	}
+	return thumbnail
+}
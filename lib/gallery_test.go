@@ -0,0 +1,60 @@
+package lib
+
+import (
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestScaleDown(t *testing.T) {
+	thumbnail := scaleDown(image.NewRGBA(image.Rect(0, 0, 600, 400)), 300)
+	if size := thumbnail.Bounds().Size(); size != image.Pt(300, 200) {
+		t.Errorf("want %v, got %v", image.Pt(300, 200), size)
+	}
+
+	small := image.NewRGBA(image.Rect(0, 0, 100, 50))
+	if thumbnail := scaleDown(small, 300); thumbnail != image.Image(small) {
+		t.Errorf("want the image unchanged, got %v", thumbnail.Bounds())
+	}
+}
+
+func TestExpandGalleryShortcode(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "images", "rome"), 0700)
+	for name, width := range map[string]int{"forum.png": 400, "colosseum.png": 100} {
+		file, _ := os.Create(filepath.Join(dir, "images", "rome", name))
+		png.Encode(file, image.NewRGBA(image.Rect(0, 0, width, 100)))
+		file.Close()
+	}
+
+	g := StaticBlogGenerator{outputDir: dir, progressFunc: func(string) {},
+		thumbnails: map[string]image.Point{},
+		options:    options{config: Config{ThumbnailSize: 200}, cacheDir: t.TempDir()}}
+
+	html, err := g.expandShortcodes("Photos:\n{{< gallery \"images/rome\" >}}\n")
+	if err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+
+	for _, want := range []string{
+		`<div class="gallery">`,
+		`<a class="gallery-item" href="/images/rome/colosseum.png" data-lightbox="images/rome">` +
+			`<img src="/images/rome/thumbs/colosseum.png" alt="colosseum" width="100" height="100" loading="lazy"></a>`,
+		`<img src="/images/rome/thumbs/forum.png" alt="forum" width="200" height="50" loading="lazy">`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("want %q in %q", want, html)
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "images", "rome", "thumbs", "forum.png")); err != nil {
+		t.Errorf("want a thumbnail, got %v", err)
+	}
+
+	if _, err := g.expandShortcodes("{{< unknown >}}"); err == nil {
+		t.Errorf("want an error, got nil")
+	}
+}
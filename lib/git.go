@@ -0,0 +1,79 @@
+package lib
+
+import (
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// gitDates holds the dates of files read from the Git history keyed by their
+// paths (with slashes) relative to the directory whose history was read.
+type gitDates map[string]gitFileDates
+
+// gitFileDates holds the dates of the first (added) and the last (updated)
+// commits that touched a file.
+type gitFileDates struct {
+	added, updated time.Time
+}
+
+// readGitDates reads the dates of the files in the directory (and its
+// subdirectories) in a single walk of its Git history. If the directory isn't
+// in a Git repository (or Git isn't available) it returns nil.
+func readGitDates(dir string) gitDates {
+	// commits start with a NUL, so they can't be confused with file names
+	cmd := exec.Command("git", "-c", "core.quotePath=false", "log", "--name-only",
+		"--relative", "--format=%x00%ct")
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return nil
+	}
+
+	dates := gitDates{}
+	var committed time.Time
+	for _, line := range strings.Split(string(out), "\n") {
+		if strings.HasPrefix(line, "\x00") {
+			seconds, err := strconv.ParseInt(strings.TrimPrefix(line, "\x00"), 10, 64)
+			if err != nil {
+				return nil
+			}
+			committed = time.Unix(seconds, 0)
+			continue
+		}
+		if line == "" {
+			continue
+		}
+
+		// the history is walked from the latest commit
+		file, ok := dates[line]
+		if !ok {
+			file.updated = committed
+		}
+		file.added = committed
+		dates[line] = file
+	}
+	return dates
+}
+
+// of returns the dates of the file at the path (relative to the directory
+// whose history was read). They're zero if the file isn't committed.
+func (d gitDates) of(path string) gitFileDates {
+	return d[filepath.ToSlash(path)]
+}
+
+// written returns the date a Post dated with the day (without a time) was
+// published: the date of the commit that added its file if it was on the same
+// day (in the loc). Otherwise it returns the day.
+func (d gitFileDates) written(day time.Time, loc *time.Location) time.Time {
+	if d.added.IsZero() || day.Hour()+day.Minute()+day.Second() != 0 {
+		return day
+	}
+
+	added := d.added.In(loc)
+	if added.Format(time.DateOnly) != day.Format(time.DateOnly) {
+		return day
+	}
+	return added
+}
@@ -0,0 +1,97 @@
+package lib
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// commitAt writes the files in the directory and commits them with the
+// committer date.
+func commitAt(t *testing.T, dir string, date time.Time, files map[string]string) {
+	for name, content := range files {
+		os.MkdirAll(filepath.Join(dir, filepath.Dir(name)), 0700)
+		os.WriteFile(filepath.Join(dir, name), []byte(content), 0600)
+	}
+	for _, args := range [][]string{{"add", "."},
+		{"-c", "user.name=Test", "-c", "user.email=test@example.com", "commit", "--quiet", "-m", "posts"}} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), "GIT_COMMITTER_DATE="+date.Format(time.RFC3339),
+			"GIT_AUTHOR_DATE="+date.Format(time.RFC3339))
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %s: %s: %s", args[0], err, out)
+		}
+	}
+}
+
+func TestReadGitDates(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git isn't available")
+	}
+
+	dir := t.TempDir()
+	if dates := readGitDates(dir); dates != nil {
+		t.Errorf("want %v, got %v", nil, dates)
+	}
+
+	if err := git(dir, "init", "--quiet"); err != nil {
+		t.Fatal(err)
+	}
+	first := time.Date(2021, 8, 10, 15, 4, 5, 0, time.UTC)
+	second := first.Add(48 * time.Hour)
+	commitAt(t, dir, first, map[string]string{"posts/rome.md": "Rome", "README.md": "Blog"})
+	commitAt(t, dir, second, map[string]string{"posts/rome.md": "Rome!", "posts/café.md": "Café"})
+
+	// paths are relative to the directory the history is read in
+	dates := readGitDates(filepath.Join(dir, "posts"))
+	for path, want := range map[string]gitFileDates{
+		"rome.md":   {first, second},
+		"café.md":   {second, second},
+		"README.md": {},
+	} {
+		if got := dates.of(path); !got.added.Equal(want.added) || !got.updated.Equal(want.updated) {
+			t.Errorf("%s: want %v, got %v", path, want, got)
+		}
+	}
+}
+
+func TestMarkdownBlogGitDates(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git isn't available")
+	}
+
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, postsDir, draftDir), 0700)
+	if err := git(dir, "init", "--quiet"); err != nil {
+		t.Fatal(err)
+	}
+	added := time.Date(2021, 8, 10, 15, 4, 5, 0, time.UTC)
+	updated := added.Add(48 * time.Hour)
+	commitAt(t, dir, added, map[string]string{
+		"posts/rome.md":  "# Rome\n\n*Aug 10, 2021*\n\nRome\n",
+		"posts/paris.md": "# Paris\n\n*Aug 9, 2021*\n\nParis\n",
+	})
+	commitAt(t, dir, updated, map[string]string{"posts/rome.md": "# Rome\n\n*Aug 10, 2021*\n\nRome!\n"})
+
+	blog, err := NewMarkdownBlog(dir, WithConfig(Config{GitDates: true})).Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	posts := map[string]Post{}
+	for _, post := range blog {
+		posts[post.Title] = post
+	}
+
+	if rome := posts["Rome"]; !rome.Written.Equal(added) || !rome.Updated.Equal(updated) {
+		t.Errorf("want %v and %v, got %v and %v", added, updated, rome.Written, rome.Updated)
+	}
+
+	// posts added to Git on another day keep their date
+	written := time.Date(2021, 8, 9, 0, 0, 0, 0, time.UTC)
+	if paris := posts["Paris"]; !paris.Written.Equal(written) || !paris.Updated.Equal(added) {
+		t.Errorf("want %v and %v, got %v and %v", written, added, paris.Written, paris.Updated)
+	}
+}
@@ -0,0 +1,101 @@
+package lib
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/gosimple/slug"
+)
+
+// GitBlog is a MarkdownBlog stored in a remote Git repository. A shallow clone
+// of the repository (depth 1) is kept in the cache directory (see
+// WithCacheDir) and updated each time the Blog is read.
+type GitBlog struct {
+	url    string
+	branch string
+	dir    string
+	opts   []Option
+}
+
+// NewGitBlog creates a GitBlog of the repository at the URL. A branch (or a
+// tag) can be selected by appending it after a # to the URL (for example
+// https://github.com/johndoe/posts.git#main), otherwise the default branch is
+// used.
+func NewGitBlog(url string, opts ...Option) GitBlog {
+	url, branch, _ := strings.Cut(url, "#")
+	dir := newOptions(opts).cachePath("sources", slug.Make(url+"-"+branch))
+	return GitBlog{url, branch, dir, opts}
+}
+
+// IsGitURL tells whether the source is the URL of a remote Git repository
+// (with the ssh, git or file scheme, in the scp-like user@host:path form or
+// with the http(s) scheme and the .git extension).
+func IsGitURL(source string) bool {
+	source, _, _ = strings.Cut(source, "#")
+	for _, prefix := range []string{"ssh://", "git://", "file://", "git@"} {
+		if strings.HasPrefix(source, prefix) {
+			return true
+		}
+	}
+	return (strings.HasPrefix(source, "https://") || strings.HasPrefix(source, "http://")) &&
+		strings.HasSuffix(source, ".git")
+}
+
+// Read clones (or updates) the repository and creates a Blog from its
+// Markdown files. The drafts directory is optional because Git doesn't store
+// empty directories.
+func (b GitBlog) Read() (Blog, error) {
+	err := b.sync()
+	if err != nil {
+		return Blog{}, fmt.Errorf("failed to fetch %s: %s", b.url, err)
+	}
+
+	err = os.MkdirAll(filepath.Join(b.dir, postsDir, draftDir), 0700)
+	if err != nil {
+		return Blog{}, err
+	}
+	return NewMarkdownBlog(b.dir, b.opts...).Read()
+}
+
+// sync clones the repository to the directory if it isn't there yet,
+// otherwise it fetches the latest commit and resets the working tree to it.
+func (b GitBlog) sync() error {
+	if _, err := os.Stat(filepath.Join(b.dir, ".git")); err != nil {
+		os.RemoveAll(b.dir)
+		err := os.MkdirAll(filepath.Dir(b.dir), 0700)
+		if err != nil {
+			return err
+		}
+
+		args := []string{"clone", "--quiet", "--depth", "1"}
+		if b.branch != "" {
+			args = append(args, "--branch", b.branch)
+		}
+		return git("", append(args, b.url, b.dir)...)
+	}
+
+	ref := b.branch
+	if ref == "" {
+		ref = "HEAD"
+	}
+	err := git(b.dir, "fetch", "--quiet", "--depth", "1", "origin", ref)
+	if err != nil {
+		return err
+	}
+	return git(b.dir, "reset", "--quiet", "--hard", "FETCH_HEAD")
+}
+
+// git runs the Git command with the arguments in the directory.
+func git(dir string, args ...string) error {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("git %s: %s: %s", args[0], err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
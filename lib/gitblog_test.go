@@ -0,0 +1,63 @@
+package lib
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestIsGitURL(t *testing.T) {
+	for source, want := range map[string]bool{
+		"https://github.com/johndoe/posts.git":      true,
+		"https://github.com/johndoe/posts.git#main": true,
+		"git@github.com:johndoe/posts.git":          true,
+		"file:///srv/posts":                         true,
+		"https://github.com/johndoe/posts":          false,
+		"../team-blog":                              false,
+	} {
+		if got := IsGitURL(source); got != want {
+			t.Errorf("%s: want %v, got %v", source, want, got)
+		}
+	}
+}
+
+func TestGitBlog(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git isn't available")
+	}
+
+	repo := t.TempDir()
+	commit := func(name, content string) {
+		os.MkdirAll(filepath.Join(repo, postsDir), 0700)
+		os.WriteFile(filepath.Join(repo, postsDir, name), []byte(content), 0600)
+		for _, args := range [][]string{{"add", "."},
+			{"-c", "user.name=Test", "-c", "user.email=test@example.com", "commit", "-m", name}} {
+			if err := git(repo, args...); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := git(repo, "init", "--quiet"); err != nil {
+		t.Fatal(err)
+	}
+	commit("rome.md", "# Rome\n\n*Aug 10, 2021*\n\nRome\n")
+
+	b := NewGitBlog("file://"+repo, WithCacheDir(t.TempDir()))
+	blog, err := b.Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(blog) != 1 || blog[0].Title != "Rome" {
+		t.Errorf("want %v, got %v", "[Rome]", blog)
+	}
+
+	commit("paris.md", "# Paris\n\n*Aug 11, 2021*\n\nParis\n")
+	blog, err = b.Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(blog) != 2 {
+		t.Errorf("want %v, got %v", 2, len(blog))
+	}
+}
@@ -0,0 +1,179 @@
+package lib
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// HeadersFile is the name of the file in the output directory with the
+// headers of files for hosts like Netlify and Cloudflare Pages.
+const HeadersFile = "_headers"
+
+// immutableCacheControl is the Cache-Control header of fingerprinted assets.
+const immutableCacheControl = "public, max-age=31536000, immutable"
+
+// fingerprintRegexp matches names of files with a hash of their content, for
+// example main.3f2a9c1e.css or app-5d41402abc4b2a76.js.
+var fingerprintRegexp = regexp.MustCompile(`(?i)[.-][0-9a-f]{8,}\.[a-z0-9]+$`)
+
+// defaultSecurityHeaders are the security headers of all files if the
+// HeadersConfig doesn't change them.
+var defaultSecurityHeaders = map[string]string{
+	"X-Content-Type-Options": "nosniff",
+	"X-Frame-Options":        "DENY",
+	"Referrer-Policy":        "strict-origin-when-cross-origin",
+}
+
+// HeadersConfig holds settings of the HeadersFile.
+type HeadersConfig struct {
+	// Generate tells whether the HeadersFile with caching and security
+	// headers is generated.
+	Generate bool `yaml:"generate"`
+
+	// HTMLMaxAge is the number of seconds browsers cache pages for. If it's 0
+	// they revalidate pages on each visit.
+	HTMLMaxAge int `yaml:"htmlMaxAge"`
+
+	// Immutable are patterns (see path.Match) of URLs of files cached for a
+	// year besides the fingerprinted ones (files whose names end with a hash,
+	// like main.3f2a9c1e.css).
+	Immutable []string `yaml:"immutable"`
+
+	// Security holds security headers of all files. They're added to the
+	// default ones (X-Content-Type-Options, X-Frame-Options and
+	// Referrer-Policy) or replace them; an empty value removes a default one.
+	Security map[string]string `yaml:"security"`
+}
+
+// securityHeaders returns the security headers of all files sorted by name.
+func (c HeadersConfig) securityHeaders() [][2]string {
+	headers := map[string]string{}
+	for name, value := range defaultSecurityHeaders {
+		headers[name] = value
+	}
+	for name, value := range c.Security {
+		headers[name] = value
+	}
+
+	var sorted [][2]string
+	for name, value := range headers {
+		if value != "" {
+			sorted = append(sorted, [2]string{name, value})
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i][0] < sorted[j][0] })
+	return sorted
+}
+
+// immutable tells whether the file with the URL is cached for a year.
+func (c HeadersConfig) immutable(url string) bool {
+	if fingerprintRegexp.MatchString(path.Base(url)) {
+		return true
+	}
+	for _, pattern := range c.Immutable {
+		if ok, _ := path.Match(pattern, url); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// generateHeaders generates the HeadersFile with the Content Security
+// Policies of pages (by their URLs) and, if the HeadersConfig's Generate is
+// set, the security headers of all files and the Cache-Control headers of
+// pages and immutable files in the output directory. Rules of an existing
+// HeadersFile (copied from the static directory) are kept. Each file has only
+// one rule setting its Cache-Control header, because hosts join values of
+// headers set by more rules.
+func (g StaticBlogGenerator) generateHeaders(policies map[string]string) error {
+	config := g.options.config.Headers
+	rules := map[string][][2]string{}
+
+	if config.Generate {
+		err := filepath.WalkDir(g.outputDir, func(p string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return err
+			}
+			rel, err := filepath.Rel(g.outputDir, p)
+			if err != nil {
+				return err
+			}
+
+			if strings.ToLower(filepath.Ext(p)) == ".html" {
+				url := g.options.config.withBasePath(g.pageURL(rel))
+				cache := "public, max-age=0, must-revalidate"
+				if config.HTMLMaxAge > 0 {
+					cache = fmt.Sprintf("public, max-age=%d", config.HTMLMaxAge)
+				}
+				rules[url] = append(rules[url], [2]string{"Cache-Control", cache})
+				return nil
+			}
+
+			url := g.options.config.withBasePath("/" + filepath.ToSlash(rel))
+			if config.immutable(url) {
+				rules[url] = append(rules[url], [2]string{"Cache-Control", immutableCacheControl})
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	for url, policy := range policies {
+		rules[url] = append(rules[url], [2]string{"Content-Security-Policy", policy})
+	}
+
+	var urls []string
+	for url := range rules {
+		urls = append(urls, url)
+	}
+	sort.Strings(urls)
+
+	// rules of a HeadersFile in the static directory are kept
+	existing, err := os.ReadFile(filepath.Join(g.outputDir, HeadersFile))
+	if err == nil {
+		os.Remove(filepath.Join(g.outputDir, HeadersFile))
+		if len(existing) > 0 && !bytes.HasSuffix(existing, []byte("\n")) {
+			existing = append(existing, '\n')
+		}
+	}
+
+	return g.generateFile(HeadersFile, func(w io.Writer) error {
+		_, err := w.Write(existing)
+		if err != nil {
+			return err
+		}
+		if config.Generate {
+			err := writeHeadersRule(w, g.options.config.withBasePath("/*"), config.securityHeaders())
+			if err != nil {
+				return err
+			}
+		}
+		for _, url := range urls {
+			err := writeHeadersRule(w, url, rules[url])
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func writeHeadersRule(w io.Writer, url string, headers [][2]string) error {
+	_, err := fmt.Fprintf(w, "%s\n", url)
+	for _, header := range headers {
+		if err == nil {
+			_, err = fmt.Fprintf(w, "  %s: %s\n", header[0], header[1])
+		}
+	}
+	return err
+}
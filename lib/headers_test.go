@@ -0,0 +1,53 @@
+package lib
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestGenerateHeaders(t *testing.T) {
+	templates := writeTestTemplates(t)
+	static := t.TempDir()
+	os.MkdirAll(filepath.Join(static, "css"), 0700)
+	os.MkdirAll(filepath.Join(static, "fonts"), 0700)
+	os.WriteFile(filepath.Join(static, "css", "main.3f2a9c1e.css"), []byte("p{}"), 0600)
+	os.WriteFile(filepath.Join(static, "css", "print.css"), []byte("p{}"), 0600)
+	os.WriteFile(filepath.Join(static, "fonts", "serif.woff2"), []byte("font"), 0600)
+	os.WriteFile(filepath.Join(static, HeadersFile), []byte("/feed\n  X-Robots-Tag: noindex"), 0600)
+	blog := Blog{{Title: "Rome", Written: time.Now()}}
+
+	output := t.TempDir()
+	g, err := NewStaticBlogGenerator(blog, templates, output, func(string) {},
+		WithStaticDir(static), WithConfig(Config{URLStyle: URLStyleClean,
+			Headers: HeadersConfig{Generate: true, HTMLMaxAge: 300,
+				Immutable: []string{"/fonts/*"},
+				Security:  map[string]string{"X-Frame-Options": "", "Permissions-Policy": "camera=()"}}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Generate(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `/feed
+  X-Robots-Tag: noindex
+/*
+  Permissions-Policy: camera=()
+  Referrer-Policy: strict-origin-when-cross-origin
+  X-Content-Type-Options: nosniff
+/
+  Cache-Control: public, max-age=300
+/css/main.3f2a9c1e.css
+  Cache-Control: public, max-age=31536000, immutable
+/fonts/serif.woff2
+  Cache-Control: public, max-age=31536000, immutable
+/rome
+  Cache-Control: public, max-age=300
+`
+	headers, _ := os.ReadFile(filepath.Join(output, HeadersFile))
+	if string(headers) != want {
+		t.Errorf("want %q, got %q", want, headers)
+	}
+}
@@ -0,0 +1,75 @@
+package lib
+
+import (
+	"fmt"
+	stdhtml "html"
+	"html/template"
+	"strings"
+)
+
+// FeedLink is a feed relevant to a page.
+type FeedLink struct {
+	Title string
+
+	// Type is the feed's media type, for example application/atom+xml.
+	Type string
+
+	// URL is the URL of the feed relative to the Blog's root.
+	URL string
+}
+
+// feeds returns the feeds relevant to the page with the data (like a Post or
+// a Tag): the feed of all Posts, the feed of the tag on tag pages, the feed of
+// the author on author pages, the feed of the year on post pages and the
+// podcast feed. Feeds are generated only if the Config's URL is set, so it
+// returns nothing otherwise.
+func (g StaticBlogGenerator) feeds(data interface{}) []FeedLink {
+	config := g.options.config
+	if config.URL == "" {
+		return nil
+	}
+
+	title := config.Title
+	if title == "" {
+		title = "Feed"
+	}
+	feeds := []FeedLink{{title, "application/atom+xml", "/atom.xml"}}
+
+	switch v := data.(type) {
+	case Tag:
+		if config.tagTaxonomy().feed(v.Name) {
+			feeds = append(feeds, FeedLink{fmt.Sprintf("%s: %s", config.Title, v.Title),
+				"application/atom+xml", g.tagFeedURL(v.Name)})
+		}
+	case Author:
+		if config.Taxonomies.Authors.feed(v.Name) {
+			feeds = append(feeds, FeedLink{fmt.Sprintf("%s: %s", config.Title, v.Name),
+				"application/atom+xml", g.authorFeedURL(v.Name)})
+		}
+	case Post:
+		if config.YearlyFeeds && !v.Unlisted {
+			feeds = append(feeds, FeedLink{fmt.Sprintf("%s (%d)", config.Title, v.Written.Year()),
+				"application/atom+xml", yearlyFeedURL(v.Written.Year())})
+		}
+	}
+
+	if hasAudio(g.posts) {
+		podcast := config.Podcast.Title
+		if podcast == "" {
+			podcast = title
+		}
+		feeds = append(feeds, FeedLink{podcast, "application/rss+xml", "/" + PodcastFile})
+	}
+	return feeds
+}
+
+// headLinks returns the link elements of the feeds relevant to the page with
+// the data (see feeds) for the head element, for example {{headLinks .}}.
+func (g StaticBlogGenerator) headLinks(data interface{}) template.HTML {
+	var links []string
+	for _, feed := range g.feeds(data) {
+		links = append(links, fmt.Sprintf(`<link rel="alternate" type="%s" title="%s" href="%s">`,
+			feed.Type, stdhtml.EscapeString(feed.Title), stdhtml.EscapeString(feed.URL)))
+	}
+	return template.HTML(strings.Join(links, "\n"))
+}
@@ -0,0 +1,51 @@
+package lib
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHeadLinks(t *testing.T) {
+	templates := writeTestTemplates(t)
+	os.WriteFile(filepath.Join(templates, "layout.tmpl"),
+		[]byte(`{{headLinks .}}|{{template "content" .}}`), 0600)
+	blog := Blog{
+		{Title: "Rome", Tags: []string{"cities"}, Written: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	output := t.TempDir()
+	g, err := NewStaticBlogGenerator(blog, templates, output, func(string) {},
+		WithConfig(Config{URL: "https://example.com", Title: "Cities", TagFeeds: true, YearlyFeeds: true}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Generate(); err != nil {
+		t.Fatal(err)
+	}
+
+	site := `<link rel="alternate" type="application/atom+xml" title="Cities" href="/atom.xml">`
+	for path, want := range map[string]string{
+		"index.html": site + "|",
+		"rome.html": site + "\n" +
+			`<link rel="alternate" type="application/atom+xml" title="Cities (2024)" href="/atom-2024.xml">|`,
+		"tags/cities.html": site + "\n" +
+			`<link rel="alternate" type="application/atom+xml" title="Cities: cities" href="/tags/cities.xml">|`,
+	} {
+		bytes, _ := os.ReadFile(filepath.Join(output, path))
+		if !strings.HasPrefix(string(bytes), want) {
+			t.Errorf("%s: want %q, got %q", path, want, bytes)
+		}
+	}
+
+	feed, err := os.ReadFile(filepath.Join(output, "tags", "cities.xml"))
+	if err != nil || !strings.Contains(string(feed), "<title>Rome</title>") {
+		t.Errorf("want the tag feed, got %q (%v)", feed, err)
+	}
+
+	if feeds := (StaticBlogGenerator{}).feeds(Post{}); feeds != nil {
+		t.Errorf("want no feeds without a url, got %v", feeds)
+	}
+}
@@ -0,0 +1,125 @@
+package lib
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// HeatmapFile is the name of the generated file with the numbers of Posts
+// written each day and week.
+const HeatmapFile = "activity.json"
+
+// heatmapLevels is the number of levels of posting activity (besides no
+// activity).
+const heatmapLevels = 4
+
+// Heatmap holds the numbers of Posts written each day, grouped in weeks
+// starting on Mondays, like a contribution graph.
+type Heatmap struct {
+	// Weeks are all the weeks from the week of the first Post to the week of
+	// the last one.
+	Weeks []HeatmapWeek
+
+	// Max is the largest number of Posts written in a day.
+	Max int
+}
+
+// HeatmapWeek holds the numbers of Posts written on the seven days of a week.
+type HeatmapWeek struct {
+	Start time.Time
+	Days  []HeatmapDay
+	Count int
+}
+
+// HeatmapDay holds the number of Posts written on a day and its Level, from 0
+// (no Posts) to 4 (the most Posts).
+type HeatmapDay struct {
+	Date  time.Time
+	Count int
+	Level int
+}
+
+type heatmapJSON struct {
+	Max   int               `json:"max"`
+	Weeks []heatmapWeekJSON `json:"weeks"`
+}
+
+type heatmapWeekJSON struct {
+	Start string           `json:"start"`
+	Count int              `json:"count"`
+	Days  []heatmapDayJSON `json:"days"`
+}
+
+type heatmapDayJSON struct {
+	Date  string `json:"date"`
+	Count int    `json:"count"`
+	Level int    `json:"level"`
+}
+
+// heatmap returns the Heatmap of the Posts (without drafts and pages).
+func (g StaticBlogGenerator) heatmap() Heatmap {
+	counts := map[string]int{}
+	var first, last time.Time
+	for _, post := range g.posts {
+		if post.Draft || post.IsPage || post.Written.IsZero() {
+			continue
+		}
+
+		day := startOfDay(post.Written)
+		counts[day.Format("2006-01-02")]++
+		if first.IsZero() || day.Before(first) {
+			first = day
+		}
+		if day.After(last) {
+			last = day
+		}
+	}
+
+	var heatmap Heatmap
+	if first.IsZero() {
+		return heatmap
+	}
+
+	for _, count := range counts {
+		heatmap.Max = max(heatmap.Max, count)
+	}
+
+	start := first.AddDate(0, 0, -(int(first.Weekday())+6)%7)
+	for ; !start.After(last); start = start.AddDate(0, 0, 7) {
+		week := HeatmapWeek{Start: start}
+		for i := 0; i < 7; i++ {
+			date := start.AddDate(0, 0, i)
+			count := counts[date.Format("2006-01-02")]
+			level := (count*heatmapLevels + heatmap.Max - 1) / heatmap.Max
+			week.Days = append(week.Days, HeatmapDay{date, count, level})
+			week.Count += count
+		}
+		heatmap.Weeks = append(heatmap.Weeks, week)
+	}
+	return heatmap
+}
+
+// startOfDay returns the midnight of the time's day (in the time's location).
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+func (g StaticBlogGenerator) generateHeatmap() error {
+	heatmap := g.heatmap()
+	data := heatmapJSON{Max: heatmap.Max, Weeks: []heatmapWeekJSON{}}
+	for _, week := range heatmap.Weeks {
+		w := heatmapWeekJSON{Start: week.Start.Format("2006-01-02"), Count: week.Count}
+		for _, day := range week.Days {
+			w.Days = append(w.Days, heatmapDayJSON{day.Date.Format("2006-01-02"),
+				day.Count, day.Level})
+		}
+		data.Weeks = append(data.Weeks, w)
+	}
+
+	return g.generateFile(HeatmapFile, func(w io.Writer) error {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(data)
+	})
+}
@@ -0,0 +1,65 @@
+package lib
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestHeatmap(t *testing.T) {
+	blog := Blog{
+		// Wednesday and Thursday of one week, Monday two weeks later.
+		{Title: "Rome", Written: time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)},
+		{Title: "Paris", Written: time.Date(2024, 5, 1, 18, 0, 0, 0, time.UTC)},
+		{Title: "Oslo", Written: time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC)},
+		{Title: "Lima", Written: time.Date(2024, 5, 13, 0, 0, 0, 0, time.UTC)},
+		{Title: "About", Written: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), IsPage: true},
+	}
+
+	output := t.TempDir()
+	g, err := NewStaticBlogGenerator(blog, writeTestTemplates(t), output, func(string) {},
+		WithConfig(Config{Heatmap: true}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	heatmap := g.heatmap()
+	if len(heatmap.Weeks) != 3 {
+		t.Fatalf("want %v, got %v", 3, len(heatmap.Weeks))
+	}
+	if heatmap.Max != 2 {
+		t.Errorf("want %v, got %v", 2, heatmap.Max)
+	}
+
+	week := heatmap.Weeks[0]
+	if want := time.Date(2024, 4, 29, 0, 0, 0, 0, time.UTC); !week.Start.Equal(want) {
+		t.Errorf("want %v, got %v", want, week.Start)
+	}
+	if week.Count != 3 {
+		t.Errorf("want %v, got %v", 3, week.Count)
+	}
+	for i, want := range []int{0, 0, 4, 2, 0, 0, 0} {
+		if week.Days[i].Level != want {
+			t.Errorf("want %v, got %v", want, week.Days[i].Level)
+		}
+	}
+	if heatmap.Weeks[1].Count != 0 || heatmap.Weeks[2].Days[0].Count != 1 {
+		t.Errorf("want %v and %v, got %v and %v", 0, 1, heatmap.Weeks[1].Count,
+			heatmap.Weeks[2].Days[0].Count)
+	}
+
+	if err := g.Generate(); err != nil {
+		t.Fatal(err)
+	}
+
+	var data heatmapJSON
+	bytes, _ := os.ReadFile(filepath.Join(output, HeatmapFile))
+	if err := json.Unmarshal(bytes, &data); err != nil {
+		t.Fatal(err)
+	}
+	if data.Weeks[0].Start != "2024-04-29" || data.Weeks[0].Days[2] != (heatmapDayJSON{"2024-05-01", 2, 4}) {
+		t.Errorf("want %v, got %v", "2024-04-29 and 2024-05-01", data.Weeks[0])
+	}
+}
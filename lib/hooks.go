@@ -0,0 +1,83 @@
+package lib
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// HooksConfig holds shell commands run before and after generating a Blog
+// (for example an asset bundler and a deploy script). The commands get the
+// BuildInfo in environment variables (see BuildInfo.Env).
+type HooksConfig struct {
+	Before []string `yaml:"before"`
+	After  []string `yaml:"after"`
+}
+
+// BuildInfo describes a build of a Blog. It's passed to Hooks.
+type BuildInfo struct {
+	URL       string
+	OutputDir string
+
+	// Posts is the number of generated (listed and unlisted) Posts.
+	Posts   int
+	Started time.Time
+
+	// Duration of the build. It's 0 in hooks run before the build.
+	Duration time.Duration
+}
+
+// Env returns the BuildInfo as LITEPUB_* environment variables.
+func (i BuildInfo) Env() []string {
+	env := []string{
+		"LITEPUB_URL=" + i.URL,
+		"LITEPUB_OUTPUT_DIR=" + i.OutputDir,
+		"LITEPUB_POSTS=" + strconv.Itoa(i.Posts),
+		"LITEPUB_STARTED=" + i.Started.Format(time.RFC3339),
+	}
+	if i.Duration > 0 {
+		env = append(env, "LITEPUB_DURATION="+strconv.FormatFloat(i.Duration.Seconds(), 'f', 3, 64))
+	}
+	return env
+}
+
+// Hook is a function run before or after generating a Blog. If it returns an
+// error the build fails.
+type Hook func(info BuildInfo) error
+
+// FileHook is a function processing the content of each file generated from
+// the Blog (its path is relative to the output directory and uses slashes)
+// before it's written, for example to inject an analytics snippet. It returns
+// the content to write. If it returns an error the build fails.
+type FileHook func(path string, content []byte) ([]byte, error)
+
+// CommandHook returns a Hook running the shell command in the directory (or in
+// the current directory if it's empty) with the BuildInfo in environment
+// variables.
+func CommandHook(command, dir string) Hook {
+	return func(info BuildInfo) error {
+		cmd := exec.Command("sh", "-c", command)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), info.Env()...)
+
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("failed to run %s: %s: %s", command, err,
+				strings.TrimSpace(string(out)))
+		}
+		return nil
+	}
+}
+
+func runHooks(hooks []Hook, info BuildInfo) error {
+	for _, hook := range hooks {
+		err := hook(info)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package lib
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGenerateHooks(t *testing.T) {
+	templates := writeTestTemplates(t)
+	blog := Blog{{Title: "Rome", Written: time.Now()}}
+
+	dir := t.TempDir()
+	output := filepath.Join(dir, "www")
+	var calls []string
+	before := func(info BuildInfo) error {
+		_, err := os.Stat(filepath.Join(output, "index.html"))
+		calls = append(calls, "before")
+		if !errors.Is(err, os.ErrNotExist) || info.Duration != 0 {
+			t.Errorf("want %v, got %v (%v)", "no output", err, info.Duration)
+		}
+		return nil
+	}
+	after := CommandHook(`echo "$LITEPUB_POSTS $LITEPUB_OUTPUT_DIR" > hook.txt`, dir)
+
+	g, err := NewStaticBlogGenerator(blog, templates, output, func(string) {},
+		WithBeforeHook(before), WithAfterHook(after))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Generate(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(calls) != 1 {
+		t.Errorf("want %v, got %v", []string{"before"}, calls)
+	}
+	bytes, _ := os.ReadFile(filepath.Join(dir, "hook.txt"))
+	if want := "1 " + output; strings.TrimSpace(string(bytes)) != want {
+		t.Errorf("want %q, got %q", want, bytes)
+	}
+}
+
+func TestGenerateFailingHook(t *testing.T) {
+	templates := writeTestTemplates(t)
+	output := t.TempDir()
+
+	g, err := NewStaticBlogGenerator(Blog{}, templates, output, func(string) {},
+		WithBeforeHook(CommandHook("echo oops >&2; exit 1", "")))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = g.Generate()
+	if err == nil || !strings.Contains(err.Error(), "oops") {
+		t.Errorf("want %v, got %v", "an error with oops", err)
+	}
+}
+
+func TestGenerateFileHooks(t *testing.T) {
+	templates := writeTestTemplates(t)
+	blog := Blog{{Title: "Rome", Written: time.Now()}}
+	output := t.TempDir()
+
+	var paths []string
+	record := func(path string, content []byte) ([]byte, error) {
+		paths = append(paths, path)
+		return content, nil
+	}
+	analytics := func(path string, content []byte) ([]byte, error) {
+		if !strings.HasSuffix(path, ".html") {
+			return content, nil
+		}
+		return append(content, "<script src=/a.js></script>"...), nil
+	}
+
+	g, err := NewStaticBlogGenerator(blog, templates, output, func(string) {},
+		WithConfig(Config{URL: "https://example.com"}), WithFileHook(record),
+		WithFileHook(analytics))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Generate(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range []string{"index.html", "rome.html", "atom.xml"} {
+		if !containsFold(paths, path) {
+			t.Errorf("want %v in %v", path, paths)
+		}
+	}
+	index, _ := os.ReadFile(filepath.Join(output, "index.html"))
+	if !strings.HasSuffix(string(index), "<script src=/a.js></script>") {
+		t.Errorf("want %q at the end, got %q", "<script src=/a.js></script>", index)
+	}
+	feed, _ := os.ReadFile(filepath.Join(output, "atom.xml"))
+	if strings.Contains(string(feed), "<script") {
+		t.Errorf("want no script in the feed, got %s", feed)
+	}
+}
+
+func TestGenerateFailingFileHook(t *testing.T) {
+	templates := writeTestTemplates(t)
+
+	g, err := NewStaticBlogGenerator(Blog{}, templates, t.TempDir(), func(string) {},
+		WithFileHook(func(path string, content []byte) ([]byte, error) {
+			return nil, errors.New("oops")
+		}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = g.Generate()
+	if err == nil || !strings.Contains(err.Error(), "failed to process index.html: oops") {
+		t.Errorf("want %v, got %v", "an error with oops", err)
+	}
+}
@@ -1,172 +1,838 @@
 package lib
 
 import (
+	"bufio"
+	"bytes"
 	"fmt"
+	stdhtml "html"
 	"html/template"
+	"image"
+	"io"
 	"os"
+	"path"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"time"
+	"unicode"
 
-	"github.com/gosimple/slug"
 	"github.com/russross/blackfriday"
-	"github.com/termie/go-shutil"
 )
 
+// templateFiles are the required and optional templates. They aren't copied
+// to the output directory.
+var templateFiles = []string{"layout.tmpl", "index.tmpl", "post.tmpl",
+	"tag.tmpl", "tags.tmpl", "404.tmpl", "expired.tmpl", "author.tmpl",
+	"blogroll.tmpl", "archive.tmpl", "link.tmpl", "print.tmpl", NewsletterTemplate,
+	ThemeFile}
+
 // ProgressFunc is used to monitor progress of generating a Blog. It is called
 // before a file generation is started.
 type ProgressFunc func(path string)
 
 // StaticBlogGenerator generates Blogs to static HTML files.
 type StaticBlogGenerator struct {
-	templatesDir  string
-	outputDir     string
-	progressFunc  ProgressFunc
-	indexTemplate *template.Template
-	postTemplate  *template.Template
-	tagTemplate   *template.Template
-	posts         []Post
-	postsByTag    map[string][]Post
+	options          options
+	dates            dateFormatter
+	templatesDir     string
+	outputDir        string
+	progressFunc     ProgressFunc
+	indexTemplate    *template.Template
+	postTemplate     *template.Template
+	tagTemplate      *template.Template
+	tagTemplates     map[string]*template.Template
+	tagsTemplate     *template.Template
+	notFoundTemplate *template.Template
+	expiredTemplate  *template.Template
+	authorTemplate   *template.Template
+	blogrollTemplate *template.Template
+	archiveTemplate  *template.Template
+	linkTemplate     *template.Template
+	printTemplate    *template.Template
+	posts            []Post
+	unlisted         []Post
+	expired          []Post
+	tags             []Tag
+	authors          []Author
+	linkedFrom       map[string][]Post
+	localized        map[string]string
+	thumbnails       map[string]image.Point
+	theme            map[string]interface{}
+
+	// previous holds the hashes of the files in the output directory before
+	// the build. It's used only with a ProgressEventFunc.
+	previous map[string][32]byte
 }
 
-// Generate generates a Blog to static HTML files.
+// Generate generates a Blog to static HTML files. Hooks added with
+// WithBeforeHook and WithAfterHook run before and after that.
 func (g StaticBlogGenerator) Generate() error {
-	err := g.prepareOutputDir()
+	outputDir, err := filepath.Abs(g.outputDir)
 	if err != nil {
-		return fmt.Errorf("failed to prepare output directory: %s", err)
+		return fmt.Errorf("failed to resolve output directory: %s", err)
 	}
+	info := BuildInfo{URL: g.options.config.URL, OutputDir: outputDir,
+		Posts: len(g.posts) + len(g.unlisted), Started: time.Now()}
 
-	err = g.generateIndex()
+	err = g.options.profile.Time("before hooks", func() error {
+		return runHooks(g.options.before, info)
+	})
 	if err != nil {
-		return fmt.Errorf("failed to generate index: %s", err)
+		return fmt.Errorf("failed to run before hook: %s", err)
 	}
 
-	err = g.generateTags()
+	err = g.generate()
 	if err != nil {
-		return fmt.Errorf("failed to generate tags: %s", err)
+		return err
 	}
 
-	err = g.generatePosts()
+	info.Duration = time.Since(info.Started)
+	err = g.options.profile.Time("after hooks", func() error {
+		return runHooks(g.options.after, info)
+	})
 	if err != nil {
-		return fmt.Errorf("failed to generate posts: %s", err)
+		return fmt.Errorf("failed to run after hook: %s", err)
 	}
 
 	return nil
 }
 
-func (g StaticBlogGenerator) prepareOutputDir() error {
-	os.RemoveAll(g.outputDir)
+func (g StaticBlogGenerator) generate() error {
+	err := g.options.profile.Time("output directory", g.prepareOutputDir)
+	if err != nil {
+		return fmt.Errorf("failed to prepare output directory: %s", err)
+	}
+
+	err = g.options.profile.Time("photos", g.processPhotos)
+	if err != nil {
+		return fmt.Errorf("failed to process photos: %s", err)
+	}
 
-	err := shutil.CopyTree(g.templatesDir, g.outputDir,
-		&shutil.CopyTreeOptions{
-			Symlinks: true,
-			Ignore: func(string, []os.FileInfo) []string {
-				return []string{"layout.tmpl", "index.tmpl", "post.tmpl", "tag.tmpl"}
-			},
-			CopyFunction:           shutil.Copy,
-			IgnoreDanglingSymlinks: false,
+	if len(g.options.blogroll) > 0 {
+		err = g.options.profile.Time("blogroll", g.generateBlogroll)
+		if err != nil {
+			return fmt.Errorf("failed to generate blogroll: %s", err)
+		}
+	}
+
+	err = g.options.profile.Time("tag aliases", g.generateTagAliases)
+	if err != nil {
+		return fmt.Errorf("failed to generate tag aliases: %s", err)
+	}
+
+	err = g.options.profile.Time("redirects", g.generateRedirects)
+	if err != nil {
+		return fmt.Errorf("failed to generate redirects: %s", err)
+	}
+
+	pages := g.pages()
+	for _, s := range pageStages {
+		if !hasPages(pages, s.kind) {
+			continue
+		}
+
+		err = g.options.profile.Time(s.stage, func() error {
+			return g.generatePages(pages, s.kind)
 		})
+		if err != nil {
+			return fmt.Errorf("failed to generate %s: %s", s.stage, err)
+		}
+	}
+
+	if g.options.config.LinkGraph {
+		err = g.options.profile.Time("link graph", g.generateLinkGraph)
+		if err != nil {
+			return fmt.Errorf("failed to generate link graph: %s", err)
+		}
+	}
+
+	if g.options.config.Heatmap {
+		err = g.options.profile.Time("heatmap", g.generateHeatmap)
+		if err != nil {
+			return fmt.Errorf("failed to generate heatmap: %s", err)
+		}
+	}
+
+	if g.options.config.URL != "" {
+		err = g.options.profile.Time("sitemap", g.generateSitemap)
+		if err != nil {
+			return fmt.Errorf("failed to generate sitemap: %s", err)
+		}
+
+		err = g.options.profile.Time("feed", g.generateFeed)
+		if err != nil {
+			return fmt.Errorf("failed to generate feed: %s", err)
+		}
+
+		if g.options.config.YearlyFeeds {
+			err = g.options.profile.Time("yearly feeds", g.generateYearlyFeeds)
+			if err != nil {
+				return fmt.Errorf("failed to generate yearly feeds: %s", err)
+			}
+		}
+
+		if g.options.config.tagTaxonomy().Feeds {
+			err = g.options.profile.Time("tag feeds", g.generateTagFeeds)
+			if err != nil {
+				return fmt.Errorf("failed to generate tag feeds: %s", err)
+			}
+		}
+
+		if g.options.config.Taxonomies.Authors.Feeds {
+			err = g.options.profile.Time("author feeds", g.generateAuthorFeeds)
+			if err != nil {
+				return fmt.Errorf("failed to generate author feeds: %s", err)
+			}
+		}
+
+		if len(g.options.config.Feeds.Sections) > 0 {
+			err = g.options.profile.Time("section feeds", g.generateSectionFeeds)
+			if err != nil {
+				return fmt.Errorf("failed to generate section feeds: %s", err)
+			}
+		}
+
+		if hasAudio(g.posts) {
+			err = g.options.profile.Time("podcast feed", g.generatePodcast)
+			if err != nil {
+				return fmt.Errorf("failed to generate podcast feed: %s", err)
+			}
+		}
+	}
+
+	if g.options.config.BuildFile {
+		err = g.options.profile.Time("build file", g.generateBuildFile)
+		if err != nil {
+			return fmt.Errorf("failed to generate build file: %s", err)
+		}
+	}
+
+	err = g.options.profile.Time("plugins", g.processOutput)
 	if err != nil {
-		return err
+		return fmt.Errorf("failed to process output: %s", err)
 	}
 
-	return os.Mkdir(filepath.Join(g.outputDir, "tags"), 0700)
-}
+	if g.options.config.CSP.InlineCSS > 0 {
+		err = g.options.profile.Time("inline CSS", g.inlineCSS)
+		if err != nil {
+			return fmt.Errorf("failed to inline CSS: %s", err)
+		}
+	}
 
-func (g StaticBlogGenerator) generateIndex() error {
-	return g.generatePage(g.indexTemplate, "index.html", g.posts)
-}
+	if g.options.config.RelativeURLs {
+		err = g.options.profile.Time("relative URLs", g.relativizeOutput)
+		if err != nil {
+			return fmt.Errorf("failed to relativize URLs: %s", err)
+		}
+	} else if g.options.config.basePath() != "" {
+		err = g.options.profile.Time("base path", g.prefixOutput)
+		if err != nil {
+			return fmt.Errorf("failed to prefix URLs: %s", err)
+		}
+	}
 
-func (g StaticBlogGenerator) generatePosts() error {
-	for _, post := range g.posts {
-		err := g.generatePage(g.postTemplate, slug.Make(post.Title)+".html", post)
+	if g.options.config.PWA.enabled() {
+		err = g.options.profile.Time("pwa", g.generatePWA)
 		if err != nil {
+			return fmt.Errorf("failed to generate web app: %s", err)
+		}
+	}
+
+	var policies map[string]string
+	if g.options.config.CSP.Output != "" {
+		err = g.options.profile.Time("csp", func() (err error) {
+			policies, err = g.generateCSP()
 			return err
+		})
+		if err != nil {
+			return fmt.Errorf("failed to generate content security policies: %s", err)
+		}
+	}
+
+	if g.options.config.Headers.Generate || g.options.config.CSP.Output == CSPHeaders {
+		if g.options.config.CSP.Output != CSPHeaders {
+			policies = nil
+		}
+		err = g.options.profile.Time("headers", func() error {
+			return g.generateHeaders(policies)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to generate headers: %s", err)
+		}
+	}
+
+	if g.options.config.ValidateHTML {
+		err = g.options.profile.Time("validation", g.validateOutput)
+		if err != nil {
+			return fmt.Errorf("failed to validate output: %s", err)
+		}
+	}
+
+	if g.options.config.Audit {
+		err = g.options.profile.Time("audit", g.auditOutput)
+		if err != nil {
+			return fmt.Errorf("failed to audit output: %s", err)
+		}
+	}
+
+	if g.options.config.CheckLinks {
+		err = g.options.profile.Time("links", g.checkLinks)
+		if err != nil {
+			return fmt.Errorf("failed to check links: %s", err)
+		}
+	}
+
+	if g.options.progressEvents != nil {
+		err = g.reportChanges()
+		if err != nil {
+			return fmt.Errorf("failed to compare output: %s", err)
 		}
 	}
 
 	return nil
 }
 
-func (g StaticBlogGenerator) generateTags() error {
-	for tag, posts := range g.postsByTag {
-		err := g.generatePage(g.tagTemplate,
-			filepath.Join("tags", slug.Make(tag)+".html"), struct {
-				Name  string
-				Posts []Post
-			}{tag, posts})
+func (g StaticBlogGenerator) prepareOutputDir() error {
+	if g.options.progressEvents != nil {
+		err := g.hashOutput()
 		if err != nil {
 			return err
 		}
 	}
+	os.RemoveAll(g.outputDir)
 
-	return nil
+	templates, err := newCopier(g.options.config.Symlinks, g.ignoredTemplateFile)
+	if err != nil {
+		return err
+	}
+
+	err = templates.copy(g.templatesDir, g.outputDir)
+	if err != nil {
+		return err
+	}
+
+	if g.options.staticDir != "" {
+		if _, err := os.Stat(g.options.staticDir); err == nil {
+			static, err := newCopier(g.options.config.Symlinks, nil)
+			if err != nil {
+				return err
+			}
+
+			err = static.copy(g.options.staticDir, g.outputDir)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	err = os.MkdirAll(filepath.Join(g.outputDir, "tags"), 0700)
+	if err != nil {
+		return err
+	}
+
+	return g.copyBundles()
 }
 
-func (g StaticBlogGenerator) generatePage(template *template.Template,
-	path string, data interface{}) error {
+// generateFile writes the file with the path in the output directory. The
+// writes are buffered and flushed when the file is written completely. Files
+// of the static directory take precedence, so the file isn't written if the
+// static directory has a file with the path (except the HeadersFile, whose
+// rules are merged into the generated one).
+func (g StaticBlogGenerator) generateFile(path string,
+	write func(w io.Writer) error) error {
+	if g.staticFile(path) && path != HeadersFile {
+		return nil
+	}
 	g.progressFunc(path)
 
-	pageFile, err := os.OpenFile(filepath.Join(g.outputDir, path),
-		os.O_CREATE|os.O_WRONLY, 0600)
+	err := os.MkdirAll(filepath.Join(g.outputDir, filepath.Dir(path)), 0700)
+	if err != nil {
+		return err
+	}
+
+	if len(g.options.fileHooks) > 0 {
+		write, err = g.runFileHooks(path, write)
+		if err != nil {
+			return err
+		}
+	}
+
+	file, err := os.OpenFile(filepath.Join(g.outputDir, path),
+		os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	buffered := bufio.NewWriter(file)
+	err = write(buffered)
+	if err != nil {
+		return err
+	}
+	return buffered.Flush()
+}
+
+// staticFile reports whether the static directory has a file with the path.
+func (g StaticBlogGenerator) staticFile(path string) bool {
+	if g.options.staticDir == "" {
+		return false
+	}
+	info, err := os.Stat(filepath.Join(g.options.staticDir, path))
+	return err == nil && !info.IsDir()
+}
+
+// runFileHooks runs the FileHooks on the content written by the write
+// function to the file with the path. It returns a function writing the
+// processed content.
+func (g StaticBlogGenerator) runFileHooks(path string,
+	write func(w io.Writer) error) (func(w io.Writer) error, error) {
+	var buf bytes.Buffer
+	err := write(&buf)
 	if err != nil {
+		return nil, err
+	}
+
+	content := buf.Bytes()
+	for _, hook := range g.options.fileHooks {
+		content, err = hook(filepath.ToSlash(path), content)
+		if err != nil {
+			return nil, fmt.Errorf("failed to process %s: %s", filepath.ToSlash(path), err)
+		}
+	}
+	return func(w io.Writer) error {
+		_, err := w.Write(content)
 		return err
+	}, nil
+}
+
+// absURL returns the absolute URL of the path in the output directory.
+func (g StaticBlogGenerator) absURL(path string) string {
+	return strings.TrimSuffix(g.options.config.URL, "/") + "/" +
+		filepath.ToSlash(path)
+}
+
+// postPath returns the path of the Post's page in the output directory.
+func (g StaticBlogGenerator) postPath(post Post) string {
+	if post.Output != "" {
+		return filepath.FromSlash(post.Output)
+	}
+
+	name := g.slug(post.Title)
+	if !g.options.config.NestedURLs || post.Dir == "" {
+		return g.pagePath(name)
 	}
-	defer pageFile.Close()
 
-	return template.Execute(pageFile, data)
+	return g.pagePath(path.Join(g.slugDir(post.Dir), name))
+}
+
+// slugDir returns the directory (with slashes) with each of its parts
+// slugged.
+func (g StaticBlogGenerator) slugDir(dir string) string {
+	var parts []string
+	for _, part := range strings.Split(dir, "/") {
+		parts = append(parts, g.slug(part))
+	}
+	return path.Join(parts...)
+}
+
+func (g StaticBlogGenerator) tagPath(tag string) string {
+	return g.pagePath("tags/" + g.slug(tag))
+}
+
+// postURL returns the URL of the Post's page relative to the Blog's root.
+func (g StaticBlogGenerator) postURL(post Post) string {
+	return g.pageURL(g.postPath(post))
+}
+
+// tagURL returns the URL of the tag's page relative to the Blog's root.
+func (g StaticBlogGenerator) tagURL(tag string) string {
+	return g.pageURL(g.tagPath(tag))
+}
+
+// titleURL returns the URL the Post's title links to: the Link of linklog
+// entries, otherwise the URL of the Post's page.
+func (g StaticBlogGenerator) titleURL(post Post) string {
+	if post.Link != "" {
+		return post.Link
+	}
+	return g.postURL(post)
+}
+
+// archiveURL returns the URL of the archive page relative to the Blog's root.
+func (g StaticBlogGenerator) archiveURL() string {
+	return g.pageURL(g.pagePath("archive"))
 }
 
 // NewStaticBlogGenerator creates a StaticBlogGenerator that generates the Blog
 // to static HTML files in the outputDir using templates from the templatesDir.
 // It calls the progressFunc before generating each file.
 func NewStaticBlogGenerator(blog Blog, templatesDir, outputDir string,
-	progressFunc ProgressFunc) (StaticBlogGenerator, error) {
+	progressFunc ProgressFunc, opts ...Option) (StaticBlogGenerator, error) {
 	if _, err := os.Stat(templatesDir); err != nil {
 		return StaticBlogGenerator{},
 			fmt.Errorf("templates directory not found: %s", templatesDir)
 	}
 
-	indexTemplate, err := createTemplate(templatesDir, "index.tmpl")
+	g := StaticBlogGenerator{options: newOptions(opts),
+		templatesDir: templatesDir, outputDir: outputDir, progressFunc: progressFunc,
+		localized: map[string]string{}, thumbnails: map[string]image.Point{},
+		previous: map[string][32]byte{}}
+	defer g.options.profile.record("setup", time.Now())
+
+	err := g.validateTransformers()
+	if err != nil {
+		return StaticBlogGenerator{}, err
+	}
+
+	g.theme, err = g.themeParams()
+	if err != nil {
+		return StaticBlogGenerator{}, err
+	}
+
+	g.options.config.URLStyle, err = g.options.config.urlStyle()
+	if err != nil {
+		return StaticBlogGenerator{}, err
+	}
+	g.options.config.URL = g.options.config.siteURL()
+	if g.options.build.Time.IsZero() {
+		g.options.build = Build{Version: Version, Time: time.Now()}
+	}
+
+	now := time.Now()
+	var posts, generated Blog
+	for _, post := range blog.PostsByDate(false, g.options.preview) {
+		if g.options.config.ScheduledPosts && post.Written.After(now) {
+			if !g.options.preview {
+				continue
+			}
+			post.IsPreviewOnly = true
+		}
+		if post.Draft {
+			post.IsPreviewOnly = true
+		}
+
+		posts = append(posts, post)
+		if !post.expired(now) {
+			generated = append(generated, post)
+		}
+	}
+
+	posts, err = g.resolveWikiLinks(posts, generated)
+	if err != nil {
+		return StaticBlogGenerator{}, err
+	}
+
+	posts, err = g.transformPosts(posts)
+	if err != nil {
+		return StaticBlogGenerator{}, err
+	}
+
+	var listed Blog
+	for _, post := range posts {
+		if post.expired(now) {
+			g.expired = append(g.expired, post)
+		} else if post.Unlisted {
+			g.unlisted = append(g.unlisted, post)
+		} else {
+			listed = append(listed, post)
+		}
+	}
+	g.posts = listed
+
+	g.linkedFrom = g.computeBacklinks()
+
+	g.authors = listed.AuthorsWithPosts(true)
+	for i := range g.authors {
+		g.authors[i].Slug = g.slug(g.authors[i].Name)
+	}
+
+	g.tags = listed.tagsWithPosts(true, g.slug)
+	for i := range g.tags {
+		g.tags[i].Slug = g.slug(g.tags[i].Name)
+	}
+	for name, meta := range g.options.config.Tags {
+		for i := range g.tags {
+			if g.tags[i].Slug != g.slug(name) {
+				continue
+			}
+			if meta.Title != "" {
+				g.tags[i].Title = meta.Title
+			}
+			g.tags[i].Description = meta.Description
+		}
+	}
+
+	err = g.options.config.CommentSystem.validate()
+	if err != nil {
+		return StaticBlogGenerator{}, err
+	}
+
+	err = g.options.config.Slug.validate()
+	if err != nil {
+		return StaticBlogGenerator{}, err
+	}
+
+	err = g.options.config.Feeds.validate()
+	if err != nil {
+		return StaticBlogGenerator{}, err
+	}
+
+	err = g.checkDuplicateTitles()
+	if err != nil {
+		return StaticBlogGenerator{}, err
+	}
+
+	g.dates, err = newDateFormatter(g.options.config)
+	if err != nil {
+		return StaticBlogGenerator{}, err
+	}
+
+	g.indexTemplate, err = g.createTemplate("index.tmpl")
+	if err != nil {
+		return StaticBlogGenerator{}, err
+	}
+
+	g.postTemplate, err = g.createTemplate("post.tmpl")
+	if err != nil {
+		return StaticBlogGenerator{}, err
+	}
+
+	g.tagTemplate, err = g.createTemplate("tag.tmpl")
+	if err != nil {
+		return StaticBlogGenerator{}, err
+	}
+
+	g.tagTemplates = map[string]*template.Template{}
+	for _, tag := range g.tags {
+		override, err := g.createOptionalTemplate(tagTemplateFile(tag.Slug))
+		if err != nil {
+			return StaticBlogGenerator{}, err
+		}
+		if override != nil {
+			g.tagTemplates[tag.Slug] = override
+		}
+	}
+
+	g.tagsTemplate, err = g.createOptionalTemplate("tags.tmpl")
+	if err != nil {
+		return StaticBlogGenerator{}, err
+	}
+
+	err = g.checkTagPages()
+	if err != nil {
+		return StaticBlogGenerator{}, err
+	}
+
+	g.notFoundTemplate, err = g.createOptionalTemplate("404.tmpl")
+	if err != nil {
+		return StaticBlogGenerator{}, err
+	}
+
+	g.expiredTemplate, err = g.createOptionalTemplate("expired.tmpl")
 	if err != nil {
 		return StaticBlogGenerator{}, err
 	}
 
-	postTemplate, err := createTemplate(templatesDir, "post.tmpl")
+	g.authorTemplate, err = g.createOptionalTemplate("author.tmpl")
 	if err != nil {
 		return StaticBlogGenerator{}, err
 	}
 
-	tagTemplate, err := createTemplate(templatesDir, "tag.tmpl")
+	g.blogrollTemplate, err = g.createOptionalTemplate("blogroll.tmpl")
 	if err != nil {
 		return StaticBlogGenerator{}, err
 	}
 
-	posts := blog.PostsByDate(false, false)
+	g.archiveTemplate, err = g.createOptionalTemplate("archive.tmpl")
+	if err != nil {
+		return StaticBlogGenerator{}, err
+	}
+
+	g.linkTemplate, err = g.createOptionalTemplate("link.tmpl")
+	if err != nil {
+		return StaticBlogGenerator{}, err
+	}
+
+	// print pages are complete documents, so they aren't executed with the
+	// layout
+	g.printTemplate, err = g.createOptionalTemplate("print.tmpl")
+	if err != nil {
+		return StaticBlogGenerator{}, err
+	}
+	if g.printTemplate != nil {
+		g.printTemplate = g.printTemplate.Lookup("print.tmpl")
+	}
+
+	return g, nil
+}
+
+// tagTemplateFile returns the name of the template overriding the tag.tmpl for
+// the tag with the slug.
+func tagTemplateFile(slug string) string {
+	return "tag-" + slug + ".tmpl"
+}
+
+func (g StaticBlogGenerator) createTemplate(name string) (*template.Template, error) {
+	var t *template.Template
+	var err error
+	if g.options.templates != nil {
+		t, err = g.options.templates.template(g.templatesDir, name, g.funcs())
+	}
+	if t == nil && err == nil {
+		t, err = g.parseTemplate(name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return t.Option(g.options.config.missingKey()), nil
+}
+
+// parseTemplate parses the template with the layout. Templates are parsed only
+// if they changed since they were parsed last time (see templateCache).
+func (g StaticBlogGenerator) parseTemplate(name string) (*template.Template, error) {
+	layout, err := os.ReadFile(filepath.Join(g.templatesDir, "layout.tmpl"))
+	if err != nil {
+		return nil, err
+	}
 
-	postsByTag := map[string][]Post{}
-	for _, tag := range blog.Tags(false) {
-		postsByTag[tag] = blog.PostsByDate(false, false, tag)
+	page, err := os.ReadFile(filepath.Join(g.templatesDir, name))
+	if err != nil {
+		return nil, err
 	}
 
-	return StaticBlogGenerator{templatesDir, outputDir, progressFunc,
-		indexTemplate, postTemplate, tagTemplate, posts, postsByTag}, nil
+	return parsedTemplates.parse(layout, page, name, g.funcs())
+}
+
+// Site holds the Blog-wide data available to all templates via the site
+// function.
+type Site struct {
+	URL    string
+	Title  string
+	Author string
+
+	// Build holds metadata of the build generating the Blog.
+	Build Build
+
+	// PreviewMode tells whether it's a preview build (see WithPreview).
+	PreviewMode bool
+
+	// Theme holds the parameters of the theme (see ThemeConfig) keyed by
+	// their names, for example {{site.Theme.accentColor}}.
+	Theme map[string]interface{}
 }
 
-func createTemplate(dir, name string) (*template.Template, error) {
-	return template.New("layout.tmpl").Funcs(templateFuncs).ParseFiles(
-		filepath.Join(dir, "layout.tmpl"),
-		filepath.Join(dir, name))
+func (g StaticBlogGenerator) site() Site {
+	return Site{g.options.config.URL, g.options.config.Title,
+		g.options.config.Author, g.options.build, g.options.preview, g.theme}
+}
+
+// env returns the value of the environment variable with the name if it's
+// allowed by the Config's Env.
+func (g StaticBlogGenerator) env(name string) (string, error) {
+	for _, allowed := range g.options.config.Env {
+		if allowed == name {
+			return os.Getenv(name), nil
+		}
+	}
+	return "", fmt.Errorf("env: %s isn't allowed in the config's env", name)
+}
+
+// createOptionalTemplate creates the template only if its file exists,
+// otherwise it returns nil.
+func (g StaticBlogGenerator) createOptionalTemplate(name string) (*template.Template, error) {
+	if _, err := os.Stat(filepath.Join(g.templatesDir, name)); err != nil {
+		return nil, nil
+	}
+	return g.createTemplate(name)
+}
+
+// funcs returns the templateFuncs together with the functions that depend on
+// the generator's options.
+func (g StaticBlogGenerator) funcs() template.FuncMap {
+	funcs := template.FuncMap{}
+	for name, f := range templateFuncs {
+		funcs[name] = f
+	}
+	for name, f := range queryFuncs {
+		funcs[name] = f
+	}
+	funcs["html"] = g.htmlFunc
+	funcs["markdownInline"] = g.markdownInline
+	funcs["summary"] = g.summary
+	funcs["description"] = g.description
+	funcs["formatDate"] = g.formatDate
+	funcs["formatYear"] = g.formatYear
+	funcs["tags"] = func() []Tag { return g.tags }
+	funcs["site"] = g.site
+	funcs["postURL"] = g.postURL
+	funcs["titleURL"] = g.titleURL
+	funcs["tagURL"] = g.tagURL
+	funcs["archiveURL"] = g.archiveURL
+	funcs["tagFeedURL"] = g.tagFeedURL
+	funcs["authorFeedURL"] = g.authorFeedURL
+	funcs["sectionFeedURL"] = g.sectionFeedURL
+	funcs["printURL"] = g.printURL
+	funcs["printHTML"] = g.printHTML
+	funcs["feeds"] = g.feeds
+	funcs["headLinks"] = g.headLinks
+	funcs["authors"] = func() []Author { return g.authors }
+	funcs["authorURL"] = g.authorURL
+	funcs["blogroll"] = func() Blogroll { return g.options.blogroll }
+	funcs["slug"] = g.slug
+	funcs["env"] = g.env
+	funcs["age"] = g.age
+	funcs["pwa"] = g.pwaFunc
+	funcs["backlinks"] = g.backlinks
+	funcs["heatmap"] = g.heatmap
+	funcs["archived"] = g.archived
+	funcs["commentSystem"] = g.commentSystem
+	for name, f := range g.pluginFuncs() {
+		funcs[name] = f
+	}
+	return funcs
 }
 
 var templateFuncs = template.FuncMap{
-	"html":       html,
-	"summary":    summary,
-	"even":       even,
-	"inc":        inc,
-	"slug":       slugify,
-	"formatDate": formatDate,
-	"formatYear": formatYear,
+	"summary":       summary,
+	"even":          even,
+	"inc":           inc,
+	"truncate":      truncate,
+	"pluralize":     pluralize,
+	"wordCount":     wordCount,
+	"readingTime":   readingTime,
+	"yearlyFeedURL": yearlyFeedURL,
+}
+
+// htmlFunc renders a Post's Content (according to its Format) or a Markdown
+// string to post-processed HTML.
+func (g StaticBlogGenerator) htmlFunc(postOrMarkdown interface{}) (template.HTML, error) {
+	switch v := postOrMarkdown.(type) {
+	case Post:
+		return g.render(v)
+	case string:
+		return template.HTML(g.postProcess(string(html(v)))), nil
+	}
+	return "", fmt.Errorf("html: want a post or a string, got %T", postOrMarkdown)
+}
+
+// markdownInline renders a Markdown string to post-processed HTML without the
+// paragraph wrapping it, for example for titles with emphasis or code spans.
+// Markdown with more paragraphs (or other blocks) is rendered unchanged.
+func (g StaticBlogGenerator) markdownInline(markdown string) template.HTML {
+	rendered := strings.TrimSpace(string(html(markdown)))
+	inner := strings.TrimSuffix(strings.TrimPrefix(rendered, "<p>"), "</p>")
+	if len(inner) == len(rendered)-len("<p></p>") && !strings.Contains(inner, "<p>") {
+		rendered = inner
+	}
+	return template.HTML(g.postProcess(rendered))
+}
+
+// renderPost renders the Post's Content to HTML.
+func renderPost(post Post) template.HTML {
+	if post.Format == FormatHTML {
+		return template.HTML(post.Content)
+	}
+	return html(post.Content)
 }
 
 func html(markdown string) template.HTML {
@@ -174,7 +840,100 @@ func html(markdown string) template.HTML {
 	return template.HTML(html)
 }
 
+// summary returns the Summary of a Post or the summary of a Markdown string.
+// Summaries that aren't explicitly marked are truncated to the configured
+// SummaryLength.
+func (g StaticBlogGenerator) summary(postOrMarkdown interface{}) (string, error) {
+	return g.summaryOfLength(postOrMarkdown, g.options.config.SummaryLength)
+}
+
+// summaryOfLength returns the summary of a Post or a Markdown string like
+// summary, but truncated to the maximum number of words (no limit if it's 0).
+func (g StaticBlogGenerator) summaryOfLength(postOrMarkdown interface{}, length int) (string, error) {
+	var markdown string
+	switch v := postOrMarkdown.(type) {
+	case Post:
+		// summaries of password protected Posts aren't encrypted, so
+		// they're never shown
+		if v.Password != "" {
+			return protectedSummary, nil
+		}
+		if v.Summary != "" {
+			return v.Summary, nil
+		}
+		if v.Format == FormatHTML {
+			return htmlSummary(v.Content), nil
+		}
+		markdown = v.Content
+	case string:
+		markdown = v
+	default:
+		return "", fmt.Errorf("summary: want a post or a string, got %T", v)
+	}
+
+	if strings.Contains(markdown, moreMarker) {
+		return summary(markdown), nil
+	}
+	return truncateWords(summary(markdown), length), nil
+}
+
+// description returns the Description of a Post or, if it's empty, the
+// Post's summary as plain text.
+func (g StaticBlogGenerator) description(post Post) (string, error) {
+	return g.descriptionOfLength(post, g.options.config.SummaryLength)
+}
+
+// descriptionOfLength returns the description of a Post like description, but
+// with the summary truncated to the maximum number of words.
+func (g StaticBlogGenerator) descriptionOfLength(post Post, length int) (string, error) {
+	if post.Description != "" {
+		return post.Description, nil
+	}
+
+	summary, err := g.summaryOfLength(post, length)
+	if err != nil {
+		return "", err
+	}
+	return plainText(summary), nil
+}
+
+var (
+	htmlTagRegexp       = regexp.MustCompile(`<[^>]*>`)
+	htmlParagraphRegexp = regexp.MustCompile(`(?s)<p>.*?</p>`)
+)
+
+// plainText converts a Markdown string to plain text on a single line.
+func plainText(markdown string) string {
+	return strings.Join(strings.Fields(stripTags(string(html(markdown)))), " ")
+}
+
+// stripTags removes tags from an HTML string and unescapes its entities.
+func stripTags(html string) string {
+	return stdhtml.UnescapeString(htmlTagRegexp.ReplaceAllString(html, ""))
+}
+
+// htmlSummary returns the part of an HTML string before the moreMarker or, if
+// there's no marker, its first paragraph.
+func htmlSummary(html string) string {
+	if i := strings.Index(html, moreMarker); i != -1 {
+		return strings.TrimSpace(html[:i])
+	}
+	if p := htmlParagraphRegexp.FindString(html); p != "" {
+		return p
+	}
+	return html
+}
+
+// moreMarker separates the summary from the rest of a Markdown string.
+const moreMarker = "<!--more-->"
+
+// summary returns the part of a Markdown string before the moreMarker or, if
+// there's no marker, the first paragraph that isn't a header.
 func summary(content string) string {
+	if i := strings.Index(content, moreMarker); i != -1 {
+		return strings.TrimSpace(content[:i])
+	}
+
 	lines := strings.Split(content, "\n\n")
 	for _, line := range lines {
 		if !strings.HasPrefix(line, "#") {
@@ -184,6 +943,16 @@ func summary(content string) string {
 	return content
 }
 
+// truncateWords shortens a string to at most n words followed by an ellipsis.
+// If n <= 0 the string is returned unchanged.
+func truncateWords(str string, n int) string {
+	words := strings.Fields(str)
+	if n <= 0 || len(words) <= n {
+		return str
+	}
+	return strings.Join(words[:n], " ") + "…"
+}
+
 func even(integer int) bool {
 	return integer%2 == 0
 }
@@ -192,15 +961,98 @@ func inc(integer int) int {
 	return integer + 1
 }
 
-func slugify(str string) string {
-	return slug.Make(str)
+// truncate shortens a string to at most n runes (without the ellipsis) and
+// appends an ellipsis if it's shortened. The options before the string are
+// "words" to cut it at a word boundary and a custom ellipsis ("…" by
+// default), for example {{truncate 100 "words" "..." .Description}}.
+func truncate(n int, args ...string) (string, error) {
+	if len(args) == 0 || len(args) > 3 {
+		return "", fmt.Errorf("truncate: want a string and at most 2 options, got %d arguments",
+			len(args))
+	}
+
+	s := args[len(args)-1]
+	words, ellipsis := false, "…"
+	for _, option := range args[:len(args)-1] {
+		if option == "words" {
+			words = true
+		} else {
+			ellipsis = option
+		}
+	}
+
+	runes := []rune(s)
+	if n < 0 || len(runes) <= n {
+		return s, nil
+	}
+
+	cut := runes[:n]
+	if words && !unicode.IsSpace(runes[n]) {
+		if i := strings.LastIndexFunc(string(cut), unicode.IsSpace); i != -1 {
+			cut = []rune(string(cut)[:i])
+		}
+	}
+	return strings.TrimRightFunc(string(cut), func(r rune) bool {
+		return unicode.IsSpace(r) || unicode.IsPunct(r)
+	}) + ellipsis, nil
+}
+
+// pluralize returns the number with the singular or the plural noun, for
+// example {{pluralize .Count "post" "posts"}} returns "1 post" or "3 posts".
+func pluralize(n int, singular, plural string) string {
+	if n == 1 || n == -1 {
+		return fmt.Sprintf("%d %s", n, singular)
+	}
+	return fmt.Sprintf("%d %s", n, plural)
+}
+
+// formatDate formats a date using the layout (if provided) or the configured
+// DateFormat, for example {{formatDate .Written "2 January 2006"}}.
+func (g StaticBlogGenerator) formatDate(t time.Time, layout ...string) (string, error) {
+	if len(layout) > 1 {
+		return "", fmt.Errorf("formatDate: want at most 1 layout, got %d",
+			len(layout))
+	}
+	if len(layout) == 1 {
+		return g.dates.format(t, layout[0]), nil
+	}
+	return g.dates.format(t, ""), nil
+}
+
+// wordsPerMinute is the average reading speed used by readingTime.
+const wordsPerMinute = 200
+
+// wordCount counts the words in a Markdown string, ignoring standalone
+// Markdown markup like "#", "*" or "-".
+func wordCount(markdown string) int {
+	count := 0
+	for _, field := range strings.Fields(markdown) {
+		if strings.IndexFunc(field, isWordRune) != -1 {
+			count++
+		}
+	}
+	return count
+}
+
+// readingTime returns the number of minutes (at least 1) needed to read
+// a Markdown string.
+func readingTime(markdown string) int {
+	return minutesToRead(wordCount(markdown))
+}
+
+func minutesToRead(words int) int {
+	minutes := (words + wordsPerMinute - 1) / wordsPerMinute
+	if minutes < 1 {
+		return 1
+	}
+	return minutes
 }
 
-func formatDate(t time.Time) string {
-	return t.Format("02 Jan, 2006")
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
 }
 
 // formatYear extracts the year from a time.Time object
-func formatYear(t time.Time) string {
-	return t.Format("2006") // "2006" is the Go time layout for year
+func (g StaticBlogGenerator) formatYear(t time.Time) string {
+	return g.dates.format(t, "2006") // "2006" is the Go time layout for year
 }
@@ -0,0 +1,542 @@
+package lib
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWordCount(t *testing.T) {
+	md := "# A title\n\nSome *emphasized* words - and `code`.\n"
+
+	if count := wordCount(md); count != 7 {
+		t.Errorf("want %v, got %v", 7, count)
+	}
+}
+
+func TestReadingTime(t *testing.T) {
+	if minutes := readingTime(""); minutes != 1 {
+		t.Errorf("want %v, got %v", 1, minutes)
+	}
+
+	md := ""
+	for i := 0; i < 450; i++ {
+		md += "word "
+	}
+	if minutes := readingTime(md); minutes != 3 {
+		t.Errorf("want %v, got %v", 3, minutes)
+	}
+}
+
+func TestSummaryWithMoreMarker(t *testing.T) {
+	md := "# A title\n\nFirst paragraph.\n\nSecond paragraph.\n\n<!--more-->\n\nThe rest.\n"
+
+	want := "# A title\n\nFirst paragraph.\n\nSecond paragraph."
+	if s := summary(md); s != want {
+		t.Errorf("want %q, got %q", want, s)
+	}
+}
+
+func TestDescription(t *testing.T) {
+	g := StaticBlogGenerator{options: options{config: Config{SummaryLength: 3}}}
+
+	for _, test := range []struct {
+		post Post
+		want string
+	}{
+		{Post{Description: "Rome in *one* day", Content: "Rome is the capital of Italy."},
+			"Rome in *one* day"},
+		// the summary is split at the marker and isn't truncated
+		{Post{Content: "Rome is *the* capital.\n\nOf Italy.\n\n<!--more-->\n\nThe rest."},
+			"Rome is the capital. Of Italy."},
+		// without the marker it's the first paragraph truncated
+		{Post{Content: "Rome is the capital of Italy.\n\nThe rest."}, "Rome is the…"},
+		{Post{Format: FormatHTML, Content: "<p>Rome is the capital.</p><!--more--><p>The rest.</p>"},
+			"Rome is the capital."},
+		// HTML summaries aren't truncated, so their markup isn't broken
+		{Post{Format: FormatHTML, Content: "<p>Rome is the capital of Italy.</p><p>The rest.</p>"},
+			"Rome is the capital of Italy."},
+	} {
+		description, err := g.description(test.post)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if description != test.want {
+			t.Errorf("want %q, got %q", test.want, description)
+		}
+	}
+}
+
+func TestSummaryTruncated(t *testing.T) {
+	g := StaticBlogGenerator{options: options{config: Config{SummaryLength: 3}}}
+
+	s, err := g.summary("One two three four five.")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := "One two three…"; s != want {
+		t.Errorf("want %q, got %q", want, s)
+	}
+}
+
+func TestMarkdownInline(t *testing.T) {
+	g := StaticBlogGenerator{}
+
+	for markdown, want := range map[string]string{
+		"Why *Go* uses `nil`": "Why <em>Go</em> uses <code>nil</code>",
+		"One\n\nTwo":          "<p>One</p>\n\n<p>Two</p>",
+		"":                    "",
+	} {
+		if html := g.markdownInline(markdown); string(html) != want {
+			t.Errorf("want %q, got %q", want, html)
+		}
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	for _, test := range []struct {
+		n    int
+		args []string
+		want string
+	}{
+		{8, []string{"Rome and Paris"}, "Rome and…"},
+		{10, []string{"Short"}, "Short"},
+		{7, []string{"words", "Rome and Paris"}, "Rome…"},
+		{7, []string{"words", "...", "Řím, Paříž"}, "Řím..."},
+		{8, []string{"...", "Řím a Paříž"}, "Řím a Pa..."},
+	} {
+		s, err := truncate(test.n, test.args...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s != test.want {
+			t.Errorf("want %q, got %q", test.want, s)
+		}
+	}
+
+	if _, err := truncate(10); err == nil {
+		t.Errorf("want an error, got nil")
+	}
+}
+
+func TestPluralize(t *testing.T) {
+	for n, want := range map[int]string{0: "0 posts", 1: "1 post", 2: "2 posts"} {
+		if s := pluralize(n, "post", "posts"); s != want {
+			t.Errorf("want %q, got %q", want, s)
+		}
+	}
+}
+
+func TestBacklinks(t *testing.T) {
+	g := StaticBlogGenerator{options: options{config: Config{URL: "https://example.com"}}}
+	g.posts = []Post{
+		{Title: "Rome", Content: "See [Paris](/paris.html) and [Rome](rome.html)."},
+		{Title: "Paris", Content: "Back to [Rome](https://example.com/rome.html#top)."},
+		{Title: "London", Content: "Go to [Paris](paris.html), [Paris](/paris.html) or [Oslo](/oslo.html)."},
+	}
+	g.linkedFrom = g.computeBacklinks()
+
+	backlinks := g.backlinks(g.posts[1])
+	if len(backlinks) != 2 || backlinks[0].Title != "Rome" || backlinks[1].Title != "London" {
+		t.Errorf("want %v, got %v", "[Rome London]", backlinks)
+	}
+
+	backlinks = g.backlinks(g.posts[0])
+	if len(backlinks) != 1 || backlinks[0].Title != "Paris" {
+		t.Errorf("want %v, got %v", "[Paris]", backlinks)
+	}
+}
+
+func TestInternalURL(t *testing.T) {
+	g := StaticBlogGenerator{options: options{config: Config{URL: "https://example.com/blog"}}}
+
+	for href, want := range map[string]string{
+		"https://example.com/blog/rome.html": "/rome.html",
+		"https://example.com/blog":           "/",
+		"https://example.com/blog#top":       "/",
+		"https://example.com/blog-archive/x": "",
+		"https://example.com/blogroll.html":  "",
+		"https://example.org/blog/rome.html": "",
+		"paris.html":                         "/notes/paris.html",
+		"/paris.html":                        "/paris.html",
+	} {
+		if got := g.internalURL("/notes/rome.html", href); got != want {
+			t.Errorf("%s: want %q, got %q", href, want, got)
+		}
+	}
+}
+
+func TestCommentSystem(t *testing.T) {
+	g := StaticBlogGenerator{options: options{config: Config{
+		CommentSystem: CommentSystem{Name: CommentSystemGiscus, Repo: "johndoe/blog"}}}}
+
+	if system := g.commentSystem(Post{}); system == nil || system.Repo != "johndoe/blog" {
+		t.Errorf("want %v, got %v", "johndoe/blog", system)
+	}
+	if system := g.commentSystem(Post{CommentsDisabled: true}); system != nil {
+		t.Errorf("want %v, got %v", nil, system)
+	}
+	if system := (StaticBlogGenerator{}).commentSystem(Post{}); system != nil {
+		t.Errorf("want %v, got %v", nil, system)
+	}
+
+	if err := (CommentSystem{Name: "disqus"}).validate(); err == nil {
+		t.Errorf("want an error, got nil")
+	}
+}
+
+// writeTestTemplates writes minimal templates listing Post titles to a
+// temporary directory and returns it.
+func writeTestTemplates(t *testing.T) string {
+	templates := t.TempDir()
+	for name, content := range map[string]string{
+		"layout.tmpl": `{{template "content" .}}`,
+		"index.tmpl":  `{{define "content"}}{{range .}}{{.Title}};{{end}}{{end}}`,
+		"post.tmpl":   `{{define "content"}}{{.Title}}{{end}}`,
+		"tag.tmpl":    `{{define "content"}}{{range .Posts}}{{.Title}};{{end}}{{end}}`,
+	} {
+		os.WriteFile(filepath.Join(templates, name), []byte(content), 0600)
+	}
+	return templates
+}
+
+func TestGenerateNotFoundPage(t *testing.T) {
+	templates := writeTestTemplates(t)
+	blog := Blog{{Title: "Rome", Written: time.Now()}}
+
+	// without 404.tmpl no 404 page is generated
+	output := t.TempDir()
+	g, err := NewStaticBlogGenerator(blog, templates, output, func(string) {})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Generate(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(output, "404.html")); !os.IsNotExist(err) {
+		t.Errorf("want %v, got %v", "no 404.html", err)
+	}
+
+	os.WriteFile(filepath.Join(templates, "404.tmpl"),
+		[]byte(`{{define "content"}}Not found. Try {{range .}}{{.Title}}{{end}}.{{end}}`), 0600)
+	output = t.TempDir()
+	g, err = NewStaticBlogGenerator(blog, templates, output, func(string) {})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Generate(); err != nil {
+		t.Fatal(err)
+	}
+	if page, _ := os.ReadFile(filepath.Join(output, "404.html")); string(page) != "Not found. Try Rome." {
+		t.Errorf("want %v, got %s", "Not found. Try Rome.", page)
+	}
+	if _, err := os.Stat(filepath.Join(output, "404.tmpl")); err == nil {
+		t.Errorf("want %v, got %v", "404.tmpl not copied", "404.tmpl")
+	}
+}
+
+func TestGenerateUnlistedPosts(t *testing.T) {
+	templates := writeTestTemplates(t)
+	blog := Blog{
+		{Title: "Rome", Tags: []string{"cities"}, Written: time.Now()},
+		{Title: "Paris", Tags: []string{"cities"}, Written: time.Now(), Unlisted: true},
+	}
+
+	output := t.TempDir()
+	g, err := NewStaticBlogGenerator(blog, templates, output, func(string) {})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Generate(); err != nil {
+		t.Fatal(err)
+	}
+
+	for path, want := range map[string]string{
+		"index.html":       "Rome;",
+		"tags/cities.html": "Rome;",
+		"paris.html":       "Paris",
+	} {
+		bytes, err := os.ReadFile(filepath.Join(output, path))
+		if err != nil || string(bytes) != want {
+			t.Errorf("%s: want %q, got %q (%v)", path, want, bytes, err)
+		}
+	}
+}
+
+func TestGenerateIndexExclusions(t *testing.T) {
+	templates := writeTestTemplates(t)
+	now := time.Now()
+	blog := Blog{
+		{Title: "Rome", Tags: []string{"cities"}, Written: now},
+		{Title: "Note", Tags: []string{"Notes"}, Written: now.Add(-time.Hour)},
+		{Title: "Til", Dir: "til/go", Written: now.Add(-2 * time.Hour)},
+	}
+
+	output := t.TempDir()
+	g, err := NewStaticBlogGenerator(blog, templates, output, func(string) {},
+		WithConfig(Config{URL: "https://example.com",
+			Index: IndexConfig{ExcludeTags: []string{"notes"}, ExcludeDirs: []string{"/til/"}}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Generate(); err != nil {
+		t.Fatal(err)
+	}
+
+	for path, want := range map[string]string{
+		"index.html":      "Rome;",
+		"tags/notes.html": "Note;",
+	} {
+		bytes, err := os.ReadFile(filepath.Join(output, path))
+		if err != nil || string(bytes) != want {
+			t.Errorf("%s: want %q, got %q (%v)", path, want, bytes, err)
+		}
+	}
+
+	feed, _ := os.ReadFile(filepath.Join(output, "atom.xml"))
+	if !strings.Contains(string(feed), "Note") || !strings.Contains(string(feed), "Til") {
+		t.Errorf("want %v and %v in the feed, got %s", "Note", "Til", feed)
+	}
+}
+
+func TestGenerateIndexPostsByYear(t *testing.T) {
+	templates := writeTestTemplates(t)
+	os.WriteFile(filepath.Join(templates, "index.tmpl"), []byte(`{{define "content"}}`+
+		`{{range .PostsByYear}}{{.Key}}:{{range .Posts}}{{.Title}};{{end}}{{end}}`+
+		`{{range limit 1 .}}{{.Title}}{{end}}{{end}}`), 0600)
+	blog := Blog{
+		{Title: "Rome", Written: time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC)},
+		{Title: "Paris", Written: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{Title: "Oslo", Written: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	output := t.TempDir()
+	g, err := NewStaticBlogGenerator(blog, templates, output, func(string) {})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Generate(); err != nil {
+		t.Fatal(err)
+	}
+
+	bytes, _ := os.ReadFile(filepath.Join(output, "index.html"))
+	if want := "2024:Oslo;Paris;2023:Rome;Oslo"; string(bytes) != want {
+		t.Errorf("want %q, got %q", want, bytes)
+	}
+}
+
+func TestGenerateExpiredPosts(t *testing.T) {
+	templates := writeTestTemplates(t)
+	blog := Blog{
+		{Title: "Rome", Written: time.Now()},
+		{Title: "Paris", Written: time.Now(), Expires: time.Now().Add(-time.Hour)},
+		{Title: "London", Written: time.Now(), Expires: time.Now().Add(time.Hour)},
+	}
+
+	output := t.TempDir()
+	g, err := NewStaticBlogGenerator(blog, templates, output, func(string) {})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Generate(); err != nil {
+		t.Fatal(err)
+	}
+
+	bytes, _ := os.ReadFile(filepath.Join(output, "index.html"))
+	if string(bytes) != "Rome;London;" && string(bytes) != "London;Rome;" {
+		t.Errorf("want %q, got %q", "Rome;London;", bytes)
+	}
+	if _, err := os.Stat(filepath.Join(output, "paris.html")); err == nil {
+		t.Errorf("want no page of the expired post")
+	}
+
+	g, _ = NewStaticBlogGenerator(blog, templates, output, func(string) {},
+		WithConfig(Config{Tombstones: true}))
+	if err := g.Generate(); err != nil {
+		t.Fatal(err)
+	}
+	if bytes, _ := os.ReadFile(filepath.Join(output, "paris.html")); !strings.Contains(string(bytes), "expired") {
+		t.Errorf("want a tombstone page, got %q", bytes)
+	}
+}
+
+func TestEnv(t *testing.T) {
+	templates := writeTestTemplates(t)
+	os.WriteFile(filepath.Join(templates, "index.tmpl"), []byte(
+		`{{define "content"}}{{env "ANALYTICS_ID"}}{{if env "SHOW_BANNER"}} banner{{end}}{{end}}`), 0600)
+	t.Setenv("ANALYTICS_ID", "UA-1")
+	t.Setenv("SHOW_BANNER", "")
+
+	output := t.TempDir()
+	g, err := NewStaticBlogGenerator(Blog{}, templates, output, func(string) {},
+		WithConfig(Config{Env: []string{"ANALYTICS_ID", "SHOW_BANNER"}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Generate(); err != nil {
+		t.Fatal(err)
+	}
+	bytes, _ := os.ReadFile(filepath.Join(output, "index.html"))
+	if got, want := string(bytes), "UA-1"; got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+
+	g, err = NewStaticBlogGenerator(Blog{}, templates, t.TempDir(), func(string) {},
+		WithConfig(Config{Env: []string{"ANALYTICS_ID"}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Generate(); err == nil {
+		t.Errorf("want %v, got %v", "an error", err)
+	}
+}
+
+func TestPreview(t *testing.T) {
+	templates := writeTestTemplates(t)
+	os.WriteFile(filepath.Join(templates, "post.tmpl"), []byte(
+		`{{define "content"}}{{if .IsPreviewOnly}}Draft: {{end}}{{.Title}}{{if site.PreviewMode}} (preview){{end}}{{end}}`), 0600)
+	blog := Blog{
+		{Title: "Rome", Written: time.Now().Add(-time.Hour)},
+		{Title: "Milan", Written: time.Now().Add(-time.Hour), Draft: true},
+		{Title: "Turin", Written: time.Now().Add(24 * time.Hour)},
+	}
+
+	for _, preview := range []bool{false, true} {
+		opts := []Option{WithConfig(Config{ScheduledPosts: true})}
+		if preview {
+			opts = append(opts, WithPreview())
+		}
+
+		output := t.TempDir()
+		g, err := NewStaticBlogGenerator(blog, templates, output, func(string) {}, opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := g.Generate(); err != nil {
+			t.Fatal(err)
+		}
+
+		want := map[string]string{"rome.html": "Rome"}
+		if preview {
+			want = map[string]string{"rome.html": "Rome (preview)",
+				"milan.html": "Draft: Milan (preview)", "turin.html": "Draft: Turin (preview)"}
+		}
+		for _, file := range []string{"rome.html", "milan.html", "turin.html"} {
+			bytes, _ := os.ReadFile(filepath.Join(output, file))
+			if got := string(bytes); got != want[file] {
+				t.Errorf("want %q, got %q", want[file], got)
+			}
+		}
+	}
+}
+
+func TestTagTemplates(t *testing.T) {
+	templates := writeTestTemplates(t)
+	os.WriteFile(filepath.Join(templates, "tag-photos.tmpl"), []byte(
+		`{{define "content"}}grid:{{range .Posts}}{{.Title}};{{end}}{{end}}`), 0600)
+	blog := Blog{{Title: "Rome", Tags: []string{"Photos", "cities"}, Written: time.Now()}}
+
+	output := t.TempDir()
+	g, err := NewStaticBlogGenerator(blog, templates, output, func(string) {})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Generate(); err != nil {
+		t.Fatal(err)
+	}
+
+	for page, want := range map[string]string{"photos.html": "grid:Rome;", "cities.html": "Rome;"} {
+		bytes, _ := os.ReadFile(filepath.Join(output, "tags", page))
+		if string(bytes) != want {
+			t.Errorf("want %q, got %q", want, bytes)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(output, "tag-photos.tmpl")); err == nil {
+		t.Errorf("want %v, got %v", "the template not copied", "tag-photos.tmpl")
+	}
+}
+
+func TestArchive(t *testing.T) {
+	templates := writeTestTemplates(t)
+	os.WriteFile(filepath.Join(templates, "archive.tmpl"), []byte(`{{define "content"}}`+
+		`{{range groupByYear .}}<a href="{{yearlyFeedURL .Key}}">{{.Key}}</a>{{range .Posts}}{{.Title}};{{end}}{{end}}{{end}}`), 0600)
+	os.WriteFile(filepath.Join(templates, "index.tmpl"), []byte(`{{define "content"}}{{archiveURL}}{{end}}`), 0600)
+	blog := Blog{
+		{Title: "Rome", Written: time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC)},
+		{Title: "Paris", Written: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{Title: "Oslo", Written: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	output := t.TempDir()
+	g, err := NewStaticBlogGenerator(blog, templates, output, func(string) {},
+		WithConfig(Config{URL: "https://example.com", Title: "Cities", YearlyFeeds: true}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Generate(); err != nil {
+		t.Fatal(err)
+	}
+
+	bytes, _ := os.ReadFile(filepath.Join(output, "archive.html"))
+	want := `<a href="/atom-2024.xml">2024</a>Oslo;Paris;<a href="/atom-2023.xml">2023</a>Rome;`
+	if string(bytes) != want {
+		t.Errorf("want %q, got %q", want, bytes)
+	}
+	bytes, _ = os.ReadFile(filepath.Join(output, "index.html"))
+	if string(bytes) != "/archive.html" {
+		t.Errorf("want %v, got %s", "/archive.html", bytes)
+	}
+
+	bytes, _ = os.ReadFile(filepath.Join(output, "atom-2024.xml"))
+	feed := string(bytes)
+	if !strings.Contains(feed, "<title>Cities (2024)</title>") || !strings.Contains(feed, "Oslo") ||
+		strings.Contains(feed, "Rome") {
+		t.Errorf("want %v, got %s", "the 2024 feed", feed)
+	}
+	if _, err := os.Stat(filepath.Join(output, "atom-2023.xml")); err != nil {
+		t.Errorf("want %v, got %v", "the 2023 feed", err)
+	}
+}
+
+func TestLinklog(t *testing.T) {
+	templates := writeTestTemplates(t)
+	os.WriteFile(filepath.Join(templates, "index.tmpl"), []byte(
+		`{{define "content"}}{{range .}}{{titleURL .}};{{end}}{{end}}`), 0600)
+	os.WriteFile(filepath.Join(templates, "link.tmpl"), []byte(
+		`{{define "content"}}<a href="{{.Link}}">{{.Title}}</a>{{end}}`), 0600)
+	blog := Blog{
+		{Title: "The Go Blog", Link: "https://go.dev/blog/", Written: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{Title: "Rome", Written: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	output := t.TempDir()
+	g, err := NewStaticBlogGenerator(blog, templates, output, func(string) {},
+		WithConfig(Config{URL: "https://example.com"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Generate(); err != nil {
+		t.Fatal(err)
+	}
+
+	for page, want := range map[string]string{
+		"index.html":       "https://go.dev/blog/;/rome.html;",
+		"the-go-blog.html": `<a href="https://go.dev/blog/">The Go Blog</a>`,
+		"rome.html":        "Rome",
+	} {
+		bytes, _ := os.ReadFile(filepath.Join(output, page))
+		if string(bytes) != want {
+			t.Errorf("want %q, got %q", want, bytes)
+		}
+	}
+
+	bytes, _ := os.ReadFile(filepath.Join(output, "atom.xml"))
+	want := `<link href="https://go.dev/blog/"></link>
+    <link href="https://example.com/the-go-blog.html" rel="related"></link>`
+	if !strings.Contains(string(bytes), want) {
+		t.Errorf("want %v, got %s", want, bytes)
+	}
+}
@@ -0,0 +1,63 @@
+package lib
+
+import (
+	"image"
+	_ "image/gif"  // for measuring GIF images
+	_ "image/jpeg" // for measuring JPEG images
+	_ "image/png"  // for measuring PNG images
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// rewriteImages adds the loading="lazy" and decoding="async" attributes to
+// images in rendered Posts together with their measured width and height
+// (if the images are in the output directory). Existing attributes are kept.
+func (g StaticBlogGenerator) rewriteImages(html string) string {
+	if !g.options.config.LazyImages {
+		return html
+	}
+
+	return rewriteStartTags(html, "img", func(attrs *attributes) {
+		if _, ok := attrs.get("loading"); !ok {
+			attrs.set("loading", "lazy")
+		}
+		if _, ok := attrs.get("decoding"); !ok {
+			attrs.set("decoding", "async")
+		}
+
+		_, hasWidth := attrs.get("width")
+		_, hasHeight := attrs.get("height")
+		if hasWidth || hasHeight {
+			return
+		}
+
+		src, _ := attrs.get("src")
+		width, height, ok := g.imageSize(src)
+		if ok {
+			attrs.set("width", strconv.Itoa(width))
+			attrs.set("height", strconv.Itoa(height))
+		}
+	})
+}
+
+// imageSize returns the dimensions of the image at the URL (relative to the
+// Blog's root) in the output directory.
+func (g StaticBlogGenerator) imageSize(src string) (int, int, bool) {
+	url := g.internalURL("/", src)
+	if url == "" {
+		return 0, 0, false
+	}
+
+	file, err := os.Open(filepath.Join(g.outputDir, filepath.FromSlash(url)))
+	if err != nil {
+		return 0, 0, false
+	}
+	defer file.Close()
+
+	config, _, err := image.DecodeConfig(file)
+	if err != nil {
+		return 0, 0, false
+	}
+	return config.Width, config.Height, true
+}
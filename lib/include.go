@@ -0,0 +1,61 @@
+package lib
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// maxIncludeDepth limits nested includes to stop include cycles.
+const maxIncludeDepth = 10
+
+var includeRegexp = regexp.MustCompile(`\{\{\s*include\s+"([^"]+)"\s*\}\}`)
+
+// resolveIncludes replaces {{include "path"}} directives in the source with
+// the contents of the files at the paths (relative to the Blog directory).
+// Included files can include other files.
+func (b MarkdownBlog) resolveIncludes(source string, depth int) (string, error) {
+	if depth > maxIncludeDepth {
+		return "", fmt.Errorf("includes are nested too deep (an include cycle?)")
+	}
+
+	var resolveErr error
+	resolved := includeRegexp.ReplaceAllStringFunc(source, func(directive string) string {
+		if resolveErr != nil {
+			return directive
+		}
+
+		path := includeRegexp.FindStringSubmatch(directive)[1]
+		content, err := b.readInclude(path)
+		if err != nil {
+			resolveErr = err
+			return directive
+		}
+
+		content, err = b.resolveIncludes(content, depth+1)
+		if err != nil {
+			resolveErr = err
+			return directive
+		}
+		return strings.TrimSuffix(content, "\n")
+	})
+
+	return resolved, resolveErr
+}
+
+func (b MarkdownBlog) readInclude(path string) (string, error) {
+	full := filepath.Join(b.dir, filepath.FromSlash(path))
+
+	rel, err := filepath.Rel(b.dir, full)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("include outside the blog directory: %s", path)
+	}
+
+	content, err := os.ReadFile(full)
+	if err != nil {
+		return "", fmt.Errorf("failed to include file: %s", err)
+	}
+	return strings.ReplaceAll(string(content), "\r\n", "\n"), nil
+}
@@ -0,0 +1,29 @@
+package lib
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolveIncludes(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "snippets"), 0700)
+	os.WriteFile(filepath.Join(dir, "snippets", "bio.md"), []byte("*Bio* {{include \"snippets/name.md\"}}\n"), 0600)
+	os.WriteFile(filepath.Join(dir, "snippets", "name.md"), []byte("Jane\n"), 0600)
+
+	b := MarkdownBlog{dir: dir}
+	resolved, err := b.resolveIncludes("Before\n\n{{include \"snippets/bio.md\"}}\n\nAfter", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := "Before\n\n*Bio* Jane\n\nAfter"; resolved != want {
+		t.Errorf("want %q, got %q", want, resolved)
+	}
+
+	_, err = b.resolveIncludes("{{include \"../secret.md\"}}", 0)
+	if err == nil {
+		t.Errorf("want error for an include outside the blog directory")
+	}
+}
@@ -0,0 +1,53 @@
+package lib
+
+import "strings"
+
+// IndexConfig holds settings of the index page.
+type IndexConfig struct {
+	// ExcludeTags are the tags whose Posts aren't listed in the index. They're
+	// still listed in their tag pages and feeds.
+	ExcludeTags []string `yaml:"excludeTags"`
+
+	// ExcludeDirs are the directories of the posts directory (see Post.Dir)
+	// whose Posts (including the ones in their subdirectories) aren't listed
+	// in the index.
+	ExcludeDirs []string `yaml:"excludeDirs"`
+}
+
+// indexPosts returns the Posts listed in the index: all of them except the
+// ones excluded by the Config's Index.
+func (g StaticBlogGenerator) indexPosts() []Post {
+	index := g.options.config.Index
+	if len(index.ExcludeTags) == 0 && len(index.ExcludeDirs) == 0 {
+		return g.posts
+	}
+
+	excluded := map[string]bool{}
+	for _, tag := range index.ExcludeTags {
+		excluded[g.slug(tag)] = true
+	}
+
+	var posts []Post
+	for _, post := range g.posts {
+		if !g.excludedFromIndex(post, excluded) {
+			posts = append(posts, post)
+		}
+	}
+	return posts
+}
+
+func (g StaticBlogGenerator) excludedFromIndex(post Post, excludedTags map[string]bool) bool {
+	for _, tag := range post.Tags {
+		if excludedTags[g.slug(tag)] {
+			return true
+		}
+	}
+
+	for _, dir := range g.options.config.Index.ExcludeDirs {
+		dir = strings.Trim(dir, "/")
+		if post.Dir == dir || strings.HasPrefix(post.Dir, dir+"/") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,90 @@
+package lib
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var linkRegexp = regexp.MustCompile(`(?:href|src)="([^"]*)"`)
+
+// checkLinks verifies that internal links (href and src attributes) in the
+// generated HTML files point to files in the output directory. Broken links
+// are reported according to the BrokenLinks mode.
+func (g StaticBlogGenerator) checkLinks() error {
+	mode, err := g.options.config.brokenLinks()
+	if err != nil {
+		return err
+	}
+
+	titles := map[string]string{}
+	for _, post := range append(g.posts[:len(g.posts):len(g.posts)], g.unlisted...) {
+		titles[filepath.ToSlash(g.postPath(post))] = post.Title
+	}
+
+	broken := 0
+	err = filepath.WalkDir(g.outputDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || filepath.Ext(p) != ".html" {
+			return err
+		}
+
+		bytes, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(g.outputDir, p)
+		if err != nil {
+			return err
+		}
+		page := filepath.ToSlash(rel)
+
+		source := page
+		if title, ok := titles[page]; ok {
+			source = fmt.Sprintf("%s (%s)", page, title)
+		}
+
+		for _, match := range linkRegexp.FindAllStringSubmatch(string(bytes), -1) {
+			target := g.internalURL("/"+page, match[1])
+			if target == "" || g.outputExists(target) {
+				continue
+			}
+
+			broken++
+			g.options.warn(fmt.Sprintf("broken link in %s: %s", source, match[1]))
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if broken > 0 && mode == BrokenLinksError {
+		return fmt.Errorf("found %d broken link(s)", broken)
+	}
+	return nil
+}
+
+// outputExists tells whether the URL (relative to the Blog's root) points to
+// a file in the output directory. URLs of directories point to their
+// index.html files and URLs without an extension (see URLStyleClean) can
+// point to .html files.
+func (g StaticBlogGenerator) outputExists(url string) bool {
+	p := filepath.Join(g.outputDir, filepath.FromSlash(path.Clean(url)))
+	info, err := os.Stat(p)
+	if err != nil && path.Ext(url) == "" {
+		info, err = os.Stat(p + ".html")
+	}
+	if err != nil {
+		return false
+	}
+	if info.IsDir() || strings.HasSuffix(url, "/") {
+		_, err = os.Stat(filepath.Join(p, "index.html"))
+		return err == nil
+	}
+	return true
+}
@@ -0,0 +1,41 @@
+package lib
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCheckLinks(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"index.html":      `<a href="/rome.html">Rome</a> <a href="/tags/">Tags</a>`,
+		"rome.html":       `<a href="paris.html#top">Paris</a> <img src="/img/rome.jpg"> <a href="https://example.org">x</a>`,
+		"tags/index.html": `<a href="../index.html">Home</a>`,
+	}
+	for name, content := range files {
+		os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0700)
+		os.WriteFile(filepath.Join(dir, name), []byte(content), 0600)
+	}
+
+	var warnings []string
+	g := StaticBlogGenerator{outputDir: dir, posts: []Post{{Title: "Rome"}},
+		options: options{warnFunc: func(message string) { warnings = append(warnings, message) }}}
+
+	if err := g.checkLinks(); err == nil {
+		t.Errorf("want an error, got nil")
+	}
+
+	want := []string{
+		"broken link in rome.html (Rome): paris.html#top",
+		"broken link in rome.html (Rome): /img/rome.jpg",
+	}
+	if len(warnings) != len(want) {
+		t.Fatalf("want %v, got %v", want, warnings)
+	}
+	for i := range want {
+		if warnings[i] != want[i] {
+			t.Errorf("want %q, got %q", want[i], warnings[i])
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package lib
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+)
+
+// remoteImagesDir is the directory in the output directory remote images are
+// downloaded to.
+const remoteImagesDir = "images/remote"
+
+// imageExtensions are file extensions of images whose URLs don't have them.
+var imageExtensions = map[string]string{
+	"image/avif":    ".avif",
+	"image/gif":     ".gif",
+	"image/jpeg":    ".jpg",
+	"image/png":     ".png",
+	"image/svg+xml": ".svg",
+	"image/webp":    ".webp",
+}
+
+// remoteImagesClient downloads remote images.
+var remoteImagesClient = &http.Client{Timeout: time.Minute}
+
+// localizeImages downloads images from other sites referenced in rendered
+// Posts to the output directory and rewrites their URLs. Downloaded images
+// are cached in the cache directory. Images that fail to download are
+// reported as warnings and keep their URLs.
+func (g StaticBlogGenerator) localizeImages(html string) string {
+	if !g.options.config.LocalizeImages {
+		return html
+	}
+
+	return rewriteStartTags(html, "img", func(attrs *attributes) {
+		src, _ := attrs.get("src")
+		if !g.externalURL(src, nil) {
+			return
+		}
+
+		url, err := g.localizeImage(src)
+		if err != nil {
+			g.options.warn(fmt.Sprintf("failed to localize image %s: %s", src, err))
+			return
+		}
+		attrs.set("src", url)
+	})
+}
+
+// localizeImage copies the image at the URL to the remoteImagesDir (unless
+// it's already there) and returns its new URL.
+func (g StaticBlogGenerator) localizeImage(src string) (string, error) {
+	if url, ok := g.localized[src]; ok {
+		return url, nil
+	}
+
+	cached, err := g.cachedImage(src)
+	if err != nil {
+		return "", err
+	}
+
+	name := path.Join(remoteImagesDir, filepath.Base(cached))
+	err = g.generateFile(filepath.FromSlash(name), func(w io.Writer) error {
+		file, err := os.Open(cached)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		_, err = io.Copy(w, file)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+
+	g.localized[src] = "/" + name
+	return "/" + name, nil
+}
+
+// cachedImage returns the path of the image at the URL in the cache
+// directory. The image is downloaded if it isn't cached yet.
+func (g StaticBlogGenerator) cachedImage(src string) (string, error) {
+	sum := sha1.Sum([]byte(src))
+	base := g.options.cachePath("images", hex.EncodeToString(sum[:]))
+
+	matches, _ := filepath.Glob(base + ".*")
+	if len(matches) > 0 {
+		return matches[0], nil
+	}
+
+	resp, err := remoteImagesClient.Get(src)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status: %s", resp.Status)
+	}
+
+	ext := path.Ext(resp.Request.URL.Path)
+	if ext == "" {
+		mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+		ext = imageExtensions[mediaType]
+	}
+
+	err = os.MkdirAll(filepath.Dir(base), 0700)
+	if err != nil {
+		return "", err
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(base), "download-")
+	if err != nil {
+		return "", err
+	}
+	_, err = io.Copy(file, resp.Body)
+	file.Close()
+	if err != nil {
+		os.Remove(file.Name())
+		return "", err
+	}
+
+	return base + ext, os.Rename(file.Name(), base+ext)
+}
@@ -0,0 +1,55 @@
+package lib
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLocalizeImages(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "image/png")
+		w.Write([]byte("png"))
+	}))
+	defer server.Close()
+
+	newGenerator := func() StaticBlogGenerator {
+		return StaticBlogGenerator{outputDir: t.TempDir(), progressFunc: func(string) {},
+			localized: map[string]string{},
+			options: options{config: Config{LocalizeImages: true}, cacheDir: t.TempDir(),
+				warnFunc: func(string) {}}}
+	}
+	g := newGenerator()
+
+	html := `<img src="` + server.URL + `/rome"><img src="` + server.URL + `/missing"><img src="/paris.png">`
+	got := g.localizeImages(html + html)
+
+	name := "/" + remoteImagesDir + "/" + filepath.Base(g.localized[server.URL+"/rome"])
+	want := `<img src="` + name + `"><img src="` + server.URL + `/missing"><img src="/paris.png">`
+	if got != want+want {
+		t.Errorf("want %q, got %q", want+want, got)
+	}
+	if filepath.Ext(name) != ".png" {
+		t.Errorf("want %v, got %v", ".png", filepath.Ext(name))
+	}
+
+	bytes, err := os.ReadFile(filepath.Join(g.outputDir, filepath.FromSlash(name)))
+	if err != nil || string(bytes) != "png" {
+		t.Errorf("want %q, got %q (%v)", "png", bytes, err)
+	}
+
+	cached := newGenerator()
+	cached.options.cacheDir = g.options.cacheDir
+	cached.localizeImages(html)
+	if requests != 4 {
+		t.Errorf("want %v, got %v", 4, requests)
+	}
+}
@@ -0,0 +1,105 @@
+package lib
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LockFile is the name of the file locking a Blog while it's being built.
+const LockFile = "build.lock"
+
+// lockRetryInterval is how often a held BuildLock is tried to be acquired
+// again.
+const lockRetryInterval = 100 * time.Millisecond
+
+// errLocked is returned by lockFile if the file is locked by another process.
+var errLocked = errors.New("file is locked")
+
+// BuildLock is an advisory lock preventing concurrent builds of a Blog (for
+// example by a cron job and a user) from interleaving writes to its output
+// directory. It's an exclusive lock of a file (flock on Unix, LockFileEx on
+// Windows) holding the ID of the process that acquired it. The operating
+// system releases the lock when the process exits, so locks of builds that
+// didn't finish don't have to be taken over.
+type BuildLock struct {
+	file *os.File
+}
+
+// AcquireBuildLock acquires the lock with the path, waiting at most for the
+// timeout (or failing immediately if it's 0) if it's held by another
+// process. The lock should be released when the build is done.
+func AcquireBuildLock(path string, timeout time.Duration) (BuildLock, error) {
+	err := os.MkdirAll(filepath.Dir(path), 0700)
+	if err != nil {
+		return BuildLock{}, fmt.Errorf("failed to create lock directory: %s", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return BuildLock{}, fmt.Errorf("failed to create lock: %s", err)
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		err = lockFile(file)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, errLocked) {
+			file.Close()
+			return BuildLock{}, fmt.Errorf("failed to lock: %s", err)
+		}
+
+		if !time.Now().Before(deadline) {
+			pid := lockHolder(file)
+			file.Close()
+			return BuildLock{}, fmt.Errorf("blog is being built by another process (%d)", pid)
+		}
+		time.Sleep(lockRetryInterval)
+	}
+
+	err = file.Truncate(0)
+	if err == nil {
+		_, err = file.WriteAt([]byte(strconv.Itoa(os.Getpid())), 0)
+	}
+	if err != nil {
+		BuildLock{file}.Release()
+		return BuildLock{}, fmt.Errorf("failed to write lock: %s", err)
+	}
+	return BuildLock{file}, nil
+}
+
+// Release releases the lock. The lock file is kept, so processes waiting for
+// the lock don't end up locking different files.
+func (l BuildLock) Release() error {
+	if l.file == nil {
+		return nil
+	}
+
+	l.file.Truncate(0)
+	err := unlockFile(l.file)
+	if closeErr := l.file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil && !errors.Is(err, os.ErrClosed) {
+		return fmt.Errorf("failed to release lock: %s", err)
+	}
+	return nil
+}
+
+// lockHolder returns the ID of the process holding the lock of the file (0
+// if it's unknown).
+func lockHolder(file *os.File) int {
+	bytes, err := io.ReadAll(io.NewSectionReader(file, 0, 32))
+	if err != nil {
+		return 0
+	}
+	pid, _ := strconv.Atoi(strings.TrimSpace(string(bytes)))
+	return pid
+}
@@ -0,0 +1,73 @@
+package lib
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestBuildLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".cache", LockFile)
+	lock, err := AcquireBuildLock(path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = AcquireBuildLock(path, 0)
+	if want := fmt.Sprintf("blog is being built by another process (%d)", os.Getpid()); err == nil ||
+		err.Error() != want {
+		t.Errorf("want %v, got %v", want, err)
+	}
+
+	released := make(chan error)
+	go func() {
+		time.Sleep(2 * lockRetryInterval)
+		released <- lock.Release()
+	}()
+	lock, err = AcquireBuildLock(path, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := <-released; err != nil {
+		t.Fatal(err)
+	}
+	lock.Release()
+
+	// lock files of processes that exited aren't locked anymore
+	os.WriteFile(path, []byte("2147483647"), 0600)
+	lock, err = AcquireBuildLock(path, 0)
+	if err != nil {
+		t.Fatalf("want %v, got %v", "a taken over lock", err)
+	}
+	if bytes, _ := os.ReadFile(path); string(bytes) != strconv.Itoa(os.Getpid()) {
+		t.Errorf("want %v, got %s", os.Getpid(), bytes)
+	}
+	lock.Release()
+}
+
+func TestBuildLockConcurrent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), LockFile)
+	os.WriteFile(path, []byte("2147483647"), 0600)
+
+	// only one of the builds taking over the stale lock at the same time
+	// acquires it
+	results := make(chan error)
+	for i := 0; i < 10; i++ {
+		go func() {
+			_, err := AcquireBuildLock(path, 0)
+			results <- err
+		}()
+	}
+	acquired := 0
+	for i := 0; i < 10; i++ {
+		if err := <-results; err == nil {
+			acquired++
+		}
+	}
+	if acquired != 1 {
+		t.Errorf("want %v, got %v", 1, acquired)
+	}
+}
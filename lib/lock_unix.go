@@ -0,0 +1,24 @@
+//go:build !windows
+
+package lib
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// lockFile locks the file exclusively with flock. It returns errLocked if the
+// file is locked by another process.
+func lockFile(file *os.File) error {
+	err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+	if errors.Is(err, syscall.EWOULDBLOCK) {
+		return errLocked
+	}
+	return err
+}
+
+// unlockFile unlocks the file locked by lockFile.
+func unlockFile(file *os.File) error {
+	return syscall.Flock(int(file.Fd()), syscall.LOCK_UN)
+}
@@ -0,0 +1,30 @@
+package lib
+
+import (
+	"errors"
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// lockOffset is the offset of the locked byte of lock files. It's past the
+// process ID, so other processes can read it while the file is locked.
+const lockOffset = 1 << 30
+
+// lockFile locks the file exclusively with LockFileEx. It returns errLocked if
+// the file is locked by another process.
+func lockFile(file *os.File) error {
+	err := windows.LockFileEx(windows.Handle(file.Fd()),
+		windows.LOCKFILE_EXCLUSIVE_LOCK|windows.LOCKFILE_FAIL_IMMEDIATELY, 0, 1, 0,
+		&windows.Overlapped{Offset: lockOffset})
+	if errors.Is(err, windows.ERROR_LOCK_VIOLATION) {
+		return errLocked
+	}
+	return err
+}
+
+// unlockFile unlocks the file locked by lockFile.
+func unlockFile(file *os.File) error {
+	return windows.UnlockFileEx(windows.Handle(file.Fd()), 0, 1, 0,
+		&windows.Overlapped{Offset: lockOffset})
+}
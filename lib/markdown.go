@@ -15,9 +15,15 @@ const (
 
 // MarkdownBlog represents a Blog stored as Markdown files in a directory.
 //
+// Files with the .adoc or .asciidoc extension are read as AsciiDoc (see
+// asciidocToPost), files with the .org extension as Org mode (see orgToPost)
+// and files with the .html or .htm extension as already rendered HTML (see
+// htmlToPost) instead. Files with extensions mapped in the Config's Processors
+// are converted by external commands (see processedToPost).
+//
 // Posts are stored as Markdown files in the posts subdirectory of the Blog
-// directory. Draft Posts (ones with Draft set to true) are stored in the draft
-// subdirectory of the posts directory.
+// directory (or in its nested subdirectories). Draft Posts (ones with Draft set
+// to true) are stored in the draft subdirectory of the posts directory.
 //
 // So the structure looks like this:
 //
@@ -26,11 +32,25 @@ const (
 //	    draft/
 //	      draft1.md
 //	      ...
+//	    2024/
+//	      post3.md
+//	      ...
+//	    my-trip/
+//	      index.md
+//	      photo.jpg
+//	      ...
 //	    post1.md
 //	    post2.md
 //	    ...
 //
-// Markdown files have the following format:
+// Directories with an index file (like my-trip above) are page bundles (see
+// Post.Bundle): the index file is the post and other files are its assets.
+//
+// Markdown files have the following format (the front matter is optional):
+//
+//	---
+//	updated: 2009-01-10
+//	---
 //
 //	# Title
 //
@@ -42,17 +62,22 @@ const (
 //
 //	Content
 type MarkdownBlog struct {
-	dir string
+	dir     string
+	options options
+
+	// git holds the dates of the files read from the Git history (if the
+	// Config's GitDates is set).
+	git gitDates
 }
 
 // NewMarkdownBlog creates a MarkdownBlog in the provided directory.
 //
 // If the directory doesn't exist it creates it.
-func NewMarkdownBlog(dir string) MarkdownBlog {
+func NewMarkdownBlog(dir string, opts ...Option) MarkdownBlog {
 	if _, err := os.Stat(dir); err != nil {
 		os.MkdirAll(filepath.Join(dir, postsDir, draftDir), 0700)
 	}
-	return MarkdownBlog{dir}
+	return MarkdownBlog{dir: dir, options: newOptions(opts)}
 }
 
 // Read creates a Blog from the Markdown files.
@@ -60,17 +85,26 @@ func NewMarkdownBlog(dir string) MarkdownBlog {
 // If the directory doesn't exist it returns an error.
 func (b MarkdownBlog) Read() (Blog, error) {
 	if _, err := os.Stat(b.dir); err != nil {
-		return Blog{}, fmt.Errorf("blog not found: %s", b)
+		return Blog{}, fmt.Errorf("blog not found: %s", b.dir)
+	}
+
+	loc, err := b.options.config.location()
+	if err != nil {
+		return Blog{}, err
+	}
+
+	if b.options.config.GitDates {
+		b.git = readGitDates(b.dir)
 	}
 
 	postsPath := filepath.Join(b.dir, postsDir)
-	posts, err := readPosts(postsPath)
+	posts, err := b.readPosts(postsPath, "", loc)
 	if err != nil {
 		return Blog{}, err
 	}
 
 	draftsPath := filepath.Join(postsPath, draftDir)
-	drafts, err := readPosts(draftsPath)
+	drafts, err := b.readPosts(draftsPath, "", loc)
 	if err != nil {
 		return Blog{}, err
 	}
@@ -81,10 +115,24 @@ func (b MarkdownBlog) Read() (Blog, error) {
 		blog = append(blog, draft)
 	}
 
+	authors, err := b.readAuthors()
+	if err != nil {
+		return Blog{}, err
+	}
+	for i := range blog {
+		resolveAuthors(&blog[i], authors)
+		for j := range blog[i].Bylines {
+			blog[i].Bylines[j].Slug = b.options.slug(blog[i].Bylines[j].Name)
+		}
+	}
+
 	return blog, nil
 }
 
-func readPosts(dir string) ([]Post, error) {
+// readPosts reads the posts in the rel subdirectory of the root directory and
+// in its subdirectories (except the drafts directory).
+func (b MarkdownBlog) readPosts(root, rel string, loc *time.Location) ([]Post, error) {
+	dir := filepath.Join(root, rel)
 	postFiles, err := os.ReadDir(dir)
 	if err != nil {
 		return []Post{}, fmt.Errorf("failed to read posts: %s", err)
@@ -92,31 +140,155 @@ func readPosts(dir string) ([]Post, error) {
 
 	var posts []Post
 	for _, postFile := range postFiles {
-		// TODO dirs/files starting with '.' or '_' are drafts
-		if postFile.IsDir() || strings.HasPrefix(postFile.Name(), ".") {
+		// TODO dirs/files starting with '_' are drafts
+		if strings.HasPrefix(postFile.Name(), ".") {
+			continue
+		}
+
+		path := filepath.Join(dir, postFile.Name())
+		if postFile.IsDir() {
+			if path == filepath.Join(b.dir, postsDir, draftDir) {
+				continue
+			}
+
+			if index := b.options.config.bundleIndex(path); index != "" {
+				post, err := b.readPost(filepath.Join(path, index), loc)
+				if err != nil {
+					return []Post{}, err
+				}
+				post.Dir = filepath.ToSlash(rel)
+				post.Bundle = path
+				posts = append(posts, post)
+				continue
+			}
+
+			nested, err := b.readPosts(root, filepath.Join(rel, postFile.Name()), loc)
+			if err != nil {
+				return []Post{}, err
+			}
+			posts = append(posts, nested...)
 			continue
 		}
 
-		post, err := readPost(filepath.Join(dir, postFile.Name()))
+		post, err := b.readPost(path, loc)
+		if err != nil && encryptedExt(path) != "" &&
+			root == filepath.Join(b.dir, postsDir, draftDir) {
+			b.options.warn(fmt.Sprintf("skipping encrypted draft %s: %s", path, err))
+			continue
+		}
 		if err != nil {
 			return []Post{}, err
 		}
+		post.Dir = filepath.ToSlash(rel)
 		posts = append(posts, post)
 	}
 	return posts, nil
 }
 
-func readPost(path string) (Post, error) {
-	markdown, err := os.ReadFile(path)
+func (b MarkdownBlog) readPost(path string, loc *time.Location) (Post, error) {
+	bytes, err := os.ReadFile(path)
 	if err != nil {
 		return Post{}, fmt.Errorf("failed to read post: %s", err)
 	}
 
-	return markdownToPost(string(markdown))
+	ext := filepath.Ext(path)
+	if encrypted := encryptedExt(path); encrypted != "" {
+		bytes, err = b.options.config.DraftEncryption.decrypt(bytes, encrypted)
+		if err != nil {
+			return Post{}, fmt.Errorf("failed to decrypt post %s: %s", path, err)
+		}
+		ext = filepath.Ext(strings.TrimSuffix(path, ext))
+		// renders of decrypted drafts aren't cached, so they stay encrypted
+		// at rest
+		b.options.config.RenderCache = false
+	}
+
+	source, err := b.resolveIncludes(string(bytes), 0)
+	if err != nil {
+		return Post{}, fmt.Errorf("failed to parse post %s: %s", path, err)
+	}
+
+	post, err := b.parsePost(source, ext, loc)
+	if err != nil {
+		return Post{}, fmt.Errorf("failed to parse post %s: %s", path, err)
+	}
+
+	post.Encrypted = encryptedExt(path) != ""
+
+	post.Comments, err = b.readComments(post, loc)
+	if err != nil {
+		return Post{}, err
+	}
+
+	if rel, err := filepath.Rel(b.dir, path); b.git != nil && err == nil {
+		dates := b.git.of(rel)
+		post.Written = dates.written(post.Written, loc)
+		if post.Updated.IsZero() {
+			post.Updated = dates.updated
+		}
+	}
+
+	if post.Updated.IsZero() {
+		if info, err := os.Stat(path); err == nil {
+			post.Updated = info.ModTime()
+		}
+	}
+
+	return post, nil
 }
 
-func markdownToPost(markdown string) (Post, error) {
-	md := strings.ReplaceAll(markdown, "\r\n", "\n")
+// parsePost parses the source of a post according to the extension of its
+// file.
+func (b MarkdownBlog) parsePost(source, ext string, loc *time.Location) (Post, error) {
+	var post Post
+	var err error
+	ext = strings.ToLower(ext)
+	command := b.options.config.processor(ext)
+	switch {
+	case command != "":
+		post, err = processedToPost(source, loc, b.process(command))
+	case ext == ".adoc" || ext == ".asciidoc":
+		post, err = asciidocToPost(source, loc, b.asciidoctor)
+	case ext == ".org":
+		post, err = orgToPost(source, loc)
+	case ext == ".html" || ext == ".htm":
+		post, err = htmlToPost(source, loc)
+	default:
+		post, err = markdownToPost(source, loc)
+	}
+	if err != nil {
+		return Post{}, err
+	}
+
+	post.Tags = b.canonicalTags(post.Tags)
+	return post, nil
+}
+
+// canonicalTags replaces tag aliases with their canonical tags and removes
+// duplicates.
+func (b MarkdownBlog) canonicalTags(tags []string) []string {
+	if len(b.options.config.TagAliases) == 0 {
+		return tags
+	}
+
+	var canonical []string
+	seen := map[string]bool{}
+	for _, tag := range tags {
+		tag = b.options.config.canonicalTag(tag)
+		if !seen[tag] {
+			seen[tag] = true
+			canonical = append(canonical, tag)
+		}
+	}
+	return canonical
+}
+
+// markdownToPost parses a post. Dates without a time zone are in the loc.
+func markdownToPost(markdown string, loc *time.Location) (Post, error) {
+	fm, md, err := splitFrontMatter(strings.ReplaceAll(markdown, "\r\n", "\n"))
+	if err != nil {
+		return Post{}, err
+	}
 
 	paras := strings.Split(md, "\n\n")
 	if len(paras) < 3 {
@@ -125,26 +297,31 @@ func markdownToPost(markdown string) (Post, error) {
 
 	title := strings.TrimSpace(strings.Replace(paras[0], "#", "", -1))
 
-	written, err := time.Parse("*Jan 2, 2006*", paras[1])
+	written, err := time.ParseInLocation("*Jan 2, 2006*", paras[1], loc)
 	if err != nil {
 		return Post{}, fmt.Errorf("failed to parse date: %s", err)
 	}
 
+	// the tags line can be empty (**), for example in posts created via
+	// Micropub
 	var tags []string
-	if strings.HasPrefix(paras[2], "*") && !strings.Contains(paras[2], "\n") {
-		tags = strings.Split(paras[2], ",")
-		for i, tag := range tags {
-			tags[i] = strings.TrimSpace(strings.Replace(tag, "*", "", -1))
+	hasTags := strings.HasPrefix(paras[2], "*") && !strings.Contains(paras[2], "\n")
+	if hasTags {
+		for _, tag := range strings.Split(paras[2], ",") {
+			if tag = strings.TrimSpace(strings.Replace(tag, "*", "", -1)); tag != "" {
+				tags = append(tags, tag)
+			}
 		}
 	}
 
 	var isPage bool
-	if strings.HasPrefix(paras[3], "*") && strings.Contains(paras[3], "page") {
+	if len(paras) > 3 && strings.HasPrefix(paras[3], "*") &&
+		strings.Contains(paras[3], "page") {
 		isPage = true
 	}
 
 	var content string
-	if len(tags) == 0 && !isPage {
+	if !hasTags && !isPage {
 		content = strings.Join(paras[2:], "\n\n")
 	} else if isPage {
 		content = strings.Join(paras[4:], "\n\n")
@@ -152,5 +329,12 @@ func markdownToPost(markdown string) (Post, error) {
 		content = strings.Join(paras[3:], "\n\n")
 	}
 
-	return Post{title, content, written, tags, false, isPage}, nil
+	post := Post{Title: title, Content: content, Written: written, Tags: tags,
+		IsPage: isPage}
+	err = fm.apply(&post, loc)
+	if err != nil {
+		return Post{}, err
+	}
+
+	return post, nil
 }
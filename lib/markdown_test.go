@@ -1,11 +1,17 @@
 package lib
 
-import "testing"
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
 
 func TestMarkdownToPostWithLF(t *testing.T) {
 	lf := "# A title\n\n*Aug 10, 2021*\n\n*Test, Markdown*\n\nTesting LF\n"
 
-	_, err := markdownToPost(lf)
+	_, err := markdownToPost(lf, time.UTC)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -14,7 +20,7 @@ func TestMarkdownToPostWithLF(t *testing.T) {
 func TestMarkdownToPostWithCRLF(t *testing.T) {
 	crlf := "# A title\r\n\r\n*Aug 10, 2021*\r\n\r\n*Test, Markdown*\r\n\r\nTesting CRLF\r\n"
 
-	_, err := markdownToPost(crlf)
+	_, err := markdownToPost(crlf, time.UTC)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -23,7 +29,7 @@ func TestMarkdownToPostWithCRLF(t *testing.T) {
 func TestMarkdownToPostWithPage(t *testing.T) {
 	lf := "# A title\n\n*Aug 10, 2021*\n\n*Test, Markdown*\n\n*page*\n\nTesting LF\n"
 
-	post, err := markdownToPost(lf)
+	post, err := markdownToPost(lf, time.UTC)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -32,3 +38,135 @@ func TestMarkdownToPostWithPage(t *testing.T) {
 		t.Errorf("want %v, got %v", true, post.IsPage)
 	}
 }
+
+func TestMarkdownToPostWithFrontMatter(t *testing.T) {
+	lf := "---\nupdated: 2021-08-12\n---\n\n# A title\n\n*Aug 10, 2021*\n\nTesting LF\n"
+
+	post, err := markdownToPost(lf, time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := time.Date(2021, 8, 12, 0, 0, 0, 0, time.UTC)
+	if !post.Updated.Equal(want) {
+		t.Errorf("want %v, got %v", want, post.Updated)
+	}
+
+	if post.Title != "A title" {
+		t.Errorf("want %v, got %v", "A title", post.Title)
+	}
+}
+
+func TestMarkdownToPostWithOutput(t *testing.T) {
+	for output, want := range map[string]string{
+		"/projects/widget/index.html": "projects/widget/index.html",
+		"projects/widget/":            "projects/widget/index.html",
+		"/../../etc/widget.html":      "etc/widget.html",
+		"/":                           "",
+	} {
+		md := "---\noutput: " + output + "\n---\n\n# Widget\n\n*Aug 10, 2021*\n\nWidget\n"
+		post, err := markdownToPost(md, time.UTC)
+		if want == "" {
+			if err == nil {
+				t.Errorf("%s: want an error, got %v", output, post.Output)
+			}
+			continue
+		}
+		if err != nil || post.Output != want {
+			t.Errorf("%s: want %v, got %v (%v)", output, want, post.Output, err)
+		}
+	}
+
+	g := StaticBlogGenerator{}
+	if url := g.postURL(Post{Title: "Widget", Output: "projects/widget/index.html"}); url != "/projects/widget/index.html" {
+		t.Errorf("want %v, got %v", "/projects/widget/index.html", url)
+	}
+}
+
+func TestMarkdownToPostWithLocation(t *testing.T) {
+	lf := "---\nupdated: 2021-08-12T10:00:00Z\n---\n\n# A title\n\n*Aug 10, 2021*\n\nTesting LF\n"
+
+	loc := time.FixedZone("UTC+2", 2*60*60)
+	post, err := markdownToPost(lf, loc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := time.Date(2021, 8, 10, 0, 0, 0, 0, loc)
+	if !post.Written.Equal(want) {
+		t.Errorf("want %v, got %v", want, post.Written)
+	}
+
+	want = time.Date(2021, 8, 12, 10, 0, 0, 0, time.UTC)
+	if !post.Updated.Equal(want) {
+		t.Errorf("want %v, got %v", want, post.Updated)
+	}
+}
+
+func TestCanonicalTags(t *testing.T) {
+	b := MarkdownBlog{options: options{config: Config{
+		TagAliases: map[string]string{"golang": "Go"}}}}
+
+	tags := b.canonicalTags([]string{"Golang", "Go", "Web"})
+	if len(tags) != 2 || tags[0] != "Go" || tags[1] != "Web" {
+		t.Errorf("want %v, got %v", []string{"Go", "Web"}, tags)
+	}
+}
+
+func TestMarkdownToPostWithLink(t *testing.T) {
+	md := "---\nlink: https://go.dev/blog/\n---\n\n# The Go Blog\n\n*Aug 10, 2021*\n\nA good read.\n"
+
+	post, err := markdownToPost(md, time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if post.Link != "https://go.dev/blog/" {
+		t.Errorf("want %v, got %v", "https://go.dev/blog/", post.Link)
+	}
+
+	_, err = markdownToPost(strings.Replace(md, "https://go.dev/blog/", "go.dev", 1), time.UTC)
+	if err == nil {
+		t.Errorf("want %v, got %v", "an error", err)
+	}
+}
+
+func TestReadNestedPosts(t *testing.T) {
+	dir := t.TempDir()
+	for path, title := range map[string]string{
+		"rome.md":                    "Rome",
+		"notes/travel/oslo.md":       "Oslo",
+		"notes/bern.md":              "Bern",
+		".hidden/vienna.md":          "Vienna",
+		draftDir + "/notes/paris.md": "Paris",
+	} {
+		path = filepath.Join(dir, postsDir, filepath.FromSlash(path))
+		os.MkdirAll(filepath.Dir(path), 0700)
+		os.WriteFile(path, []byte("# "+title+"\n\n*Aug 10, 2021*\n\n"+title+"\n"), 0600)
+	}
+
+	blog, err := NewMarkdownBlog(dir).Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := map[string]Post{}
+	for _, post := range blog {
+		got[post.Title] = post
+	}
+	for title, want := range map[string]Post{
+		"Rome":  {Dir: ""},
+		"Oslo":  {Dir: "notes/travel"},
+		"Bern":  {Dir: "notes"},
+		"Paris": {Dir: "notes", Draft: true},
+	} {
+		post, ok := got[title]
+		if !ok || post.Dir != want.Dir || post.Draft != want.Draft {
+			t.Errorf("%s: want %v %v, got %v %v", title, want.Dir, want.Draft, post.Dir, post.Draft)
+		}
+	}
+
+	// hidden directories are skipped and drafts are read only once
+	if len(blog) != 4 {
+		t.Errorf("want %v, got %v", 4, len(blog))
+	}
+}
@@ -0,0 +1,73 @@
+package lib
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/gosimple/slug"
+)
+
+// BlogReader reads a Blog from a content source (for example a MarkdownBlog).
+type BlogReader interface {
+	Read() (Blog, error)
+}
+
+// MergedBlog is a Blog composed of Posts of multiple content sources (for
+// example personal posts and a shared team feed).
+type MergedBlog struct {
+	readers []BlogReader
+	options options
+}
+
+// NewMergedBlog creates a MergedBlog reading the Posts from the readers.
+// Posts of earlier readers take precedence over Posts with the same slug (see
+// postKey) of later ones.
+func NewMergedBlog(readers []BlogReader, opts ...Option) MergedBlog {
+	return MergedBlog{readers, newOptions(opts)}
+}
+
+// Read creates a Blog from the Posts of all readers sorted by date (and by
+// slug if the dates are equal) in ascending order. Ignored duplicate Posts are
+// reported as warnings.
+func (b MergedBlog) Read() (Blog, error) {
+	var blog Blog
+	seen := map[string]int{}
+	for i, reader := range b.readers {
+		posts, err := reader.Read()
+		if err != nil {
+			return Blog{}, fmt.Errorf("failed to read source %d: %s", i+1, err)
+		}
+
+		for _, post := range posts {
+			key := postKey(post)
+			if source, ok := seen[key]; ok {
+				b.options.warn(fmt.Sprintf("duplicate post %s (%s) in source %d ignored, it's in source %d",
+					key, post.Title, i+1, source))
+				continue
+			}
+			seen[key] = i + 1
+			blog = append(blog, post)
+		}
+	}
+
+	sort.SliceStable(blog, func(i, j int) bool {
+		if blog[i].Written.Equal(blog[j].Written) {
+			return postKey(blog[i]) < postKey(blog[j])
+		}
+		return blog[i].Written.Before(blog[j].Written)
+	})
+	return blog, nil
+}
+
+// postKey returns the slug of the Post's title prefixed with its Dir (if any)
+// and with the drafts directory for drafts.
+func postKey(post Post) string {
+	key := slug.Make(post.Title)
+	if post.Dir != "" {
+		key = post.Dir + "/" + key
+	}
+	if post.Draft {
+		key = draftDir + "/" + key
+	}
+	return key
+}
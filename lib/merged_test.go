@@ -0,0 +1,57 @@
+package lib
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+type testReader struct {
+	blog Blog
+	err  error
+}
+
+func (r testReader) Read() (Blog, error) {
+	return r.blog, r.err
+}
+
+func TestMergedBlog(t *testing.T) {
+	day := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	personal := testReader{blog: Blog{
+		{Title: "Rome", Content: "personal", Written: day},
+		{Title: "Paris", Written: day.AddDate(0, 0, 1)},
+	}}
+	team := testReader{blog: Blog{
+		{Title: "Oslo", Written: day},
+		{Title: "rome", Content: "team", Written: day.AddDate(0, 0, 2)},
+		{Title: "Rome", Dir: "team", Written: day.AddDate(0, 0, 3)},
+	}}
+
+	var warnings []string
+	blog, err := NewMergedBlog([]BlogReader{personal, team},
+		WithWarnFunc(func(message string) { warnings = append(warnings, message) })).Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var titles []string
+	for _, post := range blog {
+		titles = append(titles, post.Dir+post.Title)
+	}
+	want := "[Oslo Rome Paris teamRome]"
+	if got := fmt.Sprint(titles); got != want {
+		t.Errorf("want %v, got %v", want, got)
+	}
+	if blog[1].Content != "personal" {
+		t.Errorf("want %v, got %v", "personal", blog[1].Content)
+	}
+	if len(warnings) != 1 {
+		t.Errorf("want %v, got %v", 1, len(warnings))
+	}
+
+	_, err = NewMergedBlog([]BlogReader{personal, testReader{err: errors.New("oops")}}).Read()
+	if err == nil {
+		t.Errorf("want %v, got %v", "an error", err)
+	}
+}
@@ -0,0 +1,595 @@
+package lib
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// micropubMaxBody is the maximum size of bodies of Micropub requests in bytes.
+const micropubMaxBody = 10 << 20
+
+// ErrMicropubDisabled is returned by NewMicropub if the authentication of the
+// endpoint isn't configured.
+var ErrMicropubDisabled = errors.New("neither micropub token nor token endpoint is set")
+
+// MicropubConfig holds the authentication settings of the Micropub endpoint.
+// It's enabled only if the Token or the TokenEndpoint is set.
+type MicropubConfig struct {
+	// Token is a static access token (it can also be set in the
+	// LITEPUB_MICROPUB_TOKEN environment variable) allowing all actions.
+	Token string `yaml:"token"`
+
+	// TokenEndpoint is the URL of an IndieAuth token endpoint verifying
+	// access tokens of the Me user (the Blog's URL if it's empty).
+	TokenEndpoint string `yaml:"tokenEndpoint"`
+	Me            string `yaml:"me"`
+}
+
+// enabled tells whether the authentication is configured.
+func (c MicropubConfig) enabled() bool {
+	return c.Token != "" || c.TokenEndpoint != ""
+}
+
+// Micropub is an http.Handler of a Micropub (https://micropub.spec.indieweb.org)
+// endpoint. It creates and updates Markdown files of Posts in a PostStore and
+// calls the change function after each change (for example to rebuild the
+// Blog).
+type Micropub struct {
+	store    PostStore
+	onChange func() error
+	options  options
+	client   *http.Client
+	mu       *sync.Mutex
+}
+
+// NewMicropub creates a Micropub endpoint storing Posts in the PostStore. It
+// returns ErrMicropubDisabled if the authentication isn't configured (see
+// MicropubConfig).
+func NewMicropub(store PostStore, onChange func() error, opts ...Option) (Micropub, error) {
+	o := newOptions(opts)
+	if o.config.Micropub.Token == "" {
+		o.config.Micropub.Token = os.Getenv("LITEPUB_MICROPUB_TOKEN")
+	}
+	if !o.config.Micropub.enabled() {
+		return Micropub{}, ErrMicropubDisabled
+	}
+	o.config.URL = o.config.siteURL()
+	return Micropub{store, onChange, o, &http.Client{Timeout: 10 * time.Second}, &sync.Mutex{}}, nil
+}
+
+// micropubError is an error response of the endpoint.
+type micropubError struct {
+	status      int
+	Code        string `json:"error"`
+	Description string `json:"error_description"`
+}
+
+func (e *micropubError) Error() string {
+	return e.Code + ": " + e.Description
+}
+
+// ServeHTTP handles queries (GET) and create and update requests (POST).
+func (m Micropub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, micropubMaxBody)
+
+	var err *micropubError
+	switch r.Method {
+	case http.MethodGet:
+		_, err = m.authenticate(r)
+		if err == nil {
+			err = m.query(w, r)
+		}
+	case http.MethodPost:
+		err = m.post(w, r)
+	default:
+		err = &micropubError{http.StatusMethodNotAllowed, "invalid_request", "unsupported method"}
+	}
+
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(err.status)
+		json.NewEncoder(w).Encode(err)
+	}
+}
+
+func (m Micropub) query(w http.ResponseWriter, r *http.Request) *micropubError {
+	var response interface{}
+	switch r.URL.Query().Get("q") {
+	case "config":
+		response = map[string]interface{}{"syndicate-to": []string{}}
+	case "syndicate-to":
+		response = map[string]interface{}{"syndicate-to": []string{}}
+	case "source":
+		_, post, _, err := m.find(r.URL.Query().Get("url"))
+		if err != nil {
+			return err
+		}
+		response = map[string]interface{}{"type": []string{"h-entry"},
+			"properties": map[string]interface{}{"name": []string{post.Title},
+				"content": []string{post.Content}, "category": post.Tags,
+				"published": []string{post.Written.Format(time.RFC3339)}}}
+	default:
+		return &micropubError{http.StatusBadRequest, "invalid_request", "unsupported query"}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+	return nil
+}
+
+// micropubRequest is a create or update request of the form or JSON syntax.
+type micropubRequest struct {
+	Type       []string                 `json:"type"`
+	Action     string                   `json:"action"`
+	URL        string                   `json:"url"`
+	Properties map[string][]interface{} `json:"properties"`
+	Replace    map[string][]interface{} `json:"replace"`
+	Add        map[string][]interface{} `json:"add"`
+	Delete     interface{}              `json:"delete"`
+}
+
+func (m Micropub) post(w http.ResponseWriter, r *http.Request) *micropubError {
+	scopes, err := m.authenticate(r)
+	if err != nil {
+		return err
+	}
+
+	req, err := parseMicropubRequest(r)
+	if err != nil {
+		return err
+	}
+
+	action := req.Action
+	if action == "" {
+		action = "create"
+	}
+	err = authorize(scopes, action)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var location string
+	switch action {
+	case "create":
+		location, err = m.create(req)
+	case "update":
+		err = m.update(req)
+	default:
+		return &micropubError{http.StatusBadRequest, "invalid_request", "unsupported action: " + action}
+	}
+	if err != nil {
+		return err
+	}
+
+	if m.onChange != nil {
+		if err := m.onChange(); err != nil {
+			return &micropubError{http.StatusInternalServerError, "server_error", err.Error()}
+		}
+	}
+
+	if action == "create" {
+		w.Header().Set("Location", location)
+		w.WriteHeader(http.StatusCreated)
+	} else {
+		w.WriteHeader(http.StatusNoContent)
+	}
+	return nil
+}
+
+func parseMicropubRequest(r *http.Request) (micropubRequest, *micropubError) {
+	var req micropubRequest
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "application/json" {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return req, &micropubError{http.StatusBadRequest, "invalid_request", err.Error()}
+		}
+		return req, nil
+	}
+
+	var err error
+	if mediaType == "multipart/form-data" {
+		err = r.ParseMultipartForm(micropubMaxBody)
+	} else {
+		err = r.ParseForm()
+	}
+	if err != nil {
+		return req, &micropubError{http.StatusBadRequest, "invalid_request", err.Error()}
+	}
+
+	req.Action = r.PostForm.Get("action")
+	req.URL = r.PostForm.Get("url")
+	req.Properties = map[string][]interface{}{}
+	for name, values := range r.PostForm {
+		switch name {
+		case "h":
+			req.Type = []string{"h-" + values[0]}
+			continue
+		case "access_token", "action", "url":
+			continue
+		}
+		for _, value := range values {
+			name := strings.TrimSuffix(name, "[]")
+			req.Properties[name] = append(req.Properties[name], value)
+		}
+	}
+	return req, nil
+}
+
+// authenticate verifies the request's access token and returns its scopes.
+// The static Token has all scopes (it returns nil scopes for it). The token is
+// read from the Authorization header or, if it's missing, from the (size
+// limited) form body.
+func (m Micropub) authenticate(r *http.Request) ([]string, *micropubError) {
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if token == "" || token == r.Header.Get("Authorization") {
+		token = r.FormValue("access_token")
+	}
+	if token == "" {
+		return nil, &micropubError{http.StatusUnauthorized, "unauthorized", "access token is missing"}
+	}
+
+	config := m.options.config.Micropub
+	if config.Token != "" && subtle.ConstantTimeCompare([]byte(token), []byte(config.Token)) == 1 {
+		return nil, nil
+	}
+	if config.TokenEndpoint == "" {
+		return nil, &micropubError{http.StatusForbidden, "forbidden", "invalid access token"}
+	}
+
+	req, err := http.NewRequest(http.MethodGet, config.TokenEndpoint, nil)
+	if err != nil {
+		return nil, &micropubError{http.StatusInternalServerError, "server_error", err.Error()}
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return nil, &micropubError{http.StatusInternalServerError, "server_error", err.Error()}
+	}
+	defer resp.Body.Close()
+
+	var verified struct {
+		Me    string `json:"me"`
+		Scope string `json:"scope"`
+	}
+	if resp.StatusCode != http.StatusOK || json.NewDecoder(resp.Body).Decode(&verified) != nil {
+		return nil, &micropubError{http.StatusForbidden, "forbidden", "invalid access token"}
+	}
+
+	me := config.Me
+	if me == "" {
+		me = m.options.config.URL
+	}
+	if strings.TrimSuffix(verified.Me, "/") != strings.TrimSuffix(me, "/") {
+		return nil, &micropubError{http.StatusForbidden, "forbidden", "access token of another user"}
+	}
+
+	scopes := strings.Fields(verified.Scope)
+	if len(scopes) == 0 {
+		return nil, &micropubError{http.StatusUnauthorized, "insufficient_scope", "scope is missing"}
+	}
+	return scopes, nil
+}
+
+// authorize checks that the scopes of an access token (see authenticate)
+// allow the action.
+func authorize(scopes []string, action string) *micropubError {
+	if scopes == nil {
+		return nil
+	}
+	for _, s := range scopes {
+		if s == action || (s == "post" && action == "create") {
+			return nil
+		}
+	}
+	return &micropubError{http.StatusUnauthorized, "insufficient_scope", "scope is missing: " + action}
+}
+
+func (m Micropub) create(req micropubRequest) (string, *micropubError) {
+	if len(req.Type) > 0 && req.Type[0] != "h-entry" {
+		return "", &micropubError{http.StatusBadRequest, "invalid_request", "unsupported type: " + req.Type[0]}
+	}
+
+	loc, err := m.options.config.location()
+	if err != nil {
+		return "", &micropubError{http.StatusInternalServerError, "server_error", err.Error()}
+	}
+
+	props := req.Properties
+	post := Post{Title: propString(props, "name"), Content: propString(props, "content"),
+		Tags: propStrings(props, "category"), Written: time.Now().In(loc)}
+	if post.Content == "" {
+		return "", &micropubError{http.StatusBadRequest, "invalid_request", "content is missing"}
+	}
+	if post.Title == "" {
+		post.Title = noteTitle(post.Content)
+	}
+	if published := propString(props, "published"); published != "" {
+		post.Written, err = time.Parse(time.RFC3339, published)
+		if err != nil {
+			post.Written, err = parseDate(published, loc)
+		}
+		if err != nil {
+			return "", &micropubError{http.StatusBadRequest, "invalid_request", err.Error()}
+		}
+	}
+
+	frontMatter := map[string]interface{}{}
+	if summary := propString(props, "summary"); summary != "" {
+		frontMatter["summary"] = summary
+	}
+	if propString(props, "visibility") == "unlisted" {
+		frontMatter["unlisted"] = true
+	}
+
+	g := StaticBlogGenerator{options: m.options}
+	name := m.options.slug(post.Title)
+	untitled := name == ""
+	if untitled {
+		// titles without a slug (for example only emoji) would make a hidden
+		// file, so the post is named by its date
+		name = post.Written.Format("2006-01-02-150405")
+	}
+	if propString(props, "post-status") == "draft" {
+		name = draftDir + "/" + name
+		post.Draft = true
+	}
+	name, err = m.freeName(name)
+	if err != nil {
+		return "", &micropubError{http.StatusInternalServerError, "server_error", err.Error()}
+	}
+	if untitled {
+		post.Output = filepath.ToSlash(g.pagePath(strings.TrimSuffix(path.Base(name), ".md")))
+		frontMatter["output"] = post.Output
+	}
+
+	if werr := m.write(name, frontMatter, post); werr != nil {
+		return "", werr
+	}
+
+	return g.absPageURL(g.postPath(post)), nil
+}
+
+func (m Micropub) update(req micropubRequest) *micropubError {
+	name, post, frontMatter, err := m.find(req.URL)
+	if err != nil {
+		return err
+	}
+
+	for prop, values := range req.Replace {
+		setProperty(&post, frontMatter, prop, values, false)
+	}
+	for prop, values := range req.Add {
+		setProperty(&post, frontMatter, prop, values, true)
+	}
+
+	switch deleted := req.Delete.(type) {
+	case []interface{}:
+		for _, prop := range deleted {
+			if s, ok := prop.(string); ok {
+				setProperty(&post, frontMatter, s, nil, false)
+			}
+		}
+	case map[string]interface{}:
+		for prop, values := range deleted {
+			if prop != "category" {
+				continue
+			}
+			remove := map[string]bool{}
+			if values, ok := values.([]interface{}); ok {
+				for _, v := range values {
+					remove[fmt.Sprint(v)] = true
+				}
+			}
+			var tags []string
+			for _, tag := range post.Tags {
+				if !remove[tag] {
+					tags = append(tags, tag)
+				}
+			}
+			post.Tags = tags
+		}
+	}
+
+	return m.write(name, frontMatter, post)
+}
+
+// setProperty replaces (or adds values to) the property of the Post.
+func setProperty(post *Post, frontMatter map[string]interface{}, prop string,
+	values []interface{}, add bool) {
+	props := map[string][]interface{}{prop: values}
+	switch prop {
+	case "name":
+		post.Title = propString(props, prop)
+	case "content":
+		if add {
+			post.Content += "\n\n" + propString(props, prop)
+		} else {
+			post.Content = propString(props, prop)
+		}
+	case "category":
+		if add {
+			post.Tags = append(post.Tags, propStrings(props, prop)...)
+		} else {
+			post.Tags = propStrings(props, prop)
+		}
+	case "summary":
+		if summary := propString(props, prop); summary != "" {
+			frontMatter["summary"] = summary
+		} else {
+			delete(frontMatter, "summary")
+		}
+	}
+}
+
+// find returns the file name, the Post and the front matter of the Markdown
+// file of the Post with the URL. Published Posts are preferred to drafts with
+// the same URL.
+func (m Micropub) find(postURL string) (string, Post, map[string]interface{}, *micropubError) {
+	u, err := url.Parse(postURL)
+	if err != nil || postURL == "" {
+		return "", Post{}, nil, &micropubError{http.StatusBadRequest, "invalid_request", "invalid url"}
+	}
+	wanted := m.options.config.trimBasePath(u.Path)
+	if wanted == "" || strings.HasSuffix(wanted, "/") {
+		wanted += "index.html"
+	}
+
+	loc, err := m.options.config.location()
+	if err != nil {
+		return "", Post{}, nil, &micropubError{http.StatusInternalServerError, "server_error", err.Error()}
+	}
+
+	files, err := m.store.List()
+	if err != nil {
+		return "", Post{}, nil, &micropubError{http.StatusInternalServerError, "server_error", err.Error()}
+	}
+	sort.SliceStable(files, func(i, j int) bool {
+		return !strings.HasPrefix(files[i].Name, draftDir+"/") &&
+			strings.HasPrefix(files[j].Name, draftDir+"/")
+	})
+
+	g := StaticBlogGenerator{options: m.options}
+	for _, file := range files {
+		if strings.ToLower(path.Ext(file.Name)) != ".md" {
+			continue
+		}
+
+		source, err := m.store.Read(file.Name)
+		if err != nil {
+			continue
+		}
+		post, err := markdownToPost(string(source), loc)
+		if err != nil {
+			continue
+		}
+		if dir := path.Dir(strings.TrimPrefix(file.Name, draftDir+"/")); dir != "." {
+			post.Dir = dir
+		}
+		if g.postURL(post) != u.Path && "/"+filepath.ToSlash(g.postPath(post)) != wanted {
+			continue
+		}
+
+		frontMatter := map[string]interface{}{}
+		_, err = cutFrontMatter(strings.ReplaceAll(string(source), "\r\n", "\n"), &frontMatter)
+		if err != nil {
+			continue
+		}
+		return file.Name, post, frontMatter, nil
+	}
+	return "", Post{}, nil, &micropubError{http.StatusBadRequest, "invalid_request", "post not found: " + postURL}
+}
+
+// freeName returns the name (with the .md extension) or, if it's used, the
+// name with the first free numeric suffix.
+func (m Micropub) freeName(name string) (string, error) {
+	files, err := m.store.List()
+	if err != nil {
+		return "", err
+	}
+	used := map[string]bool{}
+	for _, file := range files {
+		used[file.Name] = true
+	}
+
+	free := name + ".md"
+	for i := 2; used[free]; i++ {
+		free = fmt.Sprintf("%s-%d.md", name, i)
+	}
+	return free, nil
+}
+
+func (m Micropub) write(name string, frontMatter map[string]interface{}, post Post) *micropubError {
+	source, err := postToMarkdown(frontMatter, post)
+	if err == nil {
+		err = m.store.Write(name, []byte(source))
+	}
+	if err != nil {
+		return &micropubError{http.StatusInternalServerError, "server_error", err.Error()}
+	}
+	return nil
+}
+
+// postToMarkdown returns the source of a Markdown post (see MarkdownBlog) with
+// the front matter.
+func postToMarkdown(frontMatter map[string]interface{}, post Post) (string, error) {
+	var b strings.Builder
+	if len(frontMatter) > 0 {
+		bytes, err := yaml.Marshal(frontMatter)
+		if err != nil {
+			return "", err
+		}
+		b.WriteString(frontMatterDelim + "\n" + string(bytes) + frontMatterDelim + "\n\n")
+	}
+
+	b.WriteString("# " + post.Title + "\n\n")
+	b.WriteString(post.Written.Format("*Jan 2, 2006*") + "\n\n")
+	// the tags line is written even without tags, so a first paragraph of
+	// the content that's a single emphasized line isn't read as tags
+	b.WriteString("*" + strings.Join(post.Tags, ", ") + "*\n\n")
+	if post.IsPage {
+		b.WriteString("*page*\n\n")
+	}
+	b.WriteString(strings.TrimSpace(post.Content) + "\n")
+	return b.String(), nil
+}
+
+// noteTitle returns the first words of the content of a note (a post without a
+// name).
+func noteTitle(content string) string {
+	words := strings.Fields(stripTags(string(html(content))))
+	if len(words) > 8 {
+		return strings.Join(words[:8], " ") + "…"
+	}
+	return strings.Join(words, " ")
+}
+
+// propString returns the first value of the property as a string. Values
+// with the html (or value) key are supported, too.
+func propString(props map[string][]interface{}, name string) string {
+	values := props[name]
+	if len(values) == 0 {
+		return ""
+	}
+
+	switch v := values[0].(type) {
+	case string:
+		return strings.TrimSpace(v)
+	case map[string]interface{}:
+		for _, key := range []string{"html", "value"} {
+			if s, ok := v[key].(string); ok {
+				return strings.TrimSpace(s)
+			}
+		}
+	}
+	return ""
+}
+
+// propStrings returns the string values of the property.
+func propStrings(props map[string][]interface{}, name string) []string {
+	var values []string
+	for _, v := range props[name] {
+		if s, ok := v.(string); ok && strings.TrimSpace(s) != "" {
+			values = append(values, strings.TrimSpace(s))
+		}
+	}
+	return values
+}
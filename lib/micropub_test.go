@@ -0,0 +1,156 @@
+package lib
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMicropub(t *testing.T) {
+	dir := t.TempDir()
+	changes := 0
+	m, err := NewMicropub(NewDirStore(dir), func() error { changes++; return nil },
+		WithConfig(Config{URL: "https://example.com", Micropub: MicropubConfig{Token: "secret"}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	post := func(token, contentType, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/micropub", strings.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		m.ServeHTTP(rec, req)
+		return rec
+	}
+
+	form := url.Values{"h": {"entry"}, "name": {"Rome"}, "content": {"Rome"},
+		"category[]": {"Italy", "Cities"}}.Encode()
+	if rec := post("wrong", "application/x-www-form-urlencoded", form); rec.Code != http.StatusForbidden {
+		t.Errorf("want %v, got %v", http.StatusForbidden, rec.Code)
+	}
+
+	rec := post("secret", "application/x-www-form-urlencoded", form)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("want %v, got %v", http.StatusCreated, rec.Code)
+	}
+	if want, got := "https://example.com/rome.html", rec.Header().Get("Location"); want != got {
+		t.Errorf("want %v, got %v", want, got)
+	}
+
+	rec = post("secret", "application/json",
+		`{"type": ["h-entry"], "properties": {"name": ["Rome"], "content": [{"html": "<p>Rome</p>"}],
+			"post-status": ["draft"]}}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("want %v, got %v", http.StatusCreated, rec.Code)
+	}
+	if _, err := os.Stat(filepath.Join(dir, draftDir, "rome.md")); err != nil {
+		t.Errorf("want %v, got %v", "draft/rome.md", err)
+	}
+
+	rec = post("secret", "application/json",
+		`{"action": "update", "url": "https://example.com/rome.html",
+			"replace": {"content": ["Roma"]}, "delete": {"category": ["Cities"]}}`)
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("want %v, got %v", http.StatusNoContent, rec.Code)
+	}
+
+	blog, err := NewStoreBlog(NewDirStore(dir)).Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(blog) != 2 {
+		t.Fatalf("want %v, got %v", 2, len(blog))
+	}
+	for _, p := range blog {
+		if p.Draft {
+			continue
+		}
+		if strings.TrimSpace(p.Content) != "Roma" || len(p.Tags) != 1 || p.Tags[0] != "Italy" {
+			t.Errorf("want %v, got %v", "Roma [Italy]", p)
+		}
+	}
+
+	if changes != 3 {
+		t.Errorf("want %v, got %v", 3, changes)
+	}
+}
+
+func TestNoteTitle(t *testing.T) {
+	for content, want := range map[string]string{
+		"Just landed in *Rome*.":                           "Just landed in Rome.",
+		"One two three four five six seven eight nine ten": "One two three four five six seven eight…",
+	} {
+		if got := noteTitle(content); got != want {
+			t.Errorf("want %v, got %v", want, got)
+		}
+	}
+}
+
+func TestMicropubCreate(t *testing.T) {
+	dir := t.TempDir()
+	m, err := NewMicropub(NewDirStore(dir), nil,
+		WithConfig(Config{URL: "https://example.com", Micropub: MicropubConfig{Token: "secret"}}),
+		WithSlugger(func(s string) string { return strings.ToUpper(SlugConfig{}.slugify(s)) }))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	post := func(token, contentType, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/micropub", strings.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		m.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// requests are authenticated before their bodies are parsed
+	if rec := post("wrong", "application/json", "{"); rec.Code != http.StatusForbidden {
+		t.Errorf("want %v, got %v", http.StatusForbidden, rec.Code)
+	}
+	large := `{"properties": {"content": ["` + strings.Repeat("a", micropubMaxBody) + `"]}}`
+	if rec := post("secret", "application/json", large); rec.Code != http.StatusBadRequest {
+		t.Errorf("want %v, got %v", http.StatusBadRequest, rec.Code)
+	}
+
+	rec := post("secret", "application/x-www-form-urlencoded",
+		url.Values{"name": {"Rome"}, "content": {"*Veni, vidi*\n\nvici"}}.Encode())
+	if want, got := "https://example.com/ROME.html", rec.Header().Get("Location"); want != got {
+		t.Errorf("want %v, got %v", want, got)
+	}
+
+	rec = post("secret", "application/x-www-form-urlencoded",
+		url.Values{"content": {"🎉"}, "published": {"2024-05-01T10:00:00Z"}}.Encode())
+	if want, got := "https://example.com/2024-05-01-100000.html", rec.Header().Get("Location"); want != got {
+		t.Errorf("want %v, got %v", want, got)
+	}
+
+	blog, err := NewStoreBlog(NewDirStore(dir)).Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(blog) != 2 {
+		t.Fatalf("want %v, got %v", 2, blog)
+	}
+	for _, p := range blog {
+		switch p.Title {
+		case "Rome":
+			if len(p.Tags) != 0 || p.Content != "*Veni, vidi*\n\nvici\n" {
+				t.Errorf("want %q without tags, got %q %v", "*Veni, vidi*\n\nvici\n", p.Content, p.Tags)
+			}
+		case "🎉":
+			if p.Output != "2024-05-01-100000.html" {
+				t.Errorf("want %v, got %v", "2024-05-01-100000.html", p.Output)
+			}
+		default:
+			t.Errorf("want Rome or 🎉, got %v", p.Title)
+		}
+	}
+}
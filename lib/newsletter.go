@@ -0,0 +1,247 @@
+package lib
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"html/template"
+	"mime"
+	"net/smtp"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// NewsletterTemplate is the name of the optional template of newsletters. It
+// gets the newsletter's Posts.
+const NewsletterTemplate = "newsletter.tmpl"
+
+const defaultDigestDays = 7
+
+const defaultNewsletterTemplate = `<!DOCTYPE html>
+<html>
+  <head>
+    <meta charset="utf-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+  </head>
+  <body>
+    {{range .}}
+      <h1><a href="{{postURL .}}">{{.Title}}</a></h1>
+      {{. | html}}
+      <hr>
+    {{end}}
+    <p><a href="/">{{site.Title}}</a></p>
+  </body>
+</html>
+`
+
+// defaultNewsletterStyles are inlined into newsletters' tags because many
+// email clients ignore style sheets.
+var defaultNewsletterStyles = map[string]string{
+	"body":       "margin:0;padding:24px;color:#222222;font-family:Helvetica,Arial,sans-serif;font-size:16px;line-height:1.5",
+	"h1":         "font-size:28px;line-height:1.2;margin:0 0 16px",
+	"h2":         "font-size:22px;line-height:1.3;margin:24px 0 12px",
+	"h3":         "font-size:18px;margin:24px 0 12px",
+	"p":          "margin:0 0 16px",
+	"a":          "color:#1a73e8",
+	"img":        "max-width:100%;height:auto",
+	"pre":        "background:#f5f5f5;padding:12px;overflow:auto;font-size:14px",
+	"blockquote": "margin:0 0 16px;padding-left:16px;border-left:3px solid #cccccc;color:#555555",
+	"hr":         "border:none;border-top:1px solid #dddddd;margin:32px 0",
+}
+
+// NewsletterConfig holds settings of newsletters.
+type NewsletterConfig struct {
+	// DigestDays is the number of days covered by digests (7 if it's 0).
+	DigestDays int `yaml:"digestDays"`
+
+	// Styles are inline styles (CSS declarations) keyed by tag names. They
+	// replace the default styles of the tags.
+	Styles map[string]string `yaml:"styles"`
+
+	// SMTP is the mail server newsletters are sent with.
+	SMTP SMTPConfig `yaml:"smtp"`
+
+	// Command is a shell command newsletters are sent with instead of SMTP
+	// (for example a script calling an email provider's API). It gets the
+	// newsletter's HTML on the standard input and its subject in the
+	// LITEPUB_SUBJECT environment variable.
+	Command string `yaml:"command"`
+}
+
+// SMTPConfig holds the settings of a mail server. The Password is read from
+// the LITEPUB_SMTP_PASSWORD environment variable if it's empty.
+type SMTPConfig struct {
+	Host     string   `yaml:"host"`
+	Port     int      `yaml:"port"`
+	Username string   `yaml:"username"`
+	Password string   `yaml:"password"`
+	From     string   `yaml:"from"`
+	To       []string `yaml:"to"`
+}
+
+// Newsletter is an email-ready HTML version of Posts.
+type Newsletter struct {
+	Subject string
+	HTML    string
+	Posts   []Post
+}
+
+// Newsletter creates a Newsletter with the latest Post or, if digest ==
+// true, with the Posts written in the last DigestDays before now. Pages and
+// password protected Posts aren't included. Styles are inlined and URLs are absolute, so the Blog's
+// URL must be set.
+func (g StaticBlogGenerator) Newsletter(digest bool, now time.Time) (Newsletter, error) {
+	config := g.options.config.Newsletter
+	if g.options.config.URL == "" {
+		return Newsletter{}, errors.New("url isn't set in the config")
+	}
+
+	var posts []Post
+	for _, post := range g.posts {
+		if !post.IsPage && post.Password == "" {
+			posts = append(posts, post)
+		}
+	}
+
+	var newsletter Newsletter
+	if digest {
+		days := config.DigestDays
+		if days <= 0 {
+			days = defaultDigestDays
+		}
+
+		since := now.AddDate(0, 0, -days)
+		for _, post := range posts {
+			if post.Written.After(since) && !post.Written.After(now) {
+				newsletter.Posts = append(newsletter.Posts, post)
+			}
+		}
+		newsletter.Subject = fmt.Sprintf("%s: %d new post(s)", g.options.config.Title,
+			len(newsletter.Posts))
+	} else if len(posts) > 0 {
+		newsletter.Posts = posts[:1]
+		newsletter.Subject = posts[0].Title
+	}
+	if len(newsletter.Posts) == 0 {
+		return Newsletter{}, errors.New("no posts for the newsletter")
+	}
+
+	tmpl, err := g.newsletterTemplate()
+	if err != nil {
+		return Newsletter{}, fmt.Errorf("failed to parse newsletter template: %s", err)
+	}
+
+	var b bytes.Buffer
+	err = tmpl.Execute(&b, newsletter.Posts)
+	if err != nil {
+		return Newsletter{}, fmt.Errorf("failed to render newsletter: %s", err)
+	}
+
+	newsletter.HTML = g.inlineStyles(g.absoluteURLs(b.String()))
+	return newsletter, nil
+}
+
+func (g StaticBlogGenerator) newsletterTemplate() (*template.Template, error) {
+	tmpl := template.New(NewsletterTemplate).Funcs(g.funcs())
+
+	path := filepath.Join(g.templatesDir, NewsletterTemplate)
+	if _, err := os.Stat(path); err == nil {
+		return tmpl.ParseFiles(path)
+	}
+	return tmpl.Parse(defaultNewsletterTemplate)
+}
+
+// absoluteURLs makes the URLs of links and images in the HTML absolute.
+func (g StaticBlogGenerator) absoluteURLs(html string) string {
+	for tag, attr := range map[string]string{"a": "href", "img": "src"} {
+		html = rewriteStartTags(html, tag, func(attrs *attributes) {
+			value, _ := attrs.get(attr)
+			if url := g.internalURL("/", value); url != "" {
+				attrs.set(attr, g.absURL(strings.TrimPrefix(url, "/")))
+			}
+		})
+	}
+	return html
+}
+
+// inlineStyles adds the default styles (or the configured ones) to the
+// tags in the HTML. Styles already set on the tags take precedence.
+func (g StaticBlogGenerator) inlineStyles(html string) string {
+	styles := map[string]string{}
+	for tag, style := range defaultNewsletterStyles {
+		styles[tag] = style
+	}
+	for tag, style := range g.options.config.Newsletter.Styles {
+		styles[tag] = style
+	}
+
+	for tag, style := range styles {
+		html = rewriteStartTags(html, tag, func(attrs *attributes) {
+			existing, _ := attrs.get("style")
+			attrs.set("style", strings.TrimSuffix(style, ";")+";"+existing)
+		})
+	}
+	return html
+}
+
+// Send sends the Newsletter with the Command or, if it isn't set, via SMTP.
+func (n Newsletter) Send(config NewsletterConfig) error {
+	if config.Command != "" {
+		cmd := exec.Command("sh", "-c", config.Command)
+		cmd.Stdin = strings.NewReader(n.HTML)
+		cmd.Env = append(os.Environ(), "LITEPUB_SUBJECT="+n.Subject)
+
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("failed to run %s: %s: %s", config.Command, err,
+				strings.TrimSpace(string(out)))
+		}
+		return nil
+	}
+
+	smtpConfig := config.SMTP
+	if smtpConfig.Host == "" || smtpConfig.From == "" || len(smtpConfig.To) == 0 {
+		return errors.New("neither command nor smtp host, from and to are set")
+	}
+	if smtpConfig.Port == 0 {
+		smtpConfig.Port = 587
+	}
+	if smtpConfig.Password == "" {
+		smtpConfig.Password = os.Getenv("LITEPUB_SMTP_PASSWORD")
+	}
+
+	var auth smtp.Auth
+	if smtpConfig.Username != "" {
+		auth = smtp.PlainAuth("", smtpConfig.Username, smtpConfig.Password, smtpConfig.Host)
+	}
+
+	addr := smtpConfig.Host + ":" + strconv.Itoa(smtpConfig.Port)
+	return smtp.SendMail(addr, auth, smtpConfig.From, smtpConfig.To,
+		n.message(smtpConfig.From, smtpConfig.To))
+}
+
+// message returns the Newsletter as an email message.
+func (n Newsletter) message(from string, to []string) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", n.Subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&b, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&b, "Content-Type: text/html; charset=utf-8\r\n")
+	fmt.Fprintf(&b, "Content-Transfer-Encoding: base64\r\n\r\n")
+
+	encoded := base64.StdEncoding.EncodeToString([]byte(n.HTML))
+	for len(encoded) > 76 {
+		b.WriteString(encoded[:76] + "\r\n")
+		encoded = encoded[76:]
+	}
+	b.WriteString(encoded + "\r\n")
+
+	return b.Bytes()
+}
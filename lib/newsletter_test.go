@@ -0,0 +1,74 @@
+package lib
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewsletterDigest(t *testing.T) {
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	g := StaticBlogGenerator{templatesDir: t.TempDir(), dates: dateFormatter{location: time.UTC},
+		options: options{config: Config{URL: "https://example.com", Title: "Cities"}}}
+	g.posts = []Post{
+		{Title: "Rome", Content: "See [Paris](/paris.html).", Written: now.AddDate(0, 0, -1)},
+		{Title: "About", Content: "A page.", Written: now.AddDate(0, 0, -2), IsPage: true},
+		{Title: "Paris", Content: "![Paris](paris.jpg)", Written: now.AddDate(0, 0, -3)},
+		{Title: "London", Content: "Old.", Written: now.AddDate(0, 0, -30)},
+	}
+
+	newsletter, err := g.Newsletter(true, now)
+	if err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+
+	if newsletter.Subject != "Cities: 2 new post(s)" {
+		t.Errorf("want %q, got %q", "Cities: 2 new post(s)", newsletter.Subject)
+	}
+	for _, want := range []string{
+		`<a href="https://example.com/paris.html" style="color:#1a73e8;">Paris</a>`,
+		`<img src="https://example.com/paris.jpg" alt="Paris" style="max-width:100%;height:auto;" />`,
+	} {
+		if !strings.Contains(newsletter.HTML, want) {
+			t.Errorf("want %q in %q", want, newsletter.HTML)
+		}
+	}
+	if strings.Contains(newsletter.HTML, "London") || strings.Contains(newsletter.HTML, "A page.") {
+		t.Errorf("want only posts of the last week, got %q", newsletter.HTML)
+	}
+
+	latest, err := g.Newsletter(false, now)
+	if err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+	if latest.Subject != "Rome" || len(latest.Posts) != 1 {
+		t.Errorf("want %v, got %v", "Rome", latest.Subject)
+	}
+}
+
+func TestNewsletterProtectedPosts(t *testing.T) {
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	g := StaticBlogGenerator{templatesDir: t.TempDir(), dates: dateFormatter{location: time.UTC},
+		options: options{config: Config{URL: "https://example.com", Title: "Cities"}}}
+	g.posts = []Post{
+		{Title: "Rome", Content: "The secret of Rome.", Written: now.AddDate(0, 0, -1),
+			Password: "secret"},
+		{Title: "Paris", Content: "Paris", Written: now.AddDate(0, 0, -2)},
+	}
+
+	newsletter, err := g.Newsletter(true, now)
+	if err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+	if len(newsletter.Posts) != 1 || strings.Contains(newsletter.HTML, "Rome") {
+		t.Errorf("want only Paris, got %q", newsletter.HTML)
+	}
+
+	latest, err := g.Newsletter(false, now)
+	if err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+	if latest.Subject != "Paris" {
+		t.Errorf("want %v, got %v", "Paris", latest.Subject)
+	}
+}
@@ -0,0 +1,221 @@
+package lib
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// Option configures a MarkdownBlog or a StaticBlogGenerator.
+type Option func(*options)
+
+type options struct {
+	config         Config
+	staticDir      string
+	warnFunc       func(message string)
+	snapshots      map[string]string
+	cacheDir       string
+	renderCacheDir string
+	before         []Hook
+	after          []Hook
+	fileHooks      []FileHook
+	progressEvents ProgressEventFunc
+	plugins        []*Plugin
+	transformers   []transformer
+	templates      *Templates
+	profile        *Profile
+	blogroll       Blogroll
+	build          Build
+	preview        bool
+	slugger        func(string) string
+	data           map[string]interface{}
+}
+
+// WithConfig sets the Config to use.
+func WithConfig(config Config) Option {
+	return func(o *options) {
+		o.config = config
+	}
+}
+
+// WithStaticDir sets the directory whose contents are copied verbatim to the
+// output directory of a StaticBlogGenerator. Its files take precedence over
+// the files of the templates directory and the generated files with the same
+// paths. It's ignored if the directory doesn't exist.
+func WithStaticDir(dir string) Option {
+	return func(o *options) {
+		o.staticDir = dir
+	}
+}
+
+// WithWarnFunc sets the function called with warnings (for example about
+// broken links) that don't stop reading or generating a Blog.
+func WithWarnFunc(warnFunc func(message string)) Option {
+	return func(o *options) {
+		o.warnFunc = warnFunc
+	}
+}
+
+// WithSnapshots sets the snapshot URLs of archived external links (see
+// Archiver) keyed by the links' URLs.
+func WithSnapshots(snapshots map[string]string) Option {
+	return func(o *options) {
+		o.snapshots = snapshots
+	}
+}
+
+// WithCacheDir sets the directory used for caching data between builds (for
+// example downloaded remote images). It's created when needed.
+func WithCacheDir(dir string) Option {
+	return func(o *options) {
+		o.cacheDir = dir
+	}
+}
+
+// WithRenderCacheDir sets the directory results of expensive renders are
+// cached in if the Config's RenderCache is set (see RenderCacheDir). Without
+// it they're cached in the cache directory.
+func WithRenderCacheDir(dir string) Option {
+	return func(o *options) {
+		o.renderCacheDir = dir
+	}
+}
+
+// WithBeforeHook adds a Hook run by a StaticBlogGenerator before generating a
+// Blog.
+func WithBeforeHook(hook Hook) Option {
+	return func(o *options) {
+		o.before = append(o.before, hook)
+	}
+}
+
+// WithAfterHook adds a Hook run by a StaticBlogGenerator after a Blog was
+// generated successfully.
+func WithAfterHook(hook Hook) Option {
+	return func(o *options) {
+		o.after = append(o.after, hook)
+	}
+}
+
+// WithFileHook adds a FileHook run by a StaticBlogGenerator on each file it
+// generates. FileHooks run in the order they were added.
+func WithFileHook(hook FileHook) Option {
+	return func(o *options) {
+		o.fileHooks = append(o.fileHooks, hook)
+	}
+}
+
+// WithProgressEvents sets the ProgressEventFunc reporting which files a build
+// of a StaticBlogGenerator changed.
+func WithProgressEvents(progressEvents ProgressEventFunc) Option {
+	return func(o *options) {
+		o.progressEvents = progressEvents
+	}
+}
+
+// WithPlugin adds a started Plugin used by a StaticBlogGenerator.
+func WithPlugin(plugin *Plugin) Option {
+	return func(o *options) {
+		o.plugins = append(o.plugins, plugin)
+	}
+}
+
+// WithTransformer adds a Transformer run by a StaticBlogGenerator after the
+// stage (for example StageMarkdown) of the pipeline rendering Posts.
+// Transformers added after the same stage run in the order they were added.
+func WithTransformer(after string, t Transformer) Option {
+	return func(o *options) {
+		o.transformers = append(o.transformers, transformer{after, t})
+	}
+}
+
+// WithTemplates sets the shared Templates used by a StaticBlogGenerator instead
+// of parsing the templates in its templates directory (if it's the
+// Templates' directory).
+func WithTemplates(templates Templates) Option {
+	return func(o *options) {
+		o.templates = &templates
+	}
+}
+
+// WithProfile sets the Profile recording timings of a StaticBlogGenerator.
+func WithProfile(profile *Profile) Option {
+	return func(o *options) {
+		o.profile = profile
+	}
+}
+
+// WithBlogroll sets the Blogroll whose page is generated and which is
+// exported by a StaticBlogGenerator.
+func WithBlogroll(blogroll Blogroll) Option {
+	return func(o *options) {
+		o.blogroll = blogroll
+	}
+}
+
+// WithBuild sets the Build metadata shown by templates of a
+// StaticBlogGenerator. By default it has just the Version and the time the
+// generator was created.
+func WithBuild(build Build) Option {
+	return func(o *options) {
+		o.build = build
+	}
+}
+
+// WithPreview makes a StaticBlogGenerator generate a preview of a Blog
+// including draft and scheduled Posts (see Config.ScheduledPosts).
+func WithPreview() Option {
+	return func(o *options) {
+		o.preview = true
+	}
+}
+
+// WithSlugger sets the function making the slugs of titles, tags and authors
+// (for example to match existing URLs using underscores or IDs). It replaces
+// the Config's SlugConfig.
+func WithSlugger(slugger func(string) string) Option {
+	return func(o *options) {
+		o.slugger = slugger
+	}
+}
+
+// WithData sets the data files available to Posts executed as templates (see
+// ReadData and Config.TemplatePosts).
+func WithData(data map[string]interface{}) Option {
+	return func(o *options) {
+		o.data = data
+	}
+}
+
+// cachePath returns the path of the file with the name in the directory in
+// the cache directory (or in the system's temporary directory if there's no
+// cache directory).
+func (o options) cachePath(dir, name string) string {
+	cacheDir := o.cacheDir
+	if cacheDir == "" {
+		cacheDir = filepath.Join(os.TempDir(), "litepub")
+	}
+	return filepath.Join(cacheDir, dir, name)
+}
+
+// slug returns the slug of the string made by the slugger (see WithSlugger)
+// or according to the Config's SlugConfig.
+func (o options) slug(str string) string {
+	if o.slugger != nil {
+		return o.slugger(str)
+	}
+	return o.config.Slug.slugify(str)
+}
+
+func (o options) warn(message string) {
+	if o.warnFunc != nil {
+		o.warnFunc(message)
+	}
+}
+
+func newOptions(opts []Option) options {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
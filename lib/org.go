@@ -0,0 +1,105 @@
+package lib
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/niklasfasching/go-org/org"
+)
+
+// orgToPost parses a post written in Org mode. The front matter (see
+// frontMatter) is optional:
+//
+//	---
+//	updated: 2009-01-10
+//	---
+//
+//	#+TITLE: Title
+//	#+DATE: <2009-01-02 Fri>
+//	#+FILETAGS: :tag1:tag2:
+//	#+PAGE: t
+//
+//	Content
+//
+// The date can also be in any format accepted by parsePostDate. Instead of #+FILETAGS comma separated #+TAGS can be used.
+func orgToPost(source string, loc *time.Location) (Post, error) {
+	fm, doc, err := splitFrontMatter(strings.ReplaceAll(source, "\r\n", "\n"))
+	if err != nil {
+		return Post{}, err
+	}
+
+	conf := org.New()
+	conf.Log = log.New(io.Discard, "", 0)
+	conf.DefaultSettings["OPTIONS"] = "toc:nil <:t e:t f:t pri:t todo:t tags:t title:nil ealb:nil"
+
+	document := conf.Parse(strings.NewReader(doc), "")
+	if document.Error != nil {
+		return Post{}, fmt.Errorf("failed to parse Org document: %s", document.Error)
+	}
+
+	post := Post{Title: strings.TrimSpace(document.Get("TITLE")), Format: FormatHTML}
+	if post.Title == "" {
+		return Post{}, fmt.Errorf("title is missing")
+	}
+
+	post.Written, err = parseOrgDate(document.Get("DATE"), loc)
+	if err != nil {
+		return Post{}, fmt.Errorf("failed to parse date: %s", err)
+	}
+
+	if tags := document.Get("FILETAGS"); tags != "" {
+		post.Tags = splitTags(tags, ":")
+	} else if tags := document.Get("TAGS"); tags != "" {
+		post.Tags = splitTags(tags, ",")
+	}
+
+	page := strings.TrimSpace(document.Get("PAGE"))
+	post.IsPage = page != "" && page != "nil"
+
+	post.Content, err = document.Write(org.NewHTMLWriter())
+	if err != nil {
+		return Post{}, fmt.Errorf("failed to convert Org document: %s", err)
+	}
+
+	err = fm.apply(&post, loc)
+	if err != nil {
+		return Post{}, err
+	}
+
+	return post, nil
+}
+
+// parseOrgDate parses an Org timestamp (like <2009-01-02 Fri 10:00>) or
+// a date accepted by parsePostDate.
+func parseOrgDate(value string, loc *time.Location) (time.Time, error) {
+	value = strings.Trim(strings.TrimSpace(value), "<>[]")
+	if value == "" {
+		return time.Time{}, fmt.Errorf("date is missing")
+	}
+
+	if t, err := parsePostDate(value, loc); err == nil {
+		return t, nil
+	}
+
+	fields := strings.Fields(value)
+	if len(fields) > 2 {
+		// <2009-01-02 Fri 10:00>
+		if t, err := parseDate(fields[0]+" "+fields[2], loc); err == nil {
+			return t, nil
+		}
+	}
+	return parseDate(fields[0], loc)
+}
+
+func splitTags(tags, sep string) []string {
+	var split []string
+	for _, tag := range strings.Split(tags, sep) {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			split = append(split, tag)
+		}
+	}
+	return split
+}
@@ -0,0 +1,34 @@
+package lib
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOrgToPost(t *testing.T) {
+	source := "#+TITLE: A title\n#+DATE: <2021-08-10 Tue>\n#+FILETAGS: :Test:Org:\n\nTesting *Org*\n"
+
+	post, err := orgToPost(source, time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if post.Title != "A title" {
+		t.Errorf("want %v, got %v", "A title", post.Title)
+	}
+
+	want := time.Date(2021, 8, 10, 0, 0, 0, 0, time.UTC)
+	if !post.Written.Equal(want) {
+		t.Errorf("want %v, got %v", want, post.Written)
+	}
+
+	if len(post.Tags) != 2 || post.Tags[0] != "Test" || post.Tags[1] != "Org" {
+		t.Errorf("want %v, got %v", []string{"Test", "Org"}, post.Tags)
+	}
+
+	if !strings.Contains(post.Content, "<strong>Org</strong>") ||
+		strings.Contains(post.Content, "A title") {
+		t.Errorf("want content without title, got %q", post.Content)
+	}
+}
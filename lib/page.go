@@ -0,0 +1,166 @@
+package lib
+
+import (
+	"html/template"
+	"io"
+	"path/filepath"
+	"time"
+)
+
+// PageKind is the kind of a Page.
+type PageKind string
+
+const (
+	PageIndex     PageKind = "index"
+	PageTag       PageKind = "tag"
+	PageTags      PageKind = "tags"
+	PageArchive   PageKind = "archive"
+	PageAuthor    PageKind = "author"
+	PageBlogroll  PageKind = "blogroll"
+	PageNotFound  PageKind = "404"
+	PagePost      PageKind = "post"
+	PagePrint     PageKind = "print"
+	PageTombstone PageKind = "tombstone"
+)
+
+// pageStages are the build stages generating the Pages of the kinds in the
+// order they're generated.
+var pageStages = []struct {
+	kind  PageKind
+	stage string
+}{
+	{PageIndex, "index"},
+	{PageTag, "tags"},
+	{PageTags, "tag index"},
+	{PageArchive, "archive page"},
+	{PageAuthor, "authors"},
+	{PageBlogroll, "blogroll page"},
+	{PageNotFound, "404 page"},
+	{PagePost, "posts"},
+	{PagePrint, "print pages"},
+	{PageTombstone, "tombstones"},
+}
+
+// Page is a page of the Blog generated by executing a template with data.
+// All pages generated from templates are Pages, so features applying to all
+// of them need to handle only Pages.
+type Page struct {
+	Kind PageKind
+
+	// Path is the path of the page's file in the output directory.
+	Path string
+
+	// Permalink is the URL of the page relative to the Blog's root.
+	Permalink string
+
+	// Data is the data the Template is executed with, for example the Post of
+	// a post page or the Posts of the index.
+	Data     interface{}
+	Template *template.Template
+}
+
+func (g StaticBlogGenerator) newPage(kind PageKind, template *template.Template,
+	path string, data interface{}) Page {
+	return Page{Kind: kind, Path: path, Permalink: g.pageURL(path), Data: data,
+		Template: template}
+}
+
+// pages returns the Pages of the Blog in the order of the pageStages.
+// Optional pages are included only if their templates exist.
+func (g StaticBlogGenerator) pages() []Page {
+	pages := []Page{g.newPage(PageIndex, g.indexTemplate, "index.html", Posts(g.indexPosts()))}
+
+	for _, tag := range g.tags {
+		template := g.tagTemplate
+		if override, ok := g.tagTemplates[tag.Slug]; ok {
+			template = override
+		}
+		pages = append(pages, g.newPage(PageTag, template, g.tagPath(tag.Name), tag))
+	}
+
+	if g.tagsTemplate != nil {
+		pages = append(pages, g.newPage(PageTags, g.tagsTemplate,
+			filepath.Join("tags", "index.html"), g.tags))
+	}
+
+	if g.archiveTemplate != nil {
+		pages = append(pages, g.newPage(PageArchive, g.archiveTemplate,
+			g.pagePath("archive"), Posts(g.posts)))
+	}
+
+	if g.authorTemplate != nil {
+		for _, author := range g.authors {
+			pages = append(pages, g.newPage(PageAuthor, g.authorTemplate,
+				g.authorPath(author.Name), author))
+		}
+	}
+
+	if g.blogrollTemplate != nil && len(g.options.blogroll) > 0 {
+		pages = append(pages, g.newPage(PageBlogroll, g.blogrollTemplate,
+			g.pagePath("blogroll"), g.options.blogroll))
+	}
+
+	if g.notFoundTemplate != nil {
+		pages = append(pages, g.newPage(PageNotFound, g.notFoundTemplate, "404.html", Posts(g.posts)))
+	}
+
+	for _, post := range append(g.posts[:len(g.posts):len(g.posts)], g.unlisted...) {
+		template := g.postTemplate
+		if post.Link != "" && g.linkTemplate != nil {
+			template = g.linkTemplate
+		}
+		pages = append(pages, g.newPage(PagePost, template, g.postPath(post), post))
+	}
+
+	if g.printTemplate != nil {
+		for _, post := range append(g.posts[:len(g.posts):len(g.posts)], g.unlisted...) {
+			pages = append(pages, g.newPage(PagePrint, g.printTemplate, g.printPath(post), post))
+		}
+	}
+
+	if g.options.config.Tombstones {
+		template := g.expiredTemplate
+		if template == nil {
+			template = tombstoneTemplate
+		}
+		for _, post := range g.expired {
+			pages = append(pages, g.newPage(PageTombstone, template, g.postPath(post), post))
+		}
+	}
+
+	return pages
+}
+
+// generatePages generates the Pages of the kind.
+func (g StaticBlogGenerator) generatePages(pages []Page, kind PageKind) error {
+	for _, page := range pages {
+		if page.Kind != kind {
+			continue
+		}
+
+		err := g.generatePage(page)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (g StaticBlogGenerator) generatePage(page Page) error {
+	started := time.Now()
+	err := g.generateFile(page.Path, func(w io.Writer) error {
+		return page.Template.Execute(w, page.Data)
+	})
+	g.options.profile.addPage(page.Path, templateName(page.Template), time.Since(started))
+	return err
+}
+
+// hasPages tells whether there're Pages of the kind.
+func hasPages(pages []Page, kind PageKind) bool {
+	for _, page := range pages {
+		if page.Kind == kind {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,42 @@
+package lib
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPages(t *testing.T) {
+	blog := Blog{
+		{Title: "Rome", Tags: []string{"cities"}, Written: time.Now()},
+		{Title: "Paris", Written: time.Now(), Expires: time.Now().Add(-time.Hour)},
+	}
+
+	g, err := NewStaticBlogGenerator(blog, writeTestTemplates(t), t.TempDir(), func(string) {},
+		WithConfig(Config{Tombstones: true, URLStyle: URLStyleSlash}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Page{
+		{Kind: PageIndex, Path: "index.html", Permalink: "/"},
+		{Kind: PageTag, Path: "tags/cities/index.html", Permalink: "/tags/cities/"},
+		{Kind: PagePost, Path: "rome/index.html", Permalink: "/rome/"},
+		{Kind: PageTombstone, Path: "paris/index.html", Permalink: "/paris/"},
+	}
+	pages := g.pages()
+	if len(pages) != len(want) {
+		t.Fatalf("want %v, got %v", len(want), len(pages))
+	}
+	for i, page := range pages {
+		if page.Kind != want[i].Kind || page.Path != want[i].Path ||
+			page.Permalink != want[i].Permalink || page.Template == nil {
+			t.Errorf("want %+v, got %+v", want[i], page)
+		}
+	}
+	if post, ok := pages[2].Data.(Post); !ok || post.Title != "Rome" {
+		t.Errorf("want %v, got %v", "Rome", pages[2].Data)
+	}
+	if pages[3].Template != tombstoneTemplate {
+		t.Errorf("want the tombstone template, got %v", pages[3].Template.Name())
+	}
+}
@@ -0,0 +1,182 @@
+package lib
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// defaultPhotoSizes are the maximum widths and heights of the display sizes of
+// photos if the Config doesn't set them.
+var defaultPhotoSizes = []int{640, 1280}
+
+// Photo is the image of a photo post with the metadata extracted from its
+// EXIF data. Everything except the Src is set when the Blog is generated.
+type Photo struct {
+	// Src is the URL of the image relative to the Blog's root (or to the
+	// post's page bundle in the front matter).
+	Src    string
+	Width  int
+	Height int
+
+	// Sizes are the scaled down versions of the image (see Config.PhotoSizes)
+	// sorted by width.
+	Sizes []PhotoSize
+
+	Taken       time.Time
+	Camera      string
+	Lens        string
+	Exposure    string
+	Aperture    string
+	ISO         int
+	FocalLength string
+
+	// Latitude and Longitude are the location of the photo. They're set only
+	// if the Config's PhotoLocation is set and the image has the location (see
+	// HasLocation).
+	Latitude    float64
+	Longitude   float64
+	HasLocation bool
+}
+
+// PhotoSize is a scaled down version of a Photo.
+type PhotoSize struct {
+	Src    string
+	Width  int
+	Height int
+}
+
+// Srcset returns the Photo's Sizes and the Photo itself as the value of the
+// srcset attribute of an img element.
+func (p Photo) Srcset() string {
+	var candidates []string
+	for _, size := range p.Sizes {
+		candidates = append(candidates, fmt.Sprintf("%s %dw", size.Src, size.Width))
+	}
+	if p.Width > 0 {
+		candidates = append(candidates, fmt.Sprintf("%s %dw", p.Src, p.Width))
+	}
+	return strings.Join(candidates, ", ")
+}
+
+// processPhotos resolves the images of photo Posts, reads their EXIF data and
+// generates their display sizes.
+func (g StaticBlogGenerator) processPhotos() error {
+	loc, err := g.options.config.location()
+	if err != nil {
+		return err
+	}
+
+	sizes := g.options.config.PhotoSizes
+	if len(sizes) == 0 {
+		sizes = defaultPhotoSizes
+	}
+
+	for _, post := range append(g.posts[:len(g.posts):len(g.posts)], g.unlisted...) {
+		photo := post.Photo
+		if photo == nil || photo.Width > 0 {
+			continue
+		}
+
+		if post.Bundle != "" && !strings.HasPrefix(photo.Src, "/") {
+			photo.Src = "/" + path.Join(filepath.ToSlash(g.bundlePath(post)), photo.Src)
+		}
+		src := strings.TrimPrefix(path.Clean("/"+photo.Src), "/")
+		photo.Src = "/" + src
+
+		err := g.readPhoto(photo, filepath.Join(g.outputDir, filepath.FromSlash(src)), loc)
+		if err != nil {
+			return fmt.Errorf("failed to read photo of %s: %s", post.Title, err)
+		}
+
+		photo.Sizes = nil
+		for _, size := range sizes {
+			if size >= photo.Width && size >= photo.Height {
+				continue
+			}
+
+			ext := path.Ext(src)
+			scaled := fmt.Sprintf("%s-%d%s", strings.TrimSuffix(src, ext), size, ext)
+			width, height, err := g.generateScaled(src, scaled, size)
+			if err != nil {
+				return fmt.Errorf("failed to scale photo of %s: %s", post.Title, err)
+			}
+			photo.Sizes = append(photo.Sizes, PhotoSize{"/" + scaled, width, height})
+		}
+		sort.Slice(photo.Sizes, func(i, j int) bool {
+			return photo.Sizes[i].Width < photo.Sizes[j].Width
+		})
+	}
+	return nil
+}
+
+// readPhoto sets the dimensions and the EXIF metadata of the Photo from its
+// image file. Dates without a time zone are in the loc.
+func (g StaticBlogGenerator) readPhoto(photo *Photo, file string, loc *time.Location) error {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	photo.Width, photo.Height = config.Width, config.Height
+
+	tiff, err := jpegEXIF(data)
+	if err != nil || tiff == nil {
+		return nil
+	}
+	r, err := newEXIFReader(tiff)
+	if err != nil {
+		return nil
+	}
+
+	ifd0 := r.ifd(r.order.Uint32(tiff[4:]))
+	photo.Camera = strings.TrimSpace(r.string(ifd0[exifMake]) + " " + r.string(ifd0[exifModel]))
+	if model := r.string(ifd0[exifModel]); strings.HasPrefix(model, r.string(ifd0[exifMake])) {
+		photo.Camera = model
+	}
+
+	if offset, ok := r.uint(ifd0[exifIFDPointer], 0); ok {
+		exif := r.ifd(offset)
+		if taken, err := time.ParseInLocation("2006:01:02 15:04:05",
+			r.string(exif[exifDateTimeOriginal]), loc); err == nil {
+			photo.Taken = taken
+		}
+		photo.Lens = r.string(exif[exifLensModel])
+		if num, den, ok := r.rational(exif[exifExposureTime], 0); ok {
+			if num < den {
+				photo.Exposure = fmt.Sprintf("1/%.0fs", float64(den)/float64(num))
+			} else {
+				photo.Exposure = fmt.Sprintf("%gs", float64(num)/float64(den))
+			}
+		}
+		if num, den, ok := r.rational(exif[exifFNumber], 0); ok {
+			photo.Aperture = fmt.Sprintf("f/%g", float64(num)/float64(den))
+		}
+		if iso, ok := r.uint(exif[exifISO], 0); ok {
+			photo.ISO = int(iso)
+		}
+		if num, den, ok := r.rational(exif[exifFocalLength], 0); ok {
+			photo.FocalLength = fmt.Sprintf("%gmm", float64(num)/float64(den))
+		}
+	}
+
+	if offset, ok := r.uint(ifd0[exifGPSPointer], 0); ok && g.options.config.PhotoLocation {
+		gps := r.ifd(offset)
+		lat, latOK := r.coordinate(gps[gpsLatitude], r.string(gps[gpsLatitudeRef]))
+		lon, lonOK := r.coordinate(gps[gpsLongitude], r.string(gps[gpsLongitudeRef]))
+		if latOK && lonOK {
+			photo.Latitude, photo.Longitude, photo.HasLocation = lat, lon, true
+		}
+	}
+	return nil
+}
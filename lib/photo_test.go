@@ -0,0 +1,154 @@
+package lib
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/jpeg"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// testIFDEntry is an IFD entry of a test TIFF structure. If ifd is set the
+// entry points to the IFD with the index.
+type testIFDEntry struct {
+	tag, typ uint16
+	count    uint32
+	value    []byte
+	ifd      int
+}
+
+func asciiEntry(tag uint16, s string) testIFDEntry {
+	return testIFDEntry{tag: tag, typ: 2, count: uint32(len(s) + 1), value: []byte(s + "\x00")}
+}
+
+func rationalEntry(tag uint16, values ...uint32) testIFDEntry {
+	var value []byte
+	for _, v := range values {
+		value = binary.BigEndian.AppendUint32(value, v)
+	}
+	return testIFDEntry{tag: tag, typ: 5, count: uint32(len(values) / 2), value: value}
+}
+
+// testTIFF returns a big-endian TIFF structure with the IFDs (the first one is
+// IFD0).
+func testTIFF(ifds ...[]testIFDEntry) []byte {
+	order := binary.BigEndian
+	offsets := make([]int, len(ifds))
+	next := 8
+	for i, ifd := range ifds {
+		offsets[i] = next
+		next += 2 + 12*len(ifd) + 4
+	}
+
+	tiff := []byte("MM\x00\x2a\x00\x00\x00\x08")
+	var data []byte
+	for _, ifd := range ifds {
+		tiff = order.AppendUint16(tiff, uint16(len(ifd)))
+		for _, e := range ifd {
+			tiff = order.AppendUint16(tiff, e.tag)
+			tiff = order.AppendUint16(tiff, e.typ)
+			tiff = order.AppendUint32(tiff, e.count)
+
+			value := e.value
+			if e.ifd > 0 {
+				value = order.AppendUint32(nil, uint32(offsets[e.ifd]))
+			}
+			if len(value) > 4 {
+				tiff = order.AppendUint32(tiff, uint32(next+len(data)))
+				data = append(data, value...)
+			} else {
+				tiff = append(tiff, append(value, make([]byte, 4-len(value))...)...)
+			}
+		}
+		tiff = append(tiff, 0, 0, 0, 0)
+	}
+	return append(tiff, data...)
+}
+
+// writeEXIFJPEG writes a JPEG image with the dimensions and an APP1 segment
+// with the TIFF structure to the file.
+func writeEXIFJPEG(t *testing.T, file string, width, height int, tiff []byte) {
+	var b bytes.Buffer
+	jpeg.Encode(&b, image.NewRGBA(image.Rect(0, 0, width, height)), nil)
+
+	app1 := append([]byte{0xff, 0xe1}, binary.BigEndian.AppendUint16(nil, uint16(8+len(tiff)))...)
+	app1 = append(append(app1, "Exif\x00\x00"...), tiff...)
+
+	err := os.WriteFile(file, append(append(b.Bytes()[:2:2], app1...), b.Bytes()[2:]...), 0600)
+	if err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+}
+
+func TestProcessPhotos(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "photos"), 0700)
+	writeEXIFJPEG(t, filepath.Join(dir, "photos", "rome.jpg"), 1600, 1200, testTIFF(
+		[]testIFDEntry{
+			asciiEntry(exifMake, "Canon"),
+			asciiEntry(exifModel, "Canon EOS R6"),
+			{tag: exifIFDPointer, typ: 4, count: 1, ifd: 1},
+			{tag: exifGPSPointer, typ: 4, count: 1, ifd: 2},
+		},
+		[]testIFDEntry{
+			asciiEntry(exifDateTimeOriginal, "2024:05:01 18:30:00"),
+			rationalEntry(exifExposureTime, 1, 250),
+			rationalEntry(exifFNumber, 28, 10),
+			{tag: exifISO, typ: 3, count: 1, value: []byte{0x01, 0x90}},
+			rationalEntry(exifFocalLength, 35, 1),
+			asciiEntry(exifLensModel, "RF35mm F1.8"),
+		},
+		[]testIFDEntry{
+			asciiEntry(gpsLatitudeRef, "N"),
+			rationalEntry(gpsLatitude, 41, 1, 54, 1, 0, 1),
+			asciiEntry(gpsLongitudeRef, "E"),
+			rationalEntry(gpsLongitude, 12, 1, 30, 1, 0, 1),
+		}))
+
+	newGenerator := func(location bool) (StaticBlogGenerator, *Photo) {
+		photo := &Photo{Src: "photos/rome.jpg"}
+		return StaticBlogGenerator{outputDir: dir, progressFunc: func(string) {},
+			posts:      []Post{{Title: "Rome", Photo: photo}},
+			thumbnails: map[string]image.Point{},
+			options: options{config: Config{Timezone: "Europe/Rome",
+				PhotoSizes: []int{640, 2000}, PhotoLocation: location}, cacheDir: t.TempDir()}}, photo
+	}
+
+	g, photo := newGenerator(true)
+	if err := g.processPhotos(); err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+
+	rome, _ := time.LoadLocation("Europe/Rome")
+	want := Photo{Src: "/photos/rome.jpg", Width: 1600, Height: 1200,
+		Sizes: []PhotoSize{{"/photos/rome-640.jpg", 640, 480}},
+		Taken: time.Date(2024, 5, 1, 18, 30, 0, 0, rome), Camera: "Canon EOS R6",
+		Lens: "RF35mm F1.8", Exposure: "1/250s", Aperture: "f/2.8", ISO: 400,
+		FocalLength: "35mm", Latitude: 41.9, Longitude: 12.5, HasLocation: true}
+	if photo.Taken.Equal(want.Taken) {
+		photo.Taken = want.Taken
+	}
+	if !reflect.DeepEqual(*photo, want) {
+		t.Errorf("want %+v, got %+v", want, *photo)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "photos", "rome-640.jpg")); err != nil {
+		t.Errorf("want a display size, got %v", err)
+	}
+
+	if srcset := photo.Srcset(); srcset != "/photos/rome-640.jpg 640w, /photos/rome.jpg 1600w" {
+		t.Errorf("want %q, got %q", "/photos/rome-640.jpg 640w, /photos/rome.jpg 1600w", srcset)
+	}
+
+	g, photo = newGenerator(false)
+	if err := g.processPhotos(); err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+	if photo.HasLocation || photo.Latitude != 0 {
+		t.Errorf("want no location, got %v, %v", photo.Latitude, photo.Longitude)
+	}
+}
@@ -0,0 +1,284 @@
+package lib
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Hooks a Plugin can provide (see PluginInfo).
+const (
+	// PluginTransform plugins transform the Content of Posts before it's
+	// rendered.
+	PluginTransform = "transform"
+
+	// PluginProcess plugins process the generated HTML files.
+	PluginProcess = "process"
+
+	// PluginDeploy plugins deploy the output directory.
+	PluginDeploy = "deploy"
+)
+
+// Plugin is an external program extending LitePub. It runs as a child process
+// talking JSON-RPC (version 1.0) over its standard input and output, so it can
+// be written in any language. Plugins written in Go can use ServePlugin.
+//
+// A plugin provides the Plugin.Describe method returning its PluginInfo and
+// the methods of the hooks it provides:
+//
+//   - Plugin.Transform (PluginTransform) gets TransformArgs and returns the
+//     transformed Content
+//   - Plugin.Func gets FuncArgs of a template function listed in the
+//     PluginInfo's Funcs and returns its (escaped) result
+//   - Plugin.Process (PluginProcess) gets ProcessArgs of each generated HTML
+//     file and returns its new content
+//   - Plugin.Deploy (PluginDeploy) gets the BuildInfo of the output directory
+//
+// Errors returned by the methods stop reading or generating the Blog.
+type Plugin struct {
+	command string
+	cmd     *exec.Cmd
+	client  *rpc.Client
+	info    PluginInfo
+}
+
+// PluginInfo describes what a Plugin provides.
+type PluginInfo struct {
+	Name string
+
+	// Hooks holds the hooks the plugin provides: PluginTransform,
+	// PluginProcess and PluginDeploy.
+	Hooks []string
+
+	// Funcs holds names of template functions the plugin provides.
+	Funcs []string
+}
+
+// TransformArgs are the arguments of Plugin.Transform.
+type TransformArgs struct {
+	Title   string
+	Format  string
+	Content string
+}
+
+// FuncArgs are the arguments of Plugin.Func.
+type FuncArgs struct {
+	Name string
+	Args []interface{}
+}
+
+// ProcessArgs are the arguments of Plugin.Process.
+type ProcessArgs struct {
+	// Path of the file relative to the output directory using forward
+	// slashes.
+	Path    string
+	Content string
+}
+
+// StartPlugin starts the Plugin's command (with optional arguments, run by the
+// shell) in the directory (or in the current directory if it's empty) and
+// asks it for its PluginInfo. The Plugin should be closed when it's not needed
+// anymore.
+func StartPlugin(command, dir string) (*Plugin, error) {
+	cmd := exec.Command("sh", "-c", command)
+	cmd.Dir = dir
+	cmd.Stderr = os.Stderr
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start plugin %s: %s", command, err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start plugin %s: %s", command, err)
+	}
+
+	err = cmd.Start()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start plugin %s: %s", command, err)
+	}
+
+	p := &Plugin{command: command, cmd: cmd,
+		client: jsonrpc.NewClient(stdio{stdout, stdin})}
+	err = p.client.Call("Plugin.Describe", struct{}{}, &p.info)
+	if err != nil {
+		p.Close()
+		return nil, fmt.Errorf("failed to describe plugin %s: %s", command, err)
+	}
+	if p.info.Name == "" {
+		p.info.Name = command
+	}
+	return p, nil
+}
+
+// Info returns the PluginInfo the Plugin described itself with.
+func (p *Plugin) Info() PluginInfo {
+	return p.info
+}
+
+// Provides tells whether the Plugin provides the hook.
+func (p *Plugin) Provides(hook string) bool {
+	for _, h := range p.info.Hooks {
+		if h == hook {
+			return true
+		}
+	}
+	return false
+}
+
+// Deploy asks the Plugin to deploy the BuildInfo's OutputDir.
+func (p *Plugin) Deploy(info BuildInfo) error {
+	err := p.client.Call("Plugin.Deploy", info, &struct{}{})
+	if err != nil {
+		return fmt.Errorf("plugin %s failed to deploy: %s", p.info.Name, err)
+	}
+	return nil
+}
+
+// Close stops the Plugin.
+func (p *Plugin) Close() error {
+	p.client.Close()
+	return p.cmd.Wait()
+}
+
+func (p *Plugin) transform(post Post) (string, error) {
+	var content string
+	err := p.client.Call("Plugin.Transform",
+		TransformArgs{post.Title, post.Format, post.Content}, &content)
+	if err != nil {
+		return "", fmt.Errorf("plugin %s failed to transform %s: %s", p.info.Name, post.Title, err)
+	}
+	return content, nil
+}
+
+func (p *Plugin) call(name string, args []interface{}) (string, error) {
+	var result string
+	err := p.client.Call("Plugin.Func", FuncArgs{name, args}, &result)
+	if err != nil {
+		return "", fmt.Errorf("plugin %s failed to call %s: %s", p.info.Name, name, err)
+	}
+	return result, nil
+}
+
+func (p *Plugin) process(path, content string) (string, error) {
+	var processed string
+	err := p.client.Call("Plugin.Process", ProcessArgs{path, content}, &processed)
+	if err != nil {
+		return "", fmt.Errorf("plugin %s failed to process %s: %s", p.info.Name, path, err)
+	}
+	return processed, nil
+}
+
+// ServePlugin serves the receiver's methods (see Plugin) as a Plugin over the
+// standard input and output until the input is closed. It's meant to be
+// called from the main function of plugins written in Go.
+func ServePlugin(receiver interface{}) error {
+	server := rpc.NewServer()
+	err := server.RegisterName("Plugin", receiver)
+	if err != nil {
+		return err
+	}
+
+	server.ServeCodec(jsonrpc.NewServerCodec(stdio{os.Stdin, os.Stdout}))
+	return nil
+}
+
+// stdio joins a reader and a writer into an io.ReadWriteCloser.
+type stdio struct {
+	io.Reader
+	io.Writer
+}
+
+func (s stdio) Close() error {
+	if closer, ok := s.Writer.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// transformPosts replaces the Content of the Posts with the Content
+// transformed by the PluginTransform plugins.
+func (g StaticBlogGenerator) transformPosts(posts Blog) (Blog, error) {
+	var plugins []*Plugin
+	for _, p := range g.options.plugins {
+		if p.Provides(PluginTransform) {
+			plugins = append(plugins, p)
+		}
+	}
+	if len(plugins) == 0 {
+		return posts, nil
+	}
+
+	cache := newRenderCache(g.options)
+	transformed := make(Blog, len(posts))
+	for i, post := range posts {
+		for _, p := range plugins {
+			content, err := cache.of(post).transform(func() (string, error) { return p.transform(post) },
+				PluginTransform, p.command, post.Title, post.Format, post.Content)
+			if err != nil {
+				return nil, err
+			}
+			post.Content = content
+		}
+		transformed[i] = post
+	}
+	return transformed, nil
+}
+
+// pluginFuncs returns the template functions provided by the plugins.
+func (g StaticBlogGenerator) pluginFuncs() map[string]interface{} {
+	funcs := map[string]interface{}{}
+	for _, p := range g.options.plugins {
+		for _, name := range p.info.Funcs {
+			funcs[name] = func(args ...interface{}) (string, error) {
+				return p.call(name, args)
+			}
+		}
+	}
+	return funcs
+}
+
+// processOutput replaces the generated HTML files with the ones processed by
+// the PluginProcess plugins.
+func (g StaticBlogGenerator) processOutput() error {
+	for _, p := range g.options.plugins {
+		if !p.Provides(PluginProcess) {
+			continue
+		}
+
+		err := filepath.WalkDir(g.outputDir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() || !strings.EqualFold(filepath.Ext(path), ".html") {
+				return err
+			}
+
+			bytes, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+
+			rel, err := filepath.Rel(g.outputDir, path)
+			if err != nil {
+				return err
+			}
+
+			processed, err := p.process(filepath.ToSlash(rel), string(bytes))
+			if err != nil {
+				return err
+			}
+			if processed == string(bytes) {
+				return nil
+			}
+			return os.WriteFile(path, []byte(processed), 0600)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package lib
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testPlugin struct{}
+
+func (testPlugin) Describe(_ struct{}, info *PluginInfo) error {
+	*info = PluginInfo{Name: "test", Hooks: []string{PluginTransform, PluginProcess},
+		Funcs: []string{"shout"}}
+	return nil
+}
+
+func (testPlugin) Transform(args TransformArgs, content *string) error {
+	*content = strings.ReplaceAll(args.Content, "LP", "LitePub")
+	return nil
+}
+
+func (testPlugin) Func(args FuncArgs, result *string) error {
+	*result = strings.ToUpper(fmt.Sprint(args.Args...))
+	return nil
+}
+
+func (testPlugin) Process(args ProcessArgs, content *string) error {
+	*content = args.Content + "<!-- " + args.Path + " -->"
+	return nil
+}
+
+// TestHelperPlugin isn't a real test. It serves the testPlugin when the test
+// binary is started as a Plugin.
+func TestHelperPlugin(t *testing.T) {
+	if os.Getenv("LITEPUB_TEST_PLUGIN") != "1" {
+		return
+	}
+	ServePlugin(testPlugin{})
+	os.Exit(0)
+}
+
+func TestGeneratePlugin(t *testing.T) {
+	plugin, err := StartPlugin(fmt.Sprintf("LITEPUB_TEST_PLUGIN=1 '%s' -test.run=TestHelperPlugin",
+		os.Args[0]), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer plugin.Close()
+
+	if plugin.Info().Name != "test" || !plugin.Provides(PluginTransform) || plugin.Provides(PluginDeploy) {
+		t.Errorf("want %v, got %v", "the test plugin", plugin.Info())
+	}
+
+	templates := writeTestTemplates(t)
+	os.WriteFile(filepath.Join(templates, "post.tmpl"),
+		[]byte(`{{define "content"}}{{shout .Title}} {{.Content}}{{end}}`), 0600)
+	blog := Blog{{Title: "Rome", Content: "Built with LP.", Written: time.Now()}}
+
+	output := t.TempDir()
+	g, err := NewStaticBlogGenerator(blog, templates, output, func(string) {},
+		WithPlugin(plugin))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Generate(); err != nil {
+		t.Fatal(err)
+	}
+
+	bytes, _ := os.ReadFile(filepath.Join(output, "rome.html"))
+	want := "ROME Built with LitePub.<!-- rome.html -->"
+	if string(bytes) != want {
+		t.Errorf("want %q, got %q", want, bytes)
+	}
+}
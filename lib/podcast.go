@@ -0,0 +1,200 @@
+package lib
+
+import (
+	"encoding/xml"
+	"io"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PodcastFile is the name of the generated podcast feed.
+const PodcastFile = "podcast.xml"
+
+// PodcastConfig holds the metadata of the podcast feed.
+type PodcastConfig struct {
+	// Title and Description of the podcast. The Config's Title is used if the
+	// Title is empty.
+	Title       string `yaml:"title"`
+	Description string `yaml:"description"`
+
+	// Image is the URL of the cover art (a square JPEG or PNG image of at
+	// least 1400x1400 pixels) relative to the Blog's root or an absolute URL.
+	Image string `yaml:"image"`
+
+	Category string `yaml:"category"`
+	Language string `yaml:"language"`
+	Email    string `yaml:"email"`
+	Explicit bool   `yaml:"explicit"`
+}
+
+type rss struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Itunes  string     `xml:"xmlns:itunes,attr"`
+	Atom    string     `xml:"xmlns:atom,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title       string          `xml:"title"`
+	Link        string          `xml:"link"`
+	AtomLink    atomSelfLink    `xml:"atom:link"`
+	Description string          `xml:"description"`
+	Language    string          `xml:"language,omitempty"`
+	Author      string          `xml:"itunes:author,omitempty"`
+	Owner       *itunesOwner    `xml:"itunes:owner,omitempty"`
+	Image       *itunesImage    `xml:"itunes:image,omitempty"`
+	Category    *itunesCategory `xml:"itunes:category,omitempty"`
+	Explicit    string          `xml:"itunes:explicit"`
+	Items       []rssItem       `xml:"item"`
+}
+
+type atomSelfLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr"`
+}
+
+type itunesOwner struct {
+	Name  string `xml:"itunes:name,omitempty"`
+	Email string `xml:"itunes:email"`
+}
+
+type itunesImage struct {
+	Href string `xml:"href,attr"`
+}
+
+type itunesCategory struct {
+	Text string `xml:"text,attr"`
+}
+
+type rssItem struct {
+	Title       string       `xml:"title"`
+	Link        string       `xml:"link"`
+	GUID        string       `xml:"guid"`
+	PubDate     string       `xml:"pubDate"`
+	Description string       `xml:"description"`
+	Enclosure   rssEnclosure `xml:"enclosure"`
+	Duration    string       `xml:"itunes:duration,omitempty"`
+	Episode     int          `xml:"itunes:episode,omitempty"`
+	Season      int          `xml:"itunes:season,omitempty"`
+	Explicit    string       `xml:"itunes:explicit"`
+}
+
+type rssEnclosure struct {
+	URL    string `xml:"url,attr"`
+	Length int64  `xml:"length,attr"`
+	Type   string `xml:"type,attr"`
+}
+
+// generatePodcast generates the PodcastFile with the Posts with Audio.
+func (g StaticBlogGenerator) generatePodcast() error {
+	config := g.options.config.Podcast
+
+	title := config.Title
+	if title == "" {
+		title = g.options.config.Title
+	}
+
+	channel := rssChannel{
+		Title:       title,
+		Link:        g.absURL(""),
+		AtomLink:    atomSelfLink{g.absURL(PodcastFile), "self", "application/rss+xml"},
+		Description: config.Description,
+		Language:    config.Language,
+		Author:      g.options.config.Author,
+		Explicit:    strconv.FormatBool(config.Explicit),
+	}
+	if config.Email != "" {
+		channel.Owner = &itunesOwner{g.options.config.Author, config.Email}
+	}
+	if config.Image != "" {
+		channel.Image = &itunesImage{g.resolveURL(config.Image)}
+	}
+	if config.Category != "" {
+		channel.Category = &itunesCategory{config.Category}
+	}
+
+	for _, post := range g.posts {
+		if post.Audio == nil {
+			continue
+		}
+
+		description, err := g.description(post)
+		if err != nil {
+			return err
+		}
+
+		url := g.absPageURL(g.postPath(post))
+		channel.Items = append(channel.Items, rssItem{
+			Title:       post.Title,
+			Link:        url,
+			GUID:        url,
+			PubDate:     post.Written.In(g.dates.location).Format(time.RFC1123Z),
+			Description: description,
+			Enclosure: rssEnclosure{g.resolveURL(post.Audio.File),
+				g.audioSize(*post.Audio), audioType(post.Audio.File)},
+			Duration: post.Audio.Duration,
+			Episode:  post.Audio.Episode,
+			Season:   post.Audio.Season,
+			Explicit: strconv.FormatBool(post.Audio.Explicit),
+		})
+	}
+
+	feed := rss{Version: "2.0", Itunes: "http://www.itunes.com/dtds/podcast-1.0.dtd",
+		Atom: "http://www.w3.org/2005/Atom", Channel: channel}
+
+	return g.generateFile(PodcastFile, func(w io.Writer) error {
+		return writeXML(w, feed)
+	})
+}
+
+// hasAudio tells whether any of the Posts has Audio.
+func hasAudio(posts []Post) bool {
+	for _, post := range posts {
+		if post.Audio != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveURL returns the URL if it's absolute or the absolute URL of the
+// path relative to the Blog's root.
+func (g StaticBlogGenerator) resolveURL(url string) string {
+	if strings.Contains(url, "://") {
+		return url
+	}
+	return g.absURL(strings.TrimPrefix(url, "/"))
+}
+
+// audioSize returns the Audio's Size or the size of its file in the output
+// directory.
+func (g StaticBlogGenerator) audioSize(audio Audio) int64 {
+	if audio.Size > 0 || strings.Contains(audio.File, "://") {
+		return audio.Size
+	}
+
+	info, err := os.Stat(filepath.Join(g.outputDir,
+		filepath.FromSlash(path.Clean("/"+audio.File))))
+	if err != nil {
+		return 0
+	}
+	return info.Size()
+}
+
+func audioType(file string) string {
+	ext := strings.ToLower(path.Ext(file))
+	if ext == ".mp3" {
+		return "audio/mpeg"
+	}
+	if t := mime.TypeByExtension(ext); t != "" {
+		return t
+	}
+	return "audio/mpeg"
+}
@@ -0,0 +1,49 @@
+package lib
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGeneratePodcast(t *testing.T) {
+	fm, _, err := splitFrontMatter("---\naudio:\n  file: /episodes/1.mp3\n  duration: \"42:17\"\n  episode: 1\n---\n\n# Rome\n")
+	if err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+	post := Post{Title: "Rome", Content: "About Rome.", Written: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)}
+	fm.apply(&post, time.UTC)
+
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "episodes"), 0700)
+	os.WriteFile(filepath.Join(dir, "episodes", "1.mp3"), []byte("12345"), 0600)
+
+	g := StaticBlogGenerator{outputDir: dir, progressFunc: func(string) {},
+		dates: dateFormatter{location: time.UTC}, posts: []Post{post, {Title: "Paris"}},
+		options: options{config: Config{URL: "https://example.com", Title: "Cities",
+			Podcast: PodcastConfig{Image: "/cover.jpg", Category: "Society & Culture"}}}}
+
+	if err := g.generatePodcast(); err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+
+	bytes, _ := os.ReadFile(filepath.Join(dir, PodcastFile))
+	for _, want := range []string{
+		`<title>Cities</title>`,
+		`<itunes:image href="https://example.com/cover.jpg"></itunes:image>`,
+		`<itunes:category text="Society &amp; Culture"></itunes:category>`,
+		`<enclosure url="https://example.com/episodes/1.mp3" length="5" type="audio/mpeg"></enclosure>`,
+		`<itunes:duration>42:17</itunes:duration>`,
+		`<itunes:episode>1</itunes:episode>`,
+		`<pubDate>Wed, 01 May 2024 00:00:00 +0000</pubDate>`,
+	} {
+		if !strings.Contains(string(bytes), want) {
+			t.Errorf("want %q in %s", want, bytes)
+		}
+	}
+	if strings.Count(string(bytes), "<item>") != 1 {
+		t.Errorf("want %v, got %v", 1, strings.Count(string(bytes), "<item>"))
+	}
+}
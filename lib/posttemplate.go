@@ -0,0 +1,107 @@
+package lib
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ReadData reads the YAML and JSON files in the data directory of the Blog in
+// the directory keyed by their names without extensions, so posts can use
+// them (see Config.TemplatePosts). If the data directory doesn't exist it
+// returns an empty map.
+func ReadData(dir string) (map[string]interface{}, error) {
+	data := map[string]interface{}{}
+	files, err := os.ReadDir(filepath.Join(dir, dataDir))
+	if err != nil {
+		return data, nil
+	}
+
+	for _, file := range files {
+		ext := strings.ToLower(filepath.Ext(file.Name()))
+		if file.IsDir() || (ext != ".yaml" && ext != ".yml" && ext != ".json") {
+			continue
+		}
+
+		bytes, err := os.ReadFile(filepath.Join(dir, dataDir, file.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read data: %s", err)
+		}
+
+		// JSON is YAML too.
+		var v interface{}
+		err = yaml.Unmarshal(bytes, &v)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read data %s: %s", file.Name(), err)
+		}
+		data[strings.TrimSuffix(file.Name(), filepath.Ext(file.Name()))] = v
+	}
+	return data, nil
+}
+
+// PostTemplateData is the data available to the Content of Posts executed as
+// templates (see Config.TemplatePosts).
+type PostTemplateData struct {
+	Site Site
+	Post Post
+
+	// Data holds the data files keyed by their names (see ReadData).
+	Data map[string]interface{}
+}
+
+// executePost executes the Post's Content as a text/template with the
+// PostTemplateData and the template functions.
+func (g StaticBlogGenerator) executePost(post Post) (string, error) {
+	funcs := template.FuncMap{}
+	for name, f := range g.funcs() {
+		funcs[name] = f
+	}
+
+	t, err := template.New(post.Title).Funcs(funcs).Option(g.options.config.missingKey()).
+		Parse(post.Content)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse post %s: %s", post.Title, err)
+	}
+
+	var b strings.Builder
+	err = t.Execute(&b, PostTemplateData{g.site(), post, g.options.data})
+	if err != nil {
+		return "", fmt.Errorf("failed to execute post %s: %s", post.Title, err)
+	}
+	return b.String(), nil
+}
+
+// age returns how long ago the time was (relative to the time of the Build),
+// for example "3 years" or "5 days".
+func (g StaticBlogGenerator) age(t time.Time) string {
+	now := g.options.build.Time
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	plural := func(n int, unit string) string {
+		return pluralize(n, unit, unit+"s")
+	}
+
+	months := (now.Year()-t.Year())*12 + int(now.Month()) - int(t.Month())
+	if now.Day() < t.Day() {
+		months--
+	}
+	switch {
+	case months >= 12:
+		return plural(months/12, "year")
+	case months >= 1:
+		return plural(months, "month")
+	}
+
+	days := int(now.Sub(t).Hours() / 24)
+	if days < 1 {
+		return "less than a day"
+	}
+	return plural(days, "day")
+}
@@ -0,0 +1,78 @@
+package lib
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadData(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, dataDir), 0700)
+	os.WriteFile(filepath.Join(dir, dataDir, "team.yaml"), []byte("lead: Jane\n"), 0600)
+	os.WriteFile(filepath.Join(dir, dataDir, "stats.json"), []byte(`{"posts": 3}`), 0600)
+	os.WriteFile(filepath.Join(dir, dataDir, "blogroll.opml"), []byte("<opml/>"), 0600)
+
+	data, err := ReadData(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) != 2 || data["team"].(map[string]interface{})["lead"] != "Jane" ||
+		data["stats"].(map[string]interface{})["posts"] != 3 {
+		t.Errorf("want %v, got %v", "team and stats", data)
+	}
+}
+
+func TestTemplatePosts(t *testing.T) {
+	built := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	post := Post{Title: "Rome", Written: time.Date(2021, 5, 1, 0, 0, 0, 0, time.UTC),
+		Content: `{{< youtube "x" >}} *{{.Site.Title}}*, {{age .Post.Written}} old, by {{.Data.team.lead}}`}
+	opts := []Option{WithConfig(Config{Title: "Cities", TemplatePosts: true}),
+		WithBuild(Build{Time: built}), WithData(map[string]interface{}{
+			"team": map[string]interface{}{"lead": "Jane"}})}
+
+	g := StaticBlogGenerator{options: newOptions(opts)}
+	html, err := g.render(post)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "<p><em>Cities</em>, 3 years old, by Jane</p>\n"
+	if !strings.HasSuffix(string(html), want) {
+		t.Errorf("want %q, got %q", want, html)
+	}
+
+	for written, want := range map[time.Time]string{
+		time.Date(2024, 5, 31, 12, 0, 0, 0, time.UTC): "less than a day",
+		time.Date(2024, 5, 20, 0, 0, 0, 0, time.UTC):  "12 days",
+		time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC):   "5 months",
+		time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC):   "1 year",
+	} {
+		if got := g.age(written); got != want {
+			t.Errorf("want %v, got %v", want, got)
+		}
+	}
+}
+
+func TestStrictTemplatePosts(t *testing.T) {
+	post := Post{Title: "Rome", Content: "By {{.Data.team.leader}}"}
+	data := WithData(map[string]interface{}{"team": map[string]interface{}{"lead": "Jane"}})
+
+	g := StaticBlogGenerator{options: newOptions([]Option{
+		WithConfig(Config{TemplatePosts: true}), data})}
+	html, err := g.render(post)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "<p>By <no value></p>\n"; string(html) != want {
+		t.Errorf("want %q, got %q", want, html)
+	}
+
+	g = StaticBlogGenerator{options: newOptions([]Option{
+		WithConfig(Config{TemplatePosts: true, StrictTemplates: true}), data})}
+	_, err = g.render(post)
+	if err == nil || !strings.Contains(err.Error(), `Rome:1:10: executing "Rome" at <.Data.team.leader>`) {
+		t.Errorf("want missing key error, got %v", err)
+	}
+}
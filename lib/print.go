@@ -0,0 +1,101 @@
+package lib
+
+import (
+	"fmt"
+	stdhtml "html"
+	"html/template"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// printLinkRegexp matches links with their URLs except links to fragments of
+// the same page.
+var printLinkRegexp = regexp.MustCompile(`(?is)<a\s[^>]*?href\s*=\s*"([^"#][^"]*)"[^>]*>.*?</a>`)
+
+// printPath returns the path of the print version of the Post's page in the
+// output directory: print.html in the directory named like the page (for
+// example rome/print.html for rome.html or rome/index.html).
+func (g StaticBlogGenerator) printPath(post Post) string {
+	p := g.postPath(post)
+	dir := strings.TrimSuffix(p, filepath.Ext(p))
+	if filepath.Base(p) == "index.html" {
+		dir = filepath.Dir(p)
+	}
+	return filepath.Join(dir, "print.html")
+}
+
+// printURL returns the URL of the print version of the Post's page relative to
+// the Blog's root.
+func (g StaticBlogGenerator) printURL(post Post) string {
+	return g.pageURL(g.printPath(post))
+}
+
+// printHTML renders the Post like html, but with a numbered reference after
+// each link and a list of the links' absolute URLs (in the order of the
+// references) at the end, so the URLs are readable on paper.
+func (g StaticBlogGenerator) printHTML(post Post) (template.HTML, error) {
+	content, err := g.render(post)
+	if err != nil {
+		return "", err
+	}
+
+	var urls []string
+	numbers := map[string]int{}
+	printed := printLinkRegexp.ReplaceAllStringFunc(string(content), func(link string) string {
+		match := printLinkRegexp.FindStringSubmatchIndex(link)
+		href := link[match[2]:match[3]]
+		resolved := stdhtml.EscapeString(g.printLinkURL(post, stdhtml.UnescapeString(href)))
+
+		number, ok := numbers[resolved]
+		if !ok {
+			urls = append(urls, resolved)
+			number = len(urls)
+			numbers[resolved] = number
+		}
+		return fmt.Sprintf(`%s%s%s<sup class="print-link">[%d]</sup>`,
+			link[:match[2]], resolved, link[match[3]:], number)
+	})
+	if len(urls) == 0 {
+		return content, nil
+	}
+
+	var b strings.Builder
+	b.WriteString(printed)
+	b.WriteString("\n<ol class=\"print-links\">\n")
+	for _, u := range urls {
+		fmt.Fprintf(&b, "<li>%s</li>\n", u)
+	}
+	b.WriteString("</ol>\n")
+	return template.HTML(b.String()), nil
+}
+
+// printLinkURL resolves the link's URL against the URL of the Post's page. It
+// returns an absolute URL if the Config's URL is set, otherwise a URL relative
+// to the Blog's root (prefixed with the BasePath with the other links of the
+// page, see prefixOutput).
+func (g StaticBlogGenerator) printLinkURL(post Post, href string) string {
+	page, err := url.Parse(g.postURL(post))
+	if err != nil {
+		return href
+	}
+	ref, err := url.Parse(href)
+	if err != nil {
+		return href
+	}
+	resolved := page.ResolveReference(ref)
+	if g.options.config.URL == "" || resolved.IsAbs() {
+		return resolved.String()
+	}
+
+	base, err := url.Parse(g.options.config.siteURL())
+	if err != nil {
+		return href
+	}
+	prefixed, err := url.Parse(g.options.config.withBasePath(resolved.String()))
+	if err != nil {
+		return href
+	}
+	return base.ResolveReference(prefixed).String()
+}
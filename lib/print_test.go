@@ -0,0 +1,60 @@
+package lib
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGeneratePrintPages(t *testing.T) {
+	templates := writeTestTemplates(t)
+	os.WriteFile(filepath.Join(templates, "post.tmpl"),
+		[]byte(`{{define "content"}}{{printURL .}}{{end}}`), 0600)
+	os.WriteFile(filepath.Join(templates, "print.tmpl"),
+		[]byte(`<h1>{{.Title}}</h1>{{printHTML .}}`), 0600)
+	blog := Blog{
+		{Title: "Rome", Content: "See [Paris](/paris.html), [the Colosseum](https://example.org/colosseum)," +
+			" [Paris again](paris.html) and [the top](#top).",
+			Written: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)},
+		{Title: "Paris", Written: time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	output := t.TempDir()
+	g, err := NewStaticBlogGenerator(blog, templates, output, func(string) {},
+		WithConfig(Config{URL: "https://example.com"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Generate(); err != nil {
+		t.Fatal(err)
+	}
+
+	post, _ := os.ReadFile(filepath.Join(output, "rome.html"))
+	if want := "/rome/print.html"; string(post) != want {
+		t.Errorf("want %q, got %q", want, post)
+	}
+
+	print, err := os.ReadFile(filepath.Join(output, "rome", "print.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`<a href="https://example.com/paris.html">Paris</a><sup class="print-link">[1]</sup>`,
+		`<a href="https://example.org/colosseum">the Colosseum</a><sup class="print-link">[2]</sup>`,
+		`<a href="https://example.com/paris.html">Paris again</a><sup class="print-link">[1]</sup>`,
+		`<a href="#top">the top</a>.`,
+		"<li>https://example.com/paris.html</li>\n<li>https://example.org/colosseum</li>\n</ol>",
+	} {
+		if !strings.Contains(string(print), want) {
+			t.Errorf("want %s, got %s", want, print)
+		}
+	}
+	if strings.Contains(string(print), "<title>") {
+		t.Errorf("want no layout, got %s", print)
+	}
+	if _, err := os.Stat(filepath.Join(output, "print.tmpl")); err == nil {
+		t.Errorf("want print.tmpl not copied, got it in the output directory")
+	}
+}
@@ -0,0 +1,73 @@
+package lib
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// processedToPost parses a post converted to HTML by an external processor
+// (see Config.Processors). Its metadata is stored in the front matter (besides
+// the title and date it can contain all fields of the frontMatter):
+//
+//	---
+//	title: Title
+//	date: 2009-01-02
+//	tags: [tag1, tag2]
+//	page: true
+//	---
+//
+//	Content
+//
+// The front matter is the same as of posts written in HTML (see htmlToPost).
+// The content is converted to HTML by the convert function.
+func processedToPost(source string, loc *time.Location,
+	convert func(string) (string, error)) (Post, error) {
+	var fm htmlFrontMatter
+	doc, err := cutFrontMatter(strings.ReplaceAll(source, "\r\n", "\n"), &fm)
+	if err != nil {
+		return Post{}, err
+	}
+
+	content, err := convert(doc)
+	if err != nil {
+		return Post{}, fmt.Errorf("failed to convert content: %s", err)
+	}
+
+	return fm.post(content, loc)
+}
+
+// processor returns the command of the processor (see Config.Processors)
+// configured for the file extension or an empty string if there's none.
+func (c Config) processor(ext string) string {
+	for e, command := range c.Processors {
+		if strings.EqualFold("."+strings.TrimPrefix(e, "."), ext) {
+			return command
+		}
+	}
+	return ""
+}
+
+// process returns a function converting a document to HTML with the
+// processor's command run by the shell in the Blog directory.
+func (b MarkdownBlog) process(command string) func(string) (string, error) {
+	cache := newRenderCache(b.options)
+	return func(doc string) (string, error) {
+		return cache.transform(func() (string, error) {
+			cmd := exec.Command("sh", "-c", command)
+			cmd.Dir = b.dir
+			cmd.Stdin = strings.NewReader(doc)
+
+			var stderr bytes.Buffer
+			cmd.Stderr = &stderr
+
+			out, err := cmd.Output()
+			if err != nil {
+				return "", fmt.Errorf("%s: %s", err, strings.TrimSpace(stderr.String()))
+			}
+			return strings.TrimSpace(string(out)), nil
+		}, "processor", command, doc)
+	}
+}
@@ -0,0 +1,64 @@
+package lib
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestProcessedToPost(t *testing.T) {
+	dir := t.TempDir()
+	posts := filepath.Join(dir, postsDir)
+	os.MkdirAll(filepath.Join(posts, draftDir), 0700)
+	os.WriteFile(filepath.Join(posts, "rome.rst"),
+		[]byte("---\ntitle: Rome\ndate: 2021-08-10\ntags: [cities]\n---\n\nRome *wasn't* built in a day.\n"), 0600)
+
+	config := Config{Processors: map[string]string{"rst": `sed 's/^/<p>/; s/$/<\/p>/'`}}
+	blog, err := NewMarkdownBlog(dir, WithConfig(config)).Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(blog) != 1 {
+		t.Fatalf("want %v, got %v", 1, len(blog))
+	}
+	post := blog[0]
+	if post.Title != "Rome" || post.Tags[0] != "cities" ||
+		!post.Written.Equal(time.Date(2021, 8, 10, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("want %v, got %v", "Rome written on 2021-08-10", post)
+	}
+	if want := "<p>Rome *wasn't* built in a day.</p>"; post.Format != FormatHTML || post.Content != want {
+		t.Errorf("want %q, got %v %q", want, post.Format, post.Content)
+	}
+
+	config.Processors["rst"] = "exit 1"
+	_, err = NewMarkdownBlog(dir, WithConfig(config)).Read()
+	if err == nil {
+		t.Errorf("want %v, got %v", "an error", err)
+	}
+}
+
+func TestProcessedToPostFrontMatter(t *testing.T) {
+	convert := func(doc string) (string, error) { return "<p>" + doc + "</p>", nil }
+
+	post, err := processedToPost("---\ntitle: Rome\ndate: Aug 10, 2021\nupdated: 2021-09-01\n---\nRome",
+		time.UTC, convert)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !post.Written.Equal(time.Date(2021, 8, 10, 0, 0, 0, 0, time.UTC)) ||
+		!post.Updated.Equal(time.Date(2021, 9, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("want %v, got %v", "Rome written on 2021-08-10 and updated on 2021-09-01", post)
+	}
+	if want := "<p>Rome</p>"; post.Content != want {
+		t.Errorf("want %q, got %q", want, post.Content)
+	}
+
+	for _, source := range []string{"---\ndate: 2021-08-10\n---\nRome",
+		"---\ntitle: Rome\ndate: 10.8.2021\n---\nRome"} {
+		if _, err := processedToPost(source, time.UTC, convert); err == nil {
+			t.Errorf("%q: want %v, got %v", source, "an error", err)
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package lib
+
+import (
+	"html/template"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Profile records timings of reading and generating a Blog: stages of the
+// build, stages of the pipeline rendering Posts (summed over all Posts) and
+// generated pages. It's safe for concurrent use and all its methods can be
+// called on nil Profiles (which don't record anything).
+type Profile struct {
+	mu      sync.Mutex
+	stages  []Timing
+	render  map[string]time.Duration
+	pages   []Timing
+	started time.Time
+}
+
+// Timing is the duration of a build stage, a rendering stage, a page (with the
+// Name of its Template) or a template (with the Count of its pages).
+type Timing struct {
+	Name     string
+	Template string
+	Count    int
+	Duration time.Duration
+}
+
+// NewProfile creates an empty Profile.
+func NewProfile() *Profile {
+	return &Profile{render: map[string]time.Duration{}, started: time.Now()}
+}
+
+// Time calls the function and records its duration as the build stage.
+func (p *Profile) Time(stage string, f func() error) error {
+	if p == nil {
+		return f()
+	}
+
+	started := time.Now()
+	err := f()
+	p.record(stage, started)
+	return err
+}
+
+// record records the time since started as the build stage.
+func (p *Profile) record(stage string, started time.Time) {
+	if p == nil {
+		return
+	}
+
+	p.mu.Lock()
+	p.stages = append(p.stages, Timing{Name: stage, Duration: time.Since(started)})
+	p.mu.Unlock()
+}
+
+// Total returns the time since the Profile was created.
+func (p *Profile) Total() time.Duration {
+	if p == nil {
+		return 0
+	}
+	return time.Since(p.started)
+}
+
+// Stages returns the build stages in the order they were recorded.
+func (p *Profile) Stages() []Timing {
+	if p == nil {
+		return nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]Timing{}, p.stages...)
+}
+
+// Render returns the rendering stages (see StageMarkdown) in the order of the
+// pipeline. Durations of Transformers are added to the stage they run after.
+func (p *Profile) Render() []Timing {
+	if p == nil {
+		return nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var timings []Timing
+	for _, stage := range []string{StageFrontMatter, StageShortcodes, StageMarkdown, StageHTML} {
+		if d, ok := p.render[stage]; ok {
+			timings = append(timings, Timing{Name: stage, Duration: d})
+		}
+	}
+	return timings
+}
+
+// SlowestPages returns at most n pages sorted from the slowest one.
+func (p *Profile) SlowestPages(n int) []Timing {
+	if p == nil {
+		return nil
+	}
+
+	p.mu.Lock()
+	pages := append([]Timing{}, p.pages...)
+	p.mu.Unlock()
+	sortTimings(pages)
+	if len(pages) > n {
+		pages = pages[:n]
+	}
+	return pages
+}
+
+// Templates returns the total durations of pages of each template sorted from
+// the slowest one.
+func (p *Profile) Templates() []Timing {
+	if p == nil {
+		return nil
+	}
+
+	p.mu.Lock()
+	byName := map[string]*Timing{}
+	for _, page := range p.pages {
+		t, ok := byName[page.Template]
+		if !ok {
+			t = &Timing{Name: page.Template}
+			byName[page.Template] = t
+		}
+		t.Count++
+		t.Duration += page.Duration
+	}
+	p.mu.Unlock()
+
+	var templates []Timing
+	for _, t := range byName {
+		templates = append(templates, *t)
+	}
+	sortTimings(templates)
+	return templates
+}
+
+func (p *Profile) addRender(stage string, d time.Duration) {
+	if p == nil {
+		return
+	}
+
+	p.mu.Lock()
+	p.render[stage] += d
+	p.mu.Unlock()
+}
+
+func (p *Profile) addPage(path, template string, d time.Duration) {
+	if p == nil {
+		return
+	}
+
+	p.mu.Lock()
+	p.pages = append(p.pages, Timing{Name: path, Template: template, Duration: d})
+	p.mu.Unlock()
+}
+
+// sortTimings sorts the timings from the slowest one (and by name if the
+// durations are the same).
+func sortTimings(timings []Timing) {
+	sort.Slice(timings, func(i, j int) bool {
+		if timings[i].Duration != timings[j].Duration {
+			return timings[i].Duration > timings[j].Duration
+		}
+		return timings[i].Name < timings[j].Name
+	})
+}
+
+// templateName returns the name of the page template of the template parsed
+// with the layout (or the template's name if there's no such template).
+func templateName(t *template.Template) string {
+	for _, associated := range t.Templates() {
+		name := associated.Name()
+		if name != t.Name() && name != "layout.tmpl" && strings.HasSuffix(name, ".tmpl") {
+			return name
+		}
+	}
+	return t.Name()
+}
@@ -0,0 +1,57 @@
+package lib
+
+import (
+	"testing"
+	"time"
+)
+
+func TestProfile(t *testing.T) {
+	templates := writeTestTemplates(t)
+	blog := Blog{
+		{Title: "Rome", Tags: []string{"cities"}, Written: time.Now()},
+		{Title: "Paris", Tags: []string{"cities"}, Written: time.Now()},
+	}
+
+	p := NewProfile()
+	g, err := NewStaticBlogGenerator(blog, templates, t.TempDir(), func(string) {},
+		WithProfile(p))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Generate(); err != nil {
+		t.Fatal(err)
+	}
+
+	stages := map[string]bool{}
+	for _, stage := range p.Stages() {
+		stages[stage.Name] = true
+	}
+	for _, stage := range []string{"setup", "index", "tags", "posts"} {
+		if !stages[stage] {
+			t.Errorf("want %v, got %v", stage, p.Stages())
+		}
+	}
+
+	counts := map[string]int{}
+	for _, template := range p.Templates() {
+		counts[template.Name] = template.Count
+	}
+	for name, want := range map[string]int{"index.tmpl": 1, "post.tmpl": 2, "tag.tmpl": 1} {
+		if counts[name] != want {
+			t.Errorf("%s: want %v, got %v", name, want, counts[name])
+		}
+	}
+
+	if pages := p.SlowestPages(2); len(pages) != 2 || pages[0].Duration < pages[1].Duration {
+		t.Errorf("want %v, got %v", "2 sorted pages", pages)
+	}
+}
+
+func TestNilProfile(t *testing.T) {
+	var p *Profile
+	called := false
+	p.Time("reading", func() error { called = true; return nil })
+	if !called || p.Stages() != nil || p.Templates() != nil {
+		t.Errorf("want %v, got %v", "no timings", p.Stages())
+	}
+}
@@ -0,0 +1,219 @@
+package lib
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"io/fs"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Files of the progressive web app (see PWAConfig) in the output directory.
+const (
+	ManifestFile      = "manifest.webmanifest"
+	ServiceWorkerFile = "sw.js"
+)
+
+// precachedExtensions are the extensions of files precached by the service
+// worker besides the index and 404 pages and the icons. Other pages are
+// cached when they're visited.
+var precachedExtensions = map[string]bool{".css": true, ".js": true,
+	".woff": true, ".woff2": true}
+
+// PWAConfig holds settings of the web app manifest and the service worker
+// generated to make the Blog installable and readable offline. They're
+// generated if the Name or Icons are set.
+type PWAConfig struct {
+	// Name of the app. It's the Blog's Title if it's empty.
+	Name string `yaml:"name"`
+
+	// ShortName is shown where there's no space for the Name.
+	ShortName   string `yaml:"shortName"`
+	Description string `yaml:"description"`
+
+	ThemeColor      string `yaml:"themeColor"`
+	BackgroundColor string `yaml:"backgroundColor"`
+
+	// Icons are URLs (relative to the Blog's root) of the app's icons, for
+	// example /images/icon-192.png and /images/icon-512.png.
+	Icons []string `yaml:"icons"`
+}
+
+func (c PWAConfig) enabled() bool {
+	return c.Name != "" || len(c.Icons) > 0
+}
+
+type webManifest struct {
+	Name            string            `json:"name"`
+	ShortName       string            `json:"short_name,omitempty"`
+	Description     string            `json:"description,omitempty"`
+	StartURL        string            `json:"start_url"`
+	Scope           string            `json:"scope"`
+	Display         string            `json:"display"`
+	ThemeColor      string            `json:"theme_color,omitempty"`
+	BackgroundColor string            `json:"background_color,omitempty"`
+	Icons           []webManifestIcon `json:"icons,omitempty"`
+}
+
+type webManifestIcon struct {
+	Src   string `json:"src"`
+	Sizes string `json:"sizes,omitempty"`
+	Type  string `json:"type,omitempty"`
+}
+
+const serviceWorker = `const CACHE = %q;
+const PRECACHED = %s;
+
+self.addEventListener("install", event => {
+  event.waitUntil(caches.open(CACHE).then(cache => cache.addAll(PRECACHED)));
+  self.skipWaiting();
+});
+
+self.addEventListener("activate", event => {
+  event.waitUntil(caches.keys().then(keys => Promise.all(
+    keys.filter(key => key !== CACHE).map(key => caches.delete(key)))));
+  self.clients.claim();
+});
+
+self.addEventListener("fetch", event => {
+  const request = event.request;
+  if (request.method !== "GET" || new URL(request.url).origin !== location.origin) {
+    return;
+  }
+
+  const cached = caches.match(request);
+  const fetched = fetch(request).then(response => {
+    if (response.ok) {
+      const copy = response.clone();
+      caches.open(CACHE).then(cache => cache.put(request, copy));
+    }
+    return response;
+  }).catch(() => undefined);
+
+  if (request.mode === "navigate") {
+    event.respondWith(fetched.then(response => response ||
+      cached.then(response => response || caches.match(%q))));
+  } else {
+    event.respondWith(cached.then(response => response || fetched));
+  }
+});
+`
+
+// generatePWA generates the ManifestFile and the ServiceWorkerFile. The
+// service worker's cache is named by a hash of the precached files, so
+// visitors get new versions of them after each change.
+func (g StaticBlogGenerator) generatePWA() error {
+	c := g.options.config.PWA
+	base := g.options.config.withBasePath("/")
+
+	manifest := webManifest{Name: c.Name, ShortName: c.ShortName,
+		Description: c.Description, StartURL: base, Scope: base,
+		Display: "standalone", ThemeColor: c.ThemeColor,
+		BackgroundColor: c.BackgroundColor}
+	if manifest.Name == "" {
+		manifest.Name = g.options.config.Title
+	}
+	for _, icon := range c.Icons {
+		entry := webManifestIcon{Src: g.options.config.withBasePath(icon),
+			Type: mime.TypeByExtension(path.Ext(icon))}
+		if width, height, ok := g.imageSize(icon); ok {
+			entry.Sizes = fmt.Sprintf("%dx%d", width, height)
+		}
+		manifest.Icons = append(manifest.Icons, entry)
+	}
+
+	err := g.generateFile(ManifestFile, func(w io.Writer) error {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(manifest)
+	})
+	if err != nil {
+		return err
+	}
+
+	precached, hash, err := g.precachedFiles()
+	if err != nil {
+		return err
+	}
+	urls, err := json.Marshal(precached)
+	if err != nil {
+		return err
+	}
+	return g.generateFile(ServiceWorkerFile, func(w io.Writer) error {
+		_, err := fmt.Fprintf(w, serviceWorker, "litepub-"+hash, urls, base)
+		return err
+	})
+}
+
+// precachedFiles returns the sorted URLs of the files precached by the
+// service worker and a hash of their contents.
+func (g StaticBlogGenerator) precachedFiles() ([]string, string, error) {
+	icons := map[string]bool{}
+	for _, icon := range g.options.config.PWA.Icons {
+		icons["/"+strings.TrimPrefix(icon, "/")] = true
+	}
+
+	var files []string
+	err := filepath.WalkDir(g.outputDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		rel, err := filepath.Rel(g.outputDir, p)
+		if err != nil {
+			return err
+		}
+		url := "/" + filepath.ToSlash(rel)
+		if url == "/index.html" || url == "/404.html" || icons[url] ||
+			(precachedExtensions[strings.ToLower(path.Ext(url))] && url != "/"+ServiceWorkerFile) {
+			files = append(files, url)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	sort.Strings(files)
+
+	h := sha256.New()
+	var urls []string
+	for _, file := range files {
+		bytes, err := os.ReadFile(filepath.Join(g.outputDir, filepath.FromSlash(file)))
+		if err != nil {
+			return nil, "", err
+		}
+		h.Write([]byte(file))
+		h.Write(bytes)
+
+		url := g.options.config.withBasePath(file)
+		if file == "/index.html" {
+			url = g.options.config.withBasePath("/")
+		}
+		urls = append(urls, url)
+	}
+	sort.Strings(urls)
+	return urls, hex.EncodeToString(h.Sum(nil))[:12], nil
+}
+
+// pwaFunc returns the link to the ManifestFile and the script registering the
+// ServiceWorkerFile for the head of pages (or nothing if the PWAConfig isn't
+// enabled). The link is prefixed with the BasePath with the other links of
+// pages (see prefixOutput), the script's URL isn't rewritten, so it's
+// prefixed here.
+func (g StaticBlogGenerator) pwaFunc() template.HTML {
+	if !g.options.config.PWA.enabled() {
+		return ""
+	}
+	return template.HTML(fmt.Sprintf(`<link rel="manifest" href="%s">`+
+		`<script>if ("serviceWorker" in navigator) navigator.serviceWorker.register(%q);</script>`,
+		template.HTMLEscapeString("/"+ManifestFile),
+		g.options.config.withBasePath("/"+ServiceWorkerFile)))
+}
@@ -0,0 +1,63 @@
+package lib
+
+import (
+	"encoding/json"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPWA(t *testing.T) {
+	templates := writeTestTemplates(t)
+	os.WriteFile(filepath.Join(templates, "layout.tmpl"), []byte(`{{pwa}}{{template "content" .}}`), 0600)
+	os.WriteFile(filepath.Join(templates, "style.css"), []byte("body {}"), 0600)
+	icon, _ := os.Create(filepath.Join(templates, "icon.png"))
+	png.Encode(icon, image.NewRGBA(image.Rect(0, 0, 192, 192)))
+	icon.Close()
+
+	generate := func() string {
+		output := t.TempDir()
+		g, err := NewStaticBlogGenerator(Blog{}, templates, output, func(string) {},
+			WithConfig(Config{Title: "Rome", BasePath: "/blog/",
+				PWA: PWAConfig{Icons: []string{"/icon.png"}}}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := g.Generate(); err != nil {
+			t.Fatal(err)
+		}
+
+		var manifest webManifest
+		bytes, _ := os.ReadFile(filepath.Join(output, ManifestFile))
+		json.Unmarshal(bytes, &manifest)
+		want := webManifest{Name: "Rome", StartURL: "/blog/", Scope: "/blog/", Display: "standalone",
+			Icons: []webManifestIcon{{"/blog/icon.png", "192x192", "image/png"}}}
+		if !reflect.DeepEqual(manifest, want) {
+			t.Errorf("want %v, got %v", want, manifest)
+		}
+
+		bytes, _ = os.ReadFile(filepath.Join(output, "index.html"))
+		if !strings.Contains(string(bytes), `<link rel="manifest" href="/blog/manifest.webmanifest">`) {
+			t.Errorf("want %v, got %s", "the manifest link", bytes)
+		}
+
+		bytes, _ = os.ReadFile(filepath.Join(output, ServiceWorkerFile))
+		if !strings.Contains(string(bytes), `const PRECACHED = ["/blog/","/blog/icon.png","/blog/style.css"];`) {
+			t.Errorf("want %v, got %s", "the precached files", bytes)
+		}
+		return strings.SplitN(string(bytes), "\n", 2)[0]
+	}
+
+	cache := generate()
+	if cache != generate() {
+		t.Errorf("want %v, got %v", "the same cache name", "another one")
+	}
+	os.WriteFile(filepath.Join(templates, "style.css"), []byte("body { color: red; }"), 0600)
+	if cache == generate() {
+		t.Errorf("want %v, got %v", "another cache name", cache)
+	}
+}
@@ -0,0 +1,171 @@
+package lib
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// PostGroup is a named group of Posts, for example Posts written in a year.
+type PostGroup struct {
+	Key   string
+	Posts []Post
+}
+
+// Posts are Posts passed to templates listing them (like index.tmpl). Templates
+// can range over them like over a slice of Posts.
+type Posts []Post
+
+// PostsByYear returns the Posts grouped by the year they were written in (see
+// groupByYear), the latest year first for Posts sorted by date in descending
+// order, for example {{range .PostsByYear}}<h2>{{.Key}}</h2>...{{end}}.
+func (p Posts) PostsByYear() []PostGroup {
+	return groupByYear(p)
+}
+
+var queryFuncs = map[string]interface{}{
+	"where":       where,
+	"limit":       limit,
+	"first":       limit,
+	"sortBy":      sortBy,
+	"groupByYear": groupByYear,
+	"groupByTag":  groupByTag,
+}
+
+// where returns the posts whose field equals the value. If the field is
+// a slice (like Tags) the posts whose field contains the value are returned.
+// The special field Tag is the same as Tags.
+func where(posts []Post, field string, value interface{}) ([]Post, error) {
+	if field == "Tag" {
+		field = "Tags"
+	}
+
+	var filtered []Post
+	for _, post := range posts {
+		v, err := postField(post, field)
+		if err != nil {
+			return nil, fmt.Errorf("where: %s", err)
+		}
+
+		if v.Kind() == reflect.Slice {
+			for i := 0; i < v.Len(); i++ {
+				if v.Index(i).Interface() == value {
+					filtered = append(filtered, post)
+					break
+				}
+			}
+		} else if v.Interface() == value {
+			filtered = append(filtered, post)
+		}
+	}
+	return filtered, nil
+}
+
+// limit returns at most the first n posts.
+func limit(n int, posts []Post) []Post {
+	if n < 0 {
+		n = 0
+	}
+	if len(posts) > n {
+		return posts[:n]
+	}
+	return posts
+}
+
+// sortBy returns a copy of the posts sorted by the field (or a method without
+// arguments like WordCount) in ascending or descending (if order is "desc")
+// order.
+func sortBy(posts []Post, field string, order ...string) ([]Post, error) {
+	desc := len(order) > 0 && order[0] == "desc"
+
+	sorted := make([]Post, len(posts))
+	copy(sorted, posts)
+
+	var sortErr error
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if desc {
+			i, j = j, i
+		}
+
+		a, err := postField(sorted[i], field)
+		if err != nil {
+			sortErr = err
+			return false
+		}
+		b, _ := postField(sorted[j], field)
+
+		less, err := lessValue(a, b)
+		if err != nil {
+			sortErr = err
+		}
+		return less
+	})
+	if sortErr != nil {
+		return nil, fmt.Errorf("sortBy: %s", sortErr)
+	}
+	return sorted, nil
+}
+
+// groupByYear groups the posts by the year they were written in. The groups
+// keep the order of the posts.
+func groupByYear(posts []Post) []PostGroup {
+	return groupBy(posts, func(post Post) []string {
+		return []string{strconv.Itoa(post.Written.Year())}
+	})
+}
+
+// groupByTag groups the posts by their tags. The groups keep the order in
+// which the tags first appear in the posts.
+func groupByTag(posts []Post) []PostGroup {
+	return groupBy(posts, func(post Post) []string {
+		return post.Tags
+	})
+}
+
+func groupBy(posts []Post, keys func(Post) []string) []PostGroup {
+	var groups []PostGroup
+	indexes := map[string]int{}
+	for _, post := range posts {
+		for _, key := range keys(post) {
+			i, ok := indexes[key]
+			if !ok {
+				i = len(groups)
+				indexes[key] = i
+				groups = append(groups, PostGroup{Key: key})
+			}
+			groups[i].Posts = append(groups[i].Posts, post)
+		}
+	}
+	return groups
+}
+
+// postField returns the value of the Post's field or of the Post's method
+// without arguments.
+func postField(post Post, name string) (reflect.Value, error) {
+	v := reflect.ValueOf(post)
+	if field := v.FieldByName(name); field.IsValid() {
+		return field, nil
+	}
+	if method := v.MethodByName(name); method.IsValid() &&
+		method.Type().NumIn() == 0 && method.Type().NumOut() == 1 {
+		return method.Call(nil)[0], nil
+	}
+	return reflect.Value{}, fmt.Errorf("post has no field %s", name)
+}
+
+func lessValue(a, b reflect.Value) (bool, error) {
+	switch a.Kind() {
+	case reflect.String:
+		return a.String() < b.String(), nil
+	case reflect.Int, reflect.Int64:
+		return a.Int() < b.Int(), nil
+	case reflect.Bool:
+		return !a.Bool() && b.Bool(), nil
+	}
+	if t, ok := a.Interface().(time.Time); ok {
+		return t.Before(b.Interface().(time.Time)), nil
+	}
+	return false, fmt.Errorf("can't sort by %s", a.Type())
+}
@@ -0,0 +1,51 @@
+package lib
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWhereTag(t *testing.T) {
+	posts := []Post{
+		{Title: "A", Tags: []string{"go", "web"}},
+		{Title: "B", Tags: []string{"web"}},
+	}
+
+	filtered, err := where(posts, "Tag", "go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(filtered) != 1 || filtered[0].Title != "A" {
+		t.Errorf("want %v, got %v", posts[:1], filtered)
+	}
+}
+
+func TestSortByDesc(t *testing.T) {
+	posts := []Post{
+		{Title: "A", Written: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{Title: "B", Written: time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	sorted, err := sortBy(posts, "Written", "desc")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if sorted[0].Title != "B" {
+		t.Errorf("want %v, got %v", "B", sorted[0].Title)
+	}
+}
+
+func TestGroupByYear(t *testing.T) {
+	posts := []Post{
+		{Title: "A", Written: time.Date(2021, 5, 1, 0, 0, 0, 0, time.UTC)},
+		{Title: "B", Written: time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{Title: "C", Written: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	groups := groupByYear(posts)
+	if len(groups) != 2 || groups[0].Key != "2021" || len(groups[0].Posts) != 2 {
+		t.Errorf("want 2 groups starting with 2021 (2 posts), got %v", groups)
+	}
+}
@@ -0,0 +1,75 @@
+package lib
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// htmlFrontMatter holds the metadata of a post written in HTML.
+type htmlFrontMatter struct {
+	frontMatter `yaml:",inline"`
+	Title       string   `yaml:"title"`
+	Date        string   `yaml:"date"`
+	Tags        []string `yaml:"tags"`
+	Page        bool     `yaml:"page"`
+}
+
+// htmlToPost parses a post written in HTML. Its metadata is stored as YAML in
+// an HTML comment at the very start of the file (besides the title and date
+// it can contain all fields of the frontMatter):
+//
+//	<!--
+//	title: Title
+//	date: 2009-01-02
+//	tags: [tag1, tag2]
+//	page: true
+//	-->
+//
+//	<p>Content</p>
+//
+// The date is parsed by parsePostDate. The content is used as is.
+func htmlToPost(source string, loc *time.Location) (Post, error) {
+	doc := strings.TrimLeft(strings.ReplaceAll(source, "\r\n", "\n"), "\n ")
+	if !strings.HasPrefix(doc, "<!--") {
+		return Post{}, fmt.Errorf("front matter comment is missing")
+	}
+
+	end := strings.Index(doc, "-->")
+	if end == -1 {
+		return Post{}, fmt.Errorf("front matter comment isn't closed")
+	}
+
+	var fm htmlFrontMatter
+	err := yaml.Unmarshal([]byte(doc[len("<!--"):end]), &fm)
+	if err != nil {
+		return Post{}, fmt.Errorf("failed to parse front matter: %s", err)
+	}
+
+	return fm.post(strings.TrimSpace(doc[end+len("-->"):]), loc)
+}
+
+// post creates a Post with the metadata from the front matter and the content
+// (already in HTML).
+func (fm htmlFrontMatter) post(content string, loc *time.Location) (Post, error) {
+	if fm.Title == "" {
+		return Post{}, fmt.Errorf("title is missing")
+	}
+
+	written, err := parsePostDate(fm.Date, loc)
+	if err != nil {
+		return Post{}, fmt.Errorf("failed to parse date: %s", err)
+	}
+
+	post := Post{Title: fm.Title, Written: written, Tags: fm.Tags,
+		IsPage: fm.Page, Format: FormatHTML, Content: content}
+
+	err = fm.apply(&post, loc)
+	if err != nil {
+		return Post{}, err
+	}
+
+	return post, nil
+}
@@ -0,0 +1,29 @@
+package lib
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHTMLToPost(t *testing.T) {
+	source := "<!--\ntitle: A title\ndate: Aug 10, 2021\ntags: [Test, HTML]\ndescription: Testing\n-->\n\n<p>Testing HTML</p>\n"
+
+	post, err := htmlToPost(source, time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if post.Title != "A title" || post.Description != "Testing" {
+		t.Errorf("want %v/%v, got %v/%v", "A title", "Testing", post.Title,
+			post.Description)
+	}
+
+	want := time.Date(2021, 8, 10, 0, 0, 0, 0, time.UTC)
+	if !post.Written.Equal(want) {
+		t.Errorf("want %v, got %v", want, post.Written)
+	}
+
+	if post.Format != FormatHTML || post.Content != "<p>Testing HTML</p>" {
+		t.Errorf("want HTML content, got %v %q", post.Format, post.Content)
+	}
+}
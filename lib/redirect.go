@@ -0,0 +1,166 @@
+package lib
+
+import (
+	"fmt"
+	stdhtml "html"
+	"io"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Hosts whose redirect files can be generated (see Config.RedirectFiles).
+const (
+	// RedirectsNetlify generates the _redirects file of Netlify (also used by
+	// Cloudflare Pages).
+	RedirectsNetlify = "netlify"
+
+	// RedirectsApache generates the .htaccess file of Apache.
+	RedirectsApache = "apache"
+
+	// RedirectsCaddy generates the redirects.caddy file to be imported in a
+	// Caddyfile.
+	RedirectsCaddy = "caddy"
+)
+
+// redirect is a permanent redirect from a URL relative to the Blog's root.
+type redirect struct {
+	from string
+	to   string
+}
+
+const redirectPage = `<!DOCTYPE html>
+<html>
+  <head>
+    <meta charset="utf-8">
+    <title>Redirecting…</title>
+    <link rel="canonical" href="%[1]s">
+    <meta http-equiv="refresh" content="0; url=%[1]s">
+  </head>
+  <body>
+    <a href="%[1]s">%[1]s</a>
+  </body>
+</html>
+`
+
+// generateRedirect generates a page at the path that redirects to the URL.
+func (g StaticBlogGenerator) generateRedirect(path, url string) error {
+	return g.generateFile(path, func(w io.Writer) error {
+		_, err := fmt.Fprintf(w, redirectPage, stdhtml.EscapeString(url))
+		return err
+	})
+}
+
+// generateTagAliases generates redirect pages from the tag aliases to their
+// canonical tags. Aliases that have the same slug as a tag are skipped.
+func (g StaticBlogGenerator) generateTagAliases() error {
+	for _, r := range g.tagAliasRedirects() {
+		err := g.generateRedirect(g.redirectPagePath(r.from), r.to)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// tagAliasRedirects returns the redirects of the tag aliases.
+func (g StaticBlogGenerator) tagAliasRedirects() []redirect {
+	slugs := map[string]bool{}
+	for _, tag := range g.tags {
+		slugs[tag.Slug] = true
+	}
+
+	var redirects []redirect
+	for alias, canonical := range g.options.config.TagAliases {
+		if !slugs[g.slug(alias)] && slugs[g.slug(canonical)] {
+			redirects = append(redirects, redirect{g.tagURL(alias), g.tagURL(canonical)})
+		}
+	}
+	return redirects
+}
+
+// pageRedirects returns the redirects of the Posts' aliases and of the
+// Config's Redirects sorted by the URLs they redirect from.
+func (g StaticBlogGenerator) pageRedirects() []redirect {
+	var redirects []redirect
+	for _, post := range append(g.posts[:len(g.posts):len(g.posts)], g.unlisted...) {
+		for _, alias := range post.Aliases {
+			redirects = append(redirects, redirect{"/" + strings.TrimPrefix(alias, "/"), g.postURL(post)})
+		}
+	}
+	for from, to := range g.options.config.Redirects {
+		redirects = append(redirects, redirect{"/" + strings.TrimPrefix(from, "/"), to})
+	}
+
+	sortRedirects(redirects)
+	return redirects
+}
+
+// generateRedirects generates redirect pages of the pageRedirects and the
+// configured redirect files with all redirects.
+func (g StaticBlogGenerator) generateRedirects() error {
+	redirects := g.pageRedirects()
+	for _, r := range redirects {
+		err := g.generateRedirect(g.redirectPagePath(r.from), r.to)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(g.options.config.RedirectFiles) == 0 {
+		return nil
+	}
+
+	redirects = append(redirects, g.tagAliasRedirects()...)
+	sortRedirects(redirects)
+	for _, host := range g.options.config.RedirectFiles {
+		var name, format string
+		switch host {
+		case RedirectsNetlify:
+			name, format = "_redirects", "%s %s 301\n"
+		case RedirectsApache:
+			name, format = ".htaccess", "Redirect 301 %q %q\n"
+		case RedirectsCaddy:
+			name, format = "redirects.caddy", "redir %s %s permanent\n"
+		default:
+			return fmt.Errorf("unsupported redirect file: %s", host)
+		}
+
+		err := g.generateFile(name, func(w io.Writer) error {
+			for _, r := range redirects {
+				_, err := fmt.Fprintf(w, format, g.options.config.withBasePath(r.from),
+					g.options.config.withBasePath(r.to))
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// redirectPagePath returns the path of the redirect page of the URL in the
+// output directory. URLs without an extension are pages (see pagePath) or, if
+// they end with a slash, directories with the index.html file.
+func (g StaticBlogGenerator) redirectPagePath(url string) string {
+	p := strings.TrimPrefix(path.Clean(url), "/")
+	if strings.HasSuffix(url, "/") {
+		return filepath.Join(filepath.FromSlash(p), "index.html")
+	}
+	if path.Ext(p) == "" {
+		return g.pagePath(p)
+	}
+	return filepath.FromSlash(p)
+}
+
+func sortRedirects(redirects []redirect) {
+	sort.SliceStable(redirects, func(i, j int) bool {
+		return redirects[i].from < redirects[j].from
+	})
+}
@@ -0,0 +1,54 @@
+package lib
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGenerateRedirects(t *testing.T) {
+	templates := writeTestTemplates(t)
+	blog := Blog{
+		{Title: "Rome", Tags: []string{"cities"}, Written: time.Now(),
+			Aliases: []string{"/2021/08/rome/", "old-rome.html"}},
+	}
+	config := Config{TagAliases: map[string]string{"towns": "cities"},
+		Redirects:     map[string]string{"/about-me.html": "https://example.com/about.html"},
+		RedirectFiles: []string{RedirectsNetlify, RedirectsApache, RedirectsCaddy}}
+
+	output := t.TempDir()
+	g, err := NewStaticBlogGenerator(blog, templates, output, func(string) {},
+		WithConfig(config))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Generate(); err != nil {
+		t.Fatal(err)
+	}
+
+	for path, want := range map[string]string{
+		"2021/08/rome/index.html": `url=/rome.html`,
+		"old-rome.html":           `url=/rome.html`,
+		"about-me.html":           `url=https://example.com/about.html`,
+		"_redirects": "/2021/08/rome/ /rome.html 301\n" +
+			"/about-me.html https://example.com/about.html 301\n" +
+			"/old-rome.html /rome.html 301\n" +
+			"/tags/towns.html /tags/cities.html 301\n",
+		".htaccess":       `Redirect 301 "/old-rome.html" "/rome.html"`,
+		"redirects.caddy": `redir /tags/towns.html /tags/cities.html permanent`,
+	} {
+		bytes, err := os.ReadFile(filepath.Join(output, path))
+		if err != nil || !strings.Contains(string(bytes), want) {
+			t.Errorf("%s: want %q, got %q (%v)", path, want, bytes, err)
+		}
+	}
+
+	config.RedirectFiles = []string{"nginx"}
+	g, _ = NewStaticBlogGenerator(blog, templates, t.TempDir(), func(string) {},
+		WithConfig(config))
+	if err := g.Generate(); err == nil {
+		t.Errorf("want %v, got %v", "an error", err)
+	}
+}
@@ -0,0 +1,139 @@
+package lib
+
+import (
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var (
+	urlAttrRegexp    = regexp.MustCompile(`((?:href|src|poster|action)=")([^"]*)(")`)
+	urlSrcsetRegexp  = regexp.MustCompile(`(srcset=")([^"]*)(")`)
+	urlRefreshRegexp = regexp.MustCompile(`(content="\d+;\s*url=)([^"]*)(")`)
+	urlCSSRegexp     = regexp.MustCompile(`(url\(['"]?)([^'")]*)(['"]?\))`)
+)
+
+// relativizeOutput rewrites internal links (root-relative URLs and URLs
+// starting with the Blog's URL) in the generated HTML and CSS files to paths
+// relative to the files, so the output directory can be browsed without a
+// web server. Links of directories are rewritten to their index.html files
+// and links without an extension (see URLStyleClean) to their .html files.
+func (g StaticBlogGenerator) relativizeOutput() error {
+	return g.rewriteOutputURLs(g.relativeURL)
+}
+
+// prefixOutput prefixes root-relative links in the generated HTML and CSS
+// files with the BasePath.
+func (g StaticBlogGenerator) prefixOutput() error {
+	return g.rewriteOutputURLs(func(from, href string) string {
+		return g.options.config.withBasePath(href)
+	})
+}
+
+// rewriteOutputURLs replaces URLs in the generated HTML and CSS files with
+// the results of the rewrite function called with the URL (relative to the
+// Blog's root) of the file and the URL.
+func (g StaticBlogGenerator) rewriteOutputURLs(rewrite func(from, href string) string) error {
+	return filepath.WalkDir(g.outputDir, func(p string, d fs.DirEntry, err error) error {
+		ext := strings.ToLower(filepath.Ext(p))
+		if err != nil || d.IsDir() || (ext != ".html" && ext != ".css") {
+			return err
+		}
+
+		bytes, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(g.outputDir, p)
+		if err != nil {
+			return err
+		}
+		from := "/" + filepath.ToSlash(rel)
+
+		content := string(bytes)
+		rewritten := rewriteURLs(content, ext == ".html", func(href string) string {
+			return rewrite(from, href)
+		})
+		if rewritten == content {
+			return nil
+		}
+		return os.WriteFile(p, []byte(rewritten), 0600)
+	})
+}
+
+// rewriteURLs replaces URLs in the CSS (url() references) or, if html ==
+// true, HTML (also link, source and refresh attributes) content with the
+// results of the rewrite function.
+func rewriteURLs(content string, html bool, rewrite func(string) string) string {
+	replace := func(re *regexp.Regexp, s string, f func(string) string) string {
+		return re.ReplaceAllStringFunc(s, func(match string) string {
+			groups := re.FindStringSubmatch(match)
+			return groups[1] + f(groups[2]) + groups[3]
+		})
+	}
+
+	content = replace(urlCSSRegexp, content, rewrite)
+	if !html {
+		return content
+	}
+
+	content = replace(urlAttrRegexp, content, rewrite)
+	content = replace(urlRefreshRegexp, content, rewrite)
+	return replace(urlSrcsetRegexp, content, func(srcset string) string {
+		candidates := strings.Split(srcset, ",")
+		for i, candidate := range candidates {
+			fields := strings.Fields(candidate)
+			if len(fields) > 0 {
+				fields[0] = rewrite(fields[0])
+				candidates[i] = strings.Join(fields, " ")
+			}
+		}
+		return strings.Join(candidates, ", ")
+	})
+}
+
+// relativeURL returns the internal URL relative to the page with the URL
+// (relative to the Blog's root). Other URLs are returned unchanged.
+func (g StaticBlogGenerator) relativeURL(from, href string) string {
+	if base := strings.TrimSuffix(g.options.config.URL, "/"); base != "" &&
+		(href == base || strings.HasPrefix(href, base+"/") ||
+			strings.HasPrefix(href, base+"#") || strings.HasPrefix(href, base+"?")) {
+		href = "/" + strings.TrimPrefix(strings.TrimPrefix(href, base), "/")
+	}
+	if !strings.HasPrefix(href, "/") || strings.HasPrefix(href, "//") {
+		return href
+	}
+	href = g.options.config.trimBasePath(href)
+
+	p, rest := href, ""
+	if i := strings.IndexAny(href, "?#"); i >= 0 {
+		p, rest = href[:i], href[i:]
+	}
+
+	target := path.Clean(p)
+	unescaped, err := url.PathUnescape(target)
+	if err != nil {
+		unescaped = target
+	}
+	file := filepath.Join(g.outputDir, filepath.FromSlash(unescaped))
+	info, err := os.Stat(file)
+	switch {
+	case (err == nil && info.IsDir()) || strings.HasSuffix(p, "/"):
+		target = path.Join(target, "index.html")
+	case err != nil && path.Ext(target) == "":
+		if _, err := os.Stat(file + ".html"); err == nil {
+			target += ".html"
+		}
+	}
+
+	relative, err := filepath.Rel(filepath.FromSlash(path.Dir(from)), filepath.FromSlash(target))
+	if err != nil {
+		return href
+	}
+	return filepath.ToSlash(relative) + rest
+}
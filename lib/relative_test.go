@@ -0,0 +1,45 @@
+package lib
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRelativeURLs(t *testing.T) {
+	templates := writeTestTemplates(t)
+	os.WriteFile(filepath.Join(templates, "tag.tmpl"), []byte(
+		`{{define "content"}}<a href="/">Home</a>{{range .Posts}}<a href="{{postURL .}}#top">{{.Title}}</a>{{end}}{{end}}`), 0600)
+	os.WriteFile(filepath.Join(templates, "post.tmpl"), []byte(`{{define "content"}}{{. | html}}{{end}}`), 0600)
+	os.WriteFile(filepath.Join(templates, "bg.png"), nil, 0600)
+	os.WriteFile(filepath.Join(templates, "style.css"), []byte(`body { background: url("/bg.png"); }`), 0600)
+	blog := Blog{{Title: "Rome", Tags: []string{"cities"}, Written: time.Now(),
+		Content: `<img src="https://example.com/bg.png" srcset="/bg.png 1x, //cdn.example.com/bg.png 2x">`}}
+
+	output := t.TempDir()
+	g, err := NewStaticBlogGenerator(blog, templates, output, func(string) {},
+		WithConfig(Config{URL: "https://example.com", URLStyle: URLStyleSlash,
+			RelativeURLs: true, CheckLinks: true, BrokenLinks: BrokenLinksError}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Generate(); err != nil {
+		t.Fatal(err)
+	}
+
+	for file, want := range map[string]string{
+		"tags/cities/index.html": `<a href="../../index.html">Home</a><a href="../../rome/index.html#top">Rome</a>`,
+		"style.css":              `body { background: url("bg.png"); }`,
+		"rome/index.html":        `<img src="../bg.png" srcset="../bg.png 1x, //cdn.example.com/bg.png 2x">`,
+	} {
+		bytes, _ := os.ReadFile(filepath.Join(output, file))
+		if !strings.Contains(string(bytes), want) {
+			t.Errorf("want %q, got %q", want, bytes)
+		}
+	}
+	if got, want := g.relativeURL("/rome/index.html", "//cdn.example.com/bg.png"), "//cdn.example.com/bg.png"; got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+}
@@ -0,0 +1,209 @@
+package lib
+
+import (
+	"fmt"
+	stdhtml "html"
+	"html/template"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Stages of the pipeline rendering a Post's Content. Transformers added with
+// WithTransformer run after one of them.
+const (
+	// StageFrontMatter is the start of the pipeline. The Post's front matter
+	// is already applied and its Content is the source (Markdown or HTML).
+	StageFrontMatter = "frontMatter"
+
+	// StageShortcodes expands shortcodes in the Content.
+	StageShortcodes = "shortcodes"
+
+	// StageTemplate executes the Content as a template if the Config's
+	// TemplatePosts is set.
+	StageTemplate = "template"
+
+	// StageMarkdown renders the Content to HTML. After it the Post's Format
+	// is FormatHTML.
+	StageMarkdown = "markdown"
+
+	// StageHTML post-processes the HTML according to the Config (rewrites
+	// external links and images). It's the end of the pipeline.
+	StageHTML = "html"
+)
+
+// Transformer is a stage of the pipeline rendering a Post's Content. It gets
+// the Post with the Content transformed by the previous stages and returns the
+// new Content.
+type Transformer interface {
+	Transform(post Post) (string, error)
+}
+
+// TransformerFunc is a function used as a Transformer.
+type TransformerFunc func(post Post) (string, error)
+
+// Transform calls f(post).
+func (f TransformerFunc) Transform(post Post) (string, error) {
+	return f(post)
+}
+
+// transformer is a Transformer added to the pipeline after the stage.
+type transformer struct {
+	after       string
+	transformer Transformer
+}
+
+// stage is a named stage of the pipeline.
+type stage struct {
+	name        string
+	transformer Transformer
+}
+
+// stages returns the built-in stages of the pipeline, each followed by the
+// Transformers added after it.
+func (g StaticBlogGenerator) stages() []stage {
+	builtin := []stage{
+		{StageFrontMatter, nil},
+		{StageShortcodes, TransformerFunc(func(post Post) (string, error) {
+			return g.expandShortcodes(post.Content)
+		})},
+		{StageTemplate, nil},
+		{StageMarkdown, TransformerFunc(func(post Post) (string, error) {
+			if post.Format == FormatHTML {
+				return post.Content, nil
+			}
+			return newRenderCache(g.options).of(post).transform(func() (string, error) {
+				return string(renderPost(post)), nil
+			}, StageMarkdown, post.Content)
+		})},
+		{StageHTML, TransformerFunc(func(post Post) (string, error) {
+			return g.postProcess(g.resolveBundleURLs(post, post.Content)), nil
+		})},
+	}
+
+	if g.options.config.TemplatePosts {
+		builtin[2].transformer = TransformerFunc(g.executePost)
+	}
+
+	var stages []stage
+	for _, s := range builtin {
+		stages = append(stages, s)
+		for _, t := range g.options.transformers {
+			if t.after == s.name {
+				stages = append(stages, stage{s.name, t.transformer})
+			}
+		}
+	}
+	return stages
+}
+
+// validateTransformers checks that Transformers are added after existing
+// stages.
+func (g StaticBlogGenerator) validateTransformers() error {
+	for _, t := range g.options.transformers {
+		switch t.after {
+		case StageFrontMatter, StageShortcodes, StageTemplate, StageMarkdown, StageHTML:
+		default:
+			return fmt.Errorf("unknown rendering stage: %s", t.after)
+		}
+	}
+	return nil
+}
+
+// render renders the Post's Content to post-processed HTML with the pipeline
+// of stages. Content of password protected Posts is encrypted.
+func (g StaticBlogGenerator) render(post Post) (template.HTML, error) {
+	for _, s := range g.stages() {
+		if s.transformer == nil {
+			continue
+		}
+
+		started := time.Now()
+		content, err := s.transformer.Transform(post)
+		if err != nil {
+			return "", err
+		}
+		post.Content = content
+		g.options.profile.addRender(s.name, time.Since(started))
+
+		if s.name == StageMarkdown {
+			post.Format = FormatHTML
+		}
+	}
+
+	html := template.HTML(post.Content)
+	if post.Password != "" {
+		return encrypt(html, post.Password)
+	}
+	return html, nil
+}
+
+// postProcess rewrites the rendered HTML according to the Config.
+func (g StaticBlogGenerator) postProcess(html string) string {
+	html = g.rewriteExternalLinks(html)
+	html = g.localizeImages(html)
+	html = g.rewriteImages(html)
+	return html
+}
+
+var attributeRegexp = regexp.MustCompile(
+	`([^\s"'<>/=]+)(?:\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s"'=<>` + "`" + `]+)))?`)
+
+// attribute is an attribute of an HTML start tag. The value is unescaped.
+type attribute struct {
+	name  string
+	value string
+}
+
+// attributes are the attributes of an HTML start tag in their order.
+type attributes []attribute
+
+func (a attributes) get(name string) (string, bool) {
+	for _, attr := range a {
+		if strings.EqualFold(attr.name, name) {
+			return attr.value, true
+		}
+	}
+	return "", false
+}
+
+func (a *attributes) set(name, value string) {
+	for i := range *a {
+		if strings.EqualFold((*a)[i].name, name) {
+			(*a)[i].value = value
+			return
+		}
+	}
+	*a = append(*a, attribute{name, value})
+}
+
+// rewriteStartTags calls the function with the attributes of all start tags
+// with the name in the HTML and replaces the tags with ones with the
+// rewritten attributes.
+func rewriteStartTags(html, name string, rewrite func(attrs *attributes)) string {
+	tagRegexp := regexp.MustCompile(`(?i)<` + name + `(\s[^>]*)?>`)
+	return tagRegexp.ReplaceAllStringFunc(html, func(tag string) string {
+		body := tag[len(name)+1 : len(tag)-1]
+		selfClosing := strings.HasSuffix(body, "/")
+		body = strings.TrimSuffix(body, "/")
+
+		var attrs attributes
+		for _, match := range attributeRegexp.FindAllStringSubmatch(body, -1) {
+			attrs = append(attrs, attribute{match[1],
+				stdhtml.UnescapeString(match[2] + match[3] + match[4])})
+		}
+
+		rewrite(&attrs)
+
+		var b strings.Builder
+		b.WriteString("<" + tag[1:len(name)+1])
+		for _, attr := range attrs {
+			b.WriteString(" " + attr.name + `="` + template.HTMLEscapeString(attr.value) + `"`)
+		}
+		if selfClosing {
+			b.WriteString(" /")
+		}
+		b.WriteString(">")
+		return b.String()
+	})
+}
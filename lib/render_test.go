@@ -0,0 +1,86 @@
+package lib
+
+import (
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRewriteStartTags(t *testing.T) {
+	html := `<p><a href="/a?x=1&amp;y=2" class='c'>A</a><img src=b.png alt="" /><abbr>C</abbr></p>`
+
+	got := rewriteStartTags(html, "a", func(attrs *attributes) { attrs.set("rel", "x") })
+	want := `<p><a href="/a?x=1&amp;y=2" class="c" rel="x">A</a><img src=b.png alt="" /><abbr>C</abbr></p>`
+	if got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+
+	got = rewriteStartTags(html, "img", func(attrs *attributes) {})
+	want = `<p><a href="/a?x=1&amp;y=2" class='c'>A</a><img src="b.png" alt="" /><abbr>C</abbr></p>`
+	if got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+}
+
+func TestRenderTransformers(t *testing.T) {
+	acronyms := TransformerFunc(func(post Post) (string, error) {
+		return strings.ReplaceAll(post.Content, "SSG", "static site generator"), nil
+	})
+	ads := TransformerFunc(func(post Post) (string, error) {
+		if post.Format != FormatHTML {
+			t.Errorf("want %v, got %v", FormatHTML, post.Format)
+		}
+		return post.Content + "<aside>Ad</aside>", nil
+	})
+
+	g := StaticBlogGenerator{options: newOptions([]Option{
+		WithTransformer(StageMarkdown, ads), WithTransformer(StageFrontMatter, acronyms)})}
+	html, err := g.render(Post{Content: "An *SSG*"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "<p>An <em>static site generator</em></p>\n<aside>Ad</aside>"
+	if string(html) != want {
+		t.Errorf("want %q, got %q", want, html)
+	}
+
+	g = StaticBlogGenerator{options: newOptions([]Option{WithTransformer("toc", ads)})}
+	if err := g.validateTransformers(); err == nil {
+		t.Errorf("want %v, got %v", "an error", err)
+	}
+}
+
+func TestRewriteExternalLinks(t *testing.T) {
+	g := StaticBlogGenerator{options: options{config: Config{URL: "https://example.com",
+		ExternalLinks: ExternalLinksConfig{NoReferrer: true, NewTab: true, Exclude: []string{"github.com"}}}}}
+
+	html := `<a href="https://example.org" rel="me">1</a> <a href="https://www.example.com/">2</a> ` +
+		`<a href="https://gist.github.com/x">3</a> <a href="/rome.html">4</a>`
+
+	want := `<a href="https://example.org" rel="me noopener noreferrer" target="_blank">1</a> ` +
+		`<a href="https://www.example.com/">2</a> <a href="https://gist.github.com/x">3</a> <a href="/rome.html">4</a>`
+	if got := g.rewriteExternalLinks(html); got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+}
+
+func TestRewriteImages(t *testing.T) {
+	dir := t.TempDir()
+	file, _ := os.Create(filepath.Join(dir, "rome.png"))
+	png.Encode(file, image.NewRGBA(image.Rect(0, 0, 40, 30)))
+	file.Close()
+
+	g := StaticBlogGenerator{outputDir: dir, options: options{config: Config{LazyImages: true}}}
+
+	html := `<img src="/rome.png" alt="Rome"><img src="paris.png" loading="eager"><img src="/rome.png" width="20">`
+	want := `<img src="/rome.png" alt="Rome" loading="lazy" decoding="async" width="40" height="30">` +
+		`<img src="paris.png" loading="eager" decoding="async">` +
+		`<img src="/rome.png" width="20" loading="lazy" decoding="async">`
+	if got := g.rewriteImages(html); got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+}
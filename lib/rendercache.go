@@ -0,0 +1,113 @@
+package lib
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// RenderCacheDir is the name of the directory in a Blog's directory with the
+// cached results of expensive renders (see Config.RenderCache).
+const RenderCacheDir = ".litepub-cache"
+
+// renderCache caches results of expensive renders of Posts (the Markdown
+// render, transform plugins, external converters like Asciidoctor and scaled
+// images) in files named by hashes of their inputs, so rebuilding unchanged
+// Posts skips them even after the output directory is cleared. The zero value
+// caches nothing.
+type renderCache struct {
+	dir string
+}
+
+// newRenderCache returns the renderCache in the render cache directory of the
+// options (see WithRenderCacheDir) if the Config's RenderCache is set.
+func newRenderCache(o options) renderCache {
+	if !o.config.RenderCache {
+		return renderCache{}
+	}
+	if o.renderCacheDir == "" {
+		return renderCache{o.cachePath("renders", "")}
+	}
+	return renderCache{o.renderCacheDir}
+}
+
+// of returns the renderCache for the Post's renders. Posts read from encrypted
+// files aren't cached, so their plaintext isn't written to disk.
+func (c renderCache) of(post Post) renderCache {
+	if post.Encrypted {
+		return renderCache{}
+	}
+	return c
+}
+
+// transform returns the cached result of the transform with the inputs (which
+// identify the transform, for example by its command, and its input). If
+// there's none, it runs the transform and caches its result. Failed
+// transforms aren't cached.
+func (c renderCache) transform(transform func() (string, error), inputs ...string) (string, error) {
+	if c.dir == "" {
+		return transform()
+	}
+
+	path := filepath.Join(c.dir, templateKey(inputs...))
+	if cached, err := os.ReadFile(path); err == nil {
+		return string(cached), nil
+	}
+
+	result, err := transform()
+	if err != nil {
+		return "", err
+	}
+	// the cache only speeds up builds, so failing to write it isn't an error
+	c.write(path, []byte(result))
+	return result, nil
+}
+
+// file returns the path of the cached file with the extension keyed by the
+// inputs. If it doesn't exist, the create function creates it at the path
+// (it's created in a temporary file renamed to the path afterwards).
+func (c renderCache) file(ext string, create func(path string) error, inputs ...string) (string, error) {
+	path := filepath.Join(c.dir, templateKey(inputs...)+ext)
+	if _, err := os.Stat(path); err == nil {
+		return path, nil
+	}
+
+	err := os.MkdirAll(c.dir, 0700)
+	if err != nil {
+		return "", err
+	}
+	temp, err := os.CreateTemp(c.dir, ".tmp-*"+ext)
+	if err != nil {
+		return "", err
+	}
+	temp.Close()
+
+	err = create(temp.Name())
+	if err != nil {
+		os.Remove(temp.Name())
+		return "", err
+	}
+	return path, os.Rename(temp.Name(), path)
+}
+
+// write writes the result to a temporary file renamed to the path, so
+// concurrent builds don't read partially written results.
+func (c renderCache) write(path string, result []byte) error {
+	err := os.MkdirAll(c.dir, 0700)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.CreateTemp(c.dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(result)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(file.Name())
+		return err
+	}
+	return os.Rename(file.Name(), path)
+}
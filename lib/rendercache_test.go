@@ -0,0 +1,154 @@
+package lib
+
+import (
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRenderCache(t *testing.T) {
+	dir := t.TempDir()
+	posts := filepath.Join(dir, postsDir)
+	os.MkdirAll(filepath.Join(posts, draftDir), 0700)
+	os.WriteFile(filepath.Join(posts, "rome.rst"),
+		[]byte("---\ntitle: Rome\ndate: 2021-08-10\n---\n\nRome\n"), 0600)
+
+	// the processor counts its runs in the runs file
+	config := Config{RenderCache: true,
+		Processors: map[string]string{"rst": `echo run >> runs; sed 's/^/<p>/; s/$/<\/p>/'`}}
+	cache := WithCacheDir(filepath.Join(dir, ".cache"))
+	for i := 0; i < 2; i++ {
+		blog, err := NewMarkdownBlog(dir, WithConfig(config), cache).Read()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := "<p>Rome</p>"; len(blog) != 1 || blog[0].Content != want {
+			t.Errorf("want %q, got %v", want, blog)
+		}
+	}
+	if runs, _ := os.ReadFile(filepath.Join(dir, "runs")); strings.Count(string(runs), "run") != 1 {
+		t.Errorf("want %v, got %v", 1, strings.Count(string(runs), "run"))
+	}
+
+	os.WriteFile(filepath.Join(posts, "rome.rst"),
+		[]byte("---\ntitle: Rome\ndate: 2021-08-10\n---\n\nRome again\n"), 0600)
+	blog, err := NewMarkdownBlog(dir, WithConfig(config), cache).Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "<p>Rome again</p>"; blog[0].Content != want {
+		t.Errorf("want %q, got %q", want, blog[0].Content)
+	}
+	if runs, _ := os.ReadFile(filepath.Join(dir, "runs")); strings.Count(string(runs), "run") != 2 {
+		t.Errorf("want %v, got %v", 2, strings.Count(string(runs), "run"))
+	}
+}
+
+func TestRenderCacheFailedTransform(t *testing.T) {
+	cache := renderCache{t.TempDir()}
+	_, err := cache.transform(func() (string, error) { return "", os.ErrInvalid }, "failing")
+	if err == nil {
+		t.Fatalf("want error, got nil")
+	}
+
+	result, err := cache.transform(func() (string, error) { return "ok", nil }, "failing")
+	if err != nil || result != "ok" {
+		t.Errorf("want %q, got %q (%v)", "ok", result, err)
+	}
+}
+
+func TestRenderCacheMarkdown(t *testing.T) {
+	templates := writeTestTemplates(t)
+	os.WriteFile(filepath.Join(templates, "post.tmpl"),
+		[]byte(`{{define "content"}}{{. | html}}{{end}}`), 0600)
+	blog := Blog{
+		{Title: "Rome", Content: "Rome", Written: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)},
+		{Title: "Idea", Content: "Secret", Written: time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC),
+			Encrypted: true},
+	}
+
+	// a cached render is used instead of rendering the Markdown
+	dir := t.TempDir()
+	cache := renderCache{dir}
+	cache.write(filepath.Join(dir, templateKey(StageMarkdown, "Rome")), []byte("<p>Cached</p>"))
+
+	output := t.TempDir()
+	g, err := NewStaticBlogGenerator(blog, templates, output, func(string) {},
+		WithConfig(Config{RenderCache: true}), WithRenderCacheDir(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Generate(); err != nil {
+		t.Fatal(err)
+	}
+
+	if bytes, _ := os.ReadFile(filepath.Join(output, "rome.html")); string(bytes) != "<p>Cached</p>" {
+		t.Errorf("want %q, got %q", "<p>Cached</p>", bytes)
+	}
+	if bytes, _ := os.ReadFile(filepath.Join(output, "idea.html")); string(bytes) != "<p>Secret</p>\n" {
+		t.Errorf("want %q, got %q", "<p>Secret</p>\n", bytes)
+	}
+	if _, err := os.Stat(filepath.Join(dir, templateKey(StageMarkdown, "Secret"))); err == nil {
+		t.Errorf("want the encrypted post not cached, got it in the cache")
+	}
+}
+
+func TestRenderCacheEncryptedDrafts(t *testing.T) {
+	fakeAge(t)
+	t.Setenv("LITEPUB_DRAFT_IDENTITY", "drafts.txt")
+
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, postsDir, draftDir), 0700)
+	encryption := DraftEncryption{Tool: DraftEncryptionAge, Recipients: []string{"age1example"}}
+	store := NewEncryptedStore(NewDirStore(filepath.Join(dir, postsDir)), encryption)
+	store.Write("draft/idea.rst", []byte("---\ntitle: Idea\ndate: 2021-08-10\n---\n\nSecret\n"))
+
+	cacheDir := filepath.Join(dir, RenderCacheDir)
+	config := Config{RenderCache: true, DraftEncryption: encryption,
+		Processors: map[string]string{"rst": `sed 's/^/<p>/; s/$/<\/p>/'`}}
+	blog, err := NewMarkdownBlog(dir, WithConfig(config), WithRenderCacheDir(cacheDir)).Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(blog) != 1 || blog[0].Content != "<p>Secret</p>" || !blog[0].Encrypted {
+		t.Errorf("want the decrypted draft, got %v", blog)
+	}
+	if entries, _ := os.ReadDir(cacheDir); len(entries) != 0 {
+		t.Errorf("want nothing cached, got %v", entries)
+	}
+}
+
+func TestRenderCacheScaledImages(t *testing.T) {
+	output := t.TempDir()
+	os.MkdirAll(filepath.Join(output, "images"), 0700)
+	file, _ := os.Create(filepath.Join(output, "images", "forum.png"))
+	png.Encode(file, image.NewRGBA(image.Rect(0, 0, 400, 100)))
+	file.Close()
+
+	dir := t.TempDir()
+	g := StaticBlogGenerator{outputDir: output, progressFunc: func(string) {},
+		thumbnails: map[string]image.Point{},
+		options:    options{config: Config{RenderCache: true}, renderCacheDir: dir}}
+	width, height, err := g.generateScaled("images/forum.png", "images/forum-200.png", 200)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if width != 200 || height != 50 {
+		t.Errorf("want %v, got %v", image.Pt(200, 50), image.Pt(width, height))
+	}
+
+	// the cache is keyed by the image's content, not its modification time
+	later := time.Now().Add(time.Hour)
+	os.Chtimes(filepath.Join(output, "images", "forum.png"), later, later)
+	g.thumbnails = map[string]image.Point{}
+	if _, _, err := g.generateScaled("images/forum.png", "images/forum-200.png", 200); err != nil {
+		t.Fatal(err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("want %v cached image, got %v", 1, entries)
+	}
+}
@@ -0,0 +1,271 @@
+package lib
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// S3Config holds settings of S3-compatible object storages. The credentials
+// are read from the AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and (optional)
+// AWS_SESSION_TOKEN environment variables.
+type S3Config struct {
+	// Endpoint is the URL of the storage (for example
+	// https://<account>.r2.cloudflarestorage.com). It's the AWS_ENDPOINT_URL
+	// environment variable or the AWS endpoint of the Region if empty.
+	Endpoint string `yaml:"endpoint"`
+
+	// Region of the bucket. It's the AWS_REGION environment variable or
+	// us-east-1 if empty.
+	Region string `yaml:"region"`
+}
+
+// S3Store is a PostStore backed by a bucket of an S3-compatible object storage.
+// Objects are addressed in the path style (endpoint/bucket/key).
+type S3Store struct {
+	bucket   string
+	prefix   string
+	endpoint string
+	signer   s3Signer
+	client   *http.Client
+}
+
+// NewS3Store creates an S3Store of the bucket (and the optional key prefix) in
+// the URL (for example s3://my-bucket/posts).
+func NewS3Store(storeURL string, config S3Config) (S3Store, error) {
+	u, err := url.Parse(storeURL)
+	if err != nil || u.Scheme != "s3" || u.Host == "" {
+		return S3Store{}, fmt.Errorf("invalid S3 URL: %s", storeURL)
+	}
+
+	signer := s3Signer{os.Getenv("AWS_ACCESS_KEY_ID"), os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		os.Getenv("AWS_SESSION_TOKEN"), config.Region}
+	if signer.accessKey == "" || signer.secretKey == "" {
+		return S3Store{}, errors.New("AWS_ACCESS_KEY_ID or AWS_SECRET_ACCESS_KEY isn't set")
+	}
+	if signer.region == "" {
+		signer.region = os.Getenv("AWS_REGION")
+	}
+	if signer.region == "" {
+		signer.region = "us-east-1"
+	}
+
+	endpoint := config.Endpoint
+	if endpoint == "" {
+		endpoint = os.Getenv("AWS_ENDPOINT_URL")
+	}
+	if endpoint == "" {
+		endpoint = "https://s3." + signer.region + ".amazonaws.com"
+	}
+
+	prefix := strings.Trim(u.Path, "/")
+	if prefix != "" {
+		prefix += "/"
+	}
+	return S3Store{u.Host, prefix, strings.TrimSuffix(endpoint, "/"), signer,
+		&http.Client{Timeout: time.Minute}}, nil
+}
+
+// IsS3URL tells whether the source is the URL of an S3 bucket (see
+// NewS3Store).
+func IsS3URL(source string) bool {
+	return strings.HasPrefix(source, "s3://")
+}
+
+type s3ListResult struct {
+	Contents []struct {
+		Key          string    `xml:"Key"`
+		LastModified time.Time `xml:"LastModified"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+// List returns all objects with the prefix.
+func (s S3Store) List() ([]StoredFile, error) {
+	var files []StoredFile
+	token := ""
+	for {
+		query := url.Values{"list-type": {"2"}, "prefix": {s.prefix}}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+
+		body, err := s.do(http.MethodGet, "", query, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		var result s3ListResult
+		err = xml.Unmarshal(body, &result)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse objects: %s", err)
+		}
+
+		for _, object := range result.Contents {
+			name := strings.TrimPrefix(object.Key, s.prefix)
+			if name == "" || strings.HasSuffix(name, "/") {
+				continue
+			}
+			files = append(files, StoredFile{name, object.LastModified})
+		}
+
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return files, nil
+		}
+		token = result.NextContinuationToken
+	}
+}
+
+// Read returns the content of the object.
+func (s S3Store) Read(name string) ([]byte, error) {
+	return s.do(http.MethodGet, s.prefix+name, nil, nil)
+}
+
+// Write creates or replaces the object.
+func (s S3Store) Write(name string, data []byte) error {
+	_, err := s.do(http.MethodPut, s.prefix+name, nil, data)
+	return err
+}
+
+// Remove removes the object.
+func (s S3Store) Remove(name string) error {
+	_, err := s.do(http.MethodDelete, s.prefix+name, nil, nil)
+	return err
+}
+
+// do sends a signed request for the key (or for the bucket if it's empty) and
+// returns the response body.
+func (s S3Store) do(method, key string, query url.Values, payload []byte) ([]byte, error) {
+	u, err := url.Parse(s.endpoint)
+	if err != nil {
+		return nil, err
+	}
+	u.Path += "/" + s.bucket + "/" + key
+	u.RawPath = awsEscape(u.Path, true)
+	u.RawQuery = query.Encode()
+
+	req, err := http.NewRequest(method, u.String(), bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	s.signer.sign(req, payload, time.Now())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		return nil, fmt.Errorf("%s %s: %s", method, u.Path, resp.Status)
+	}
+	return body, nil
+}
+
+// s3Signer signs requests with the AWS Signature Version 4.
+type s3Signer struct {
+	accessKey    string
+	secretKey    string
+	sessionToken string
+	region       string
+}
+
+// sign adds the x-amz-* and Authorization headers to the request. All headers
+// set before are signed, too.
+func (s s3Signer) sign(req *http.Request, payload []byte, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	payloadHash := sha256Hex(payload)
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if s.sessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", s.sessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{req.Method, awsEscape(req.URL.Path, true),
+		canonicalQuery(req.URL.Query()), canonicalHeaders.String(), signedHeaders,
+		payloadHash}, "\n")
+
+	date := amzDate[:8]
+	scope := date + "/" + s.region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" +
+		sha256Hex([]byte(canonicalRequest))
+
+	key := []byte("AWS4" + s.secretKey)
+	for _, part := range []string{date, s.region, "s3", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential="+s.accessKey+"/"+scope+
+		", SignedHeaders="+signedHeaders+", Signature="+signature)
+}
+
+func canonicalQuery(query url.Values) string {
+	var params []string
+	for name, values := range query {
+		for _, value := range values {
+			params = append(params, awsEscape(name, false)+"="+awsEscape(value, false))
+		}
+	}
+	sort.Strings(params)
+	return strings.Join(params, "&")
+}
+
+// awsEscape percent-encodes all bytes of the string except unreserved
+// characters (and slashes if keepSlash == true).
+func awsEscape(s string, keepSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' ||
+			c == '-' || c == '_' || c == '.' || c == '~' || (c == '/' && keepSlash) {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,175 @@
+package lib
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// scheduleShortcuts are the cron expressions of the schedule shortcuts.
+var scheduleShortcuts = map[string]string{
+	"@hourly":  "0 * * * *",
+	"@daily":   "0 0 * * *",
+	"@weekly":  "0 0 * * 0",
+	"@monthly": "0 0 1 * *",
+	"@yearly":  "0 0 1 1 *",
+}
+
+// scheduleFields are the ranges of the fields of a cron expression.
+var scheduleFields = []struct {
+	name     string
+	min, max int
+}{
+	{"minute", 0, 59},
+	{"hour", 0, 23},
+	{"day of month", 1, 31},
+	{"month", 1, 12},
+	{"day of week", 0, 7},
+}
+
+// Schedule is a cron-like schedule of rebuilds of a Blog.
+type Schedule struct {
+	minutes, hours, days, months, weekdays []bool
+
+	// anyDay tells whether the day of month or the day of week field is *, so
+	// only the other one restricts days (otherwise days matching either of
+	// them match, like in cron).
+	anyDay bool
+}
+
+// ParseSchedule parses the cron expression with 5 fields (minute, hour, day
+// of month, month and day of week) separated by spaces, for example
+// "30 6 * * 1-5". Fields can be *, numbers, ranges (1-5), steps (*/15 or
+// 0-30/10) and comma separated lists of them. The @hourly, @daily, @weekly,
+// @monthly and @yearly shortcuts are also supported.
+func ParseSchedule(expr string) (Schedule, error) {
+	if shortcut, ok := scheduleShortcuts[strings.TrimSpace(expr)]; ok {
+		expr = shortcut
+	}
+
+	fields := strings.Fields(expr)
+	if len(fields) != len(scheduleFields) {
+		return Schedule{}, fmt.Errorf("invalid schedule: %s: want %d fields, got %d",
+			expr, len(scheduleFields), len(fields))
+	}
+
+	var parsed [][]bool
+	for i, field := range fields {
+		values, err := parseScheduleField(field, scheduleFields[i].min, scheduleFields[i].max)
+		if err != nil {
+			return Schedule{}, fmt.Errorf("invalid %s in schedule: %s: %s",
+				scheduleFields[i].name, field, err)
+		}
+		parsed = append(parsed, values)
+	}
+
+	// both 0 and 7 are Sunday
+	parsed[4][0] = parsed[4][0] || parsed[4][7]
+
+	return Schedule{minutes: parsed[0], hours: parsed[1], days: parsed[2],
+		months: parsed[3], weekdays: parsed[4],
+		anyDay: strings.HasPrefix(fields[2], "*") || strings.HasPrefix(fields[4], "*")}, nil
+}
+
+func parseScheduleField(field string, min, max int) ([]bool, error) {
+	values := make([]bool, max+1)
+	for _, part := range strings.Split(field, ",") {
+		step := 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			n, err := strconv.Atoi(part[i+1:])
+			if err != nil || n <= 0 {
+				return nil, fmt.Errorf("invalid step: %s", part[i+1:])
+			}
+			step = n
+			part = part[:i]
+		}
+
+		from, to := min, max
+		if part != "*" {
+			bounds := strings.SplitN(part, "-", 2)
+			n, err := strconv.Atoi(bounds[0])
+			if err != nil {
+				return nil, fmt.Errorf("invalid value: %s", bounds[0])
+			}
+			from, to = n, n
+			if len(bounds) == 2 {
+				to, err = strconv.Atoi(bounds[1])
+				if err != nil {
+					return nil, fmt.Errorf("invalid value: %s", bounds[1])
+				}
+			} else if step > 1 {
+				to = max
+			}
+		}
+		if from < min || to > max || from > to {
+			return nil, fmt.Errorf("out of range %d-%d", min, max)
+		}
+
+		for n := from; n <= to; n += step {
+			values[n] = true
+		}
+	}
+	return values, nil
+}
+
+// Next returns the first time after the time (in its location) matching the
+// Schedule. It returns the zero time if there's none in the next 5 years (for
+// example for February 30).
+func (s Schedule) Next(after time.Time) time.Time {
+	t := after.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		if !s.months[t.Month()] {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.matchesDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.hours[t.Hour()] {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.minutes[t.Minute()] {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+func (s Schedule) matchesDay(t time.Time) bool {
+	day, weekday := s.days[t.Day()], s.weekdays[t.Weekday()]
+	if s.anyDay {
+		return day && weekday
+	}
+	return day || weekday
+}
+
+// NextDue returns the first time after now when a Post (except drafts) is
+// published (only if scheduled == true, see Config.ScheduledPosts) or
+// expires, so a Blog can be rebuilt when it changes. It returns the zero time
+// if there's none.
+func (b Blog) NextDue(now time.Time, scheduled bool) time.Time {
+	var next time.Time
+	for _, post := range b {
+		if post.Draft {
+			continue
+		}
+
+		dues := []time.Time{post.Expires}
+		if scheduled {
+			dues = append(dues, post.Written)
+		}
+		for _, due := range dues {
+			if due.After(now) && (next.IsZero() || due.Before(next)) {
+				next = due
+			}
+		}
+	}
+	return next
+}
@@ -0,0 +1,64 @@
+package lib
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScheduleNext(t *testing.T) {
+	after := time.Date(2024, 6, 1, 10, 17, 30, 0, time.UTC)
+
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"*/15 * * * *", time.Date(2024, 6, 1, 10, 30, 0, 0, time.UTC)},
+		{"0 * * * *", time.Date(2024, 6, 1, 11, 0, 0, 0, time.UTC)},
+		{"30 6 * * 1-5", time.Date(2024, 6, 3, 6, 30, 0, 0, time.UTC)},
+		{"@daily", time.Date(2024, 6, 2, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1 * *", time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 12 13 * 5", time.Date(2024, 6, 7, 12, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2024, 6, 2, 0, 0, 0, 0, time.UTC)},
+		{"0,45 9-11 * 6 *", time.Date(2024, 6, 1, 10, 45, 0, 0, time.UTC)},
+		{"0 0 30 2 *", time.Time{}},
+	}
+	for _, test := range tests {
+		schedule, err := ParseSchedule(test.expr)
+		if err != nil {
+			t.Fatalf("%s: %s", test.expr, err)
+		}
+		if got := schedule.Next(after); !got.Equal(test.want) {
+			t.Errorf("%s: want %v, got %v", test.expr, test.want, got)
+		}
+	}
+}
+
+func TestParseScheduleErrors(t *testing.T) {
+	for _, expr := range []string{"* * * *", "60 * * * *", "*/0 * * * *", "a * * * *",
+		"5-1 * * * *", "* * 0 * *"} {
+		if _, err := ParseSchedule(expr); err == nil {
+			t.Errorf("%s: want error, got nil", expr)
+		}
+	}
+}
+
+func TestBlogNextDue(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	blog := Blog{
+		{Title: "Published", Written: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)},
+		{Title: "Expiring", Written: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+			Expires: time.Date(2024, 6, 20, 0, 0, 0, 0, time.UTC)},
+		{Title: "Scheduled", Written: time.Date(2024, 6, 10, 8, 0, 0, 0, time.UTC)},
+		{Title: "Draft", Written: time.Date(2024, 6, 5, 0, 0, 0, 0, time.UTC), Draft: true},
+	}
+
+	if want, got := time.Date(2024, 6, 10, 8, 0, 0, 0, time.UTC), blog.NextDue(now, true); !got.Equal(want) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+	if want, got := time.Date(2024, 6, 20, 0, 0, 0, 0, time.UTC), blog.NextDue(now, false); !got.Equal(want) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+	if got := blog[:1].NextDue(now, true); !got.IsZero() {
+		t.Errorf("want zero time, got %v", got)
+	}
+}
@@ -0,0 +1,55 @@
+package lib
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// shortcodeFunc renders a shortcode with the arguments to HTML.
+type shortcodeFunc func(g StaticBlogGenerator, args []string) (string, error)
+
+// shortcodes are the available shortcodes keyed by their names.
+var shortcodes = map[string]shortcodeFunc{
+	"gallery":  gallery,
+	"youtube":  youtube,
+	"vimeo":    vimeo,
+	"mastodon": mastodon,
+}
+
+var (
+	shortcodeRegexp = regexp.MustCompile(`\{\{<\s*(\w+)((?:\s+(?:"[^"]*"|[^\s">]+))*)\s*>\}\}`)
+	argRegexp       = regexp.MustCompile(`"([^"]*)"|(\S+)`)
+)
+
+// expandShortcodes replaces {{< name args >}} shortcodes in the Content with
+// their HTML.
+func (g StaticBlogGenerator) expandShortcodes(content string) (string, error) {
+	var expandErr error
+	expanded := shortcodeRegexp.ReplaceAllStringFunc(content, func(code string) string {
+		if expandErr != nil {
+			return code
+		}
+
+		match := shortcodeRegexp.FindStringSubmatch(code)
+		shortcode, ok := shortcodes[match[1]]
+		if !ok {
+			expandErr = fmt.Errorf("unknown shortcode: %s", match[1])
+			return code
+		}
+
+		var args []string
+		for _, arg := range argRegexp.FindAllStringSubmatch(match[2], -1) {
+			args = append(args, arg[1]+arg[2])
+		}
+
+		html, err := shortcode(g, args)
+		if err != nil {
+			expandErr = fmt.Errorf("failed to expand shortcode %s: %s", match[1], err)
+			return code
+		}
+		return "\n\n" + strings.TrimSpace(html) + "\n\n"
+	})
+
+	return expanded, expandErr
+}
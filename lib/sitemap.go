@@ -0,0 +1,156 @@
+package lib
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Limits of a single sitemap file. Larger sitemaps are split into several
+// files listed in a sitemap index.
+var (
+	sitemapMaxURLs  = 50000
+	sitemapMaxBytes = 50 * 1024 * 1024
+)
+
+type sitemapURLSet struct {
+	XMLName xml.Name     `xml:"urlset"`
+	Xmlns   string       `xml:"xmlns,attr"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+type sitemapURL struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+type sitemapIndex struct {
+	XMLName  xml.Name       `xml:"sitemapindex"`
+	Xmlns    string         `xml:"xmlns,attr"`
+	Sitemaps []sitemapEntry `xml:"sitemap"`
+}
+
+type sitemapEntry struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+// sitemapPart is a part of a split sitemap with the last modification date of
+// its URLs.
+type sitemapPart struct {
+	urls    []sitemapURL
+	lastMod time.Time
+}
+
+const sitemapXmlns = "http://www.sitemaps.org/schemas/sitemap/0.9"
+
+// generateSitemap generates the sitemap.xml file. If the sitemap exceeds the
+// limits of a single file, it's split into sitemap-1.xml, sitemap-2.xml and so
+// on and the sitemap.xml file is a sitemap index referencing them.
+func (g StaticBlogGenerator) generateSitemap() error {
+	var urls []sitemapURL
+	var lastMods []time.Time
+	add := func(loc string, lastMod time.Time) {
+		urls = append(urls, sitemapURL{loc, g.dates.rfc3339(lastMod)})
+		lastMods = append(lastMods, lastMod)
+	}
+
+	add(g.absURL(""), lastModified(g.posts))
+	for _, tag := range g.tags {
+		if g.options.config.Taxonomies.Tags.sitemap(tag.Name, true) {
+			add(g.absPageURL(g.tagPath(tag.Name)), lastModified(tag.Posts))
+		}
+	}
+	if g.authorTemplate != nil {
+		for _, author := range g.authors {
+			if g.options.config.Taxonomies.Authors.sitemap(author.Name, false) {
+				add(g.absPageURL(g.authorPath(author.Name)), lastModified(author.Posts))
+			}
+		}
+	}
+	for _, post := range g.posts {
+		if !post.NoIndex {
+			add(g.absPageURL(g.postPath(post)), post.LastModified())
+		}
+	}
+
+	parts, err := splitSitemap(urls, lastMods)
+	if err != nil {
+		return err
+	}
+	if len(parts) == 1 {
+		return g.generateFile("sitemap.xml", func(w io.Writer) error {
+			return writeXML(w, sitemapURLSet{Xmlns: sitemapXmlns, URLs: urls})
+		})
+	}
+
+	index := sitemapIndex{Xmlns: sitemapXmlns}
+	for i, part := range parts {
+		name := fmt.Sprintf("sitemap-%d.xml", i+1)
+		err := g.generateFile(name, func(w io.Writer) error {
+			return writeXML(w, sitemapURLSet{Xmlns: sitemapXmlns, URLs: part.urls})
+		})
+		if err != nil {
+			return err
+		}
+		index.Sitemaps = append(index.Sitemaps,
+			sitemapEntry{g.absURL(name), g.dates.rfc3339(part.lastMod)})
+	}
+
+	return g.generateFile("sitemap.xml", func(w io.Writer) error {
+		return writeXML(w, index)
+	})
+}
+
+// splitSitemap splits the URLs (with their last modification dates) into parts
+// within the sitemapMaxURLs and sitemapMaxBytes limits.
+func splitSitemap(urls []sitemapURL, lastMods []time.Time) ([]sitemapPart, error) {
+	overhead := len(xml.Header) + len(`<urlset xmlns="`+sitemapXmlns+`">`+"\n</urlset>")
+
+	parts := []sitemapPart{{}}
+	size := overhead
+	for i, url := range urls {
+		bytes, err := xml.MarshalIndent(url, "  ", "  ")
+		if err != nil {
+			return nil, err
+		}
+
+		part := &parts[len(parts)-1]
+		if len(part.urls) > 0 &&
+			(len(part.urls) >= sitemapMaxURLs || size+len(bytes)+1 > sitemapMaxBytes) {
+			parts = append(parts, sitemapPart{})
+			part = &parts[len(parts)-1]
+			size = overhead
+		}
+
+		part.urls = append(part.urls, url)
+		size += len(bytes) + 1
+		if lastMods[i].After(part.lastMod) {
+			part.lastMod = lastMods[i]
+		}
+	}
+	return parts, nil
+}
+
+// lastModified returns the newest LastModified date of the posts.
+func lastModified(posts []Post) time.Time {
+	var last time.Time
+	for _, post := range posts {
+		if post.LastModified().After(last) {
+			last = post.LastModified()
+		}
+	}
+	return last
+}
+
+func writeXML(w io.Writer, v interface{}) error {
+	_, err := io.WriteString(w, xml.Header)
+	if err != nil {
+		return err
+	}
+
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	return enc.Encode(v)
+}
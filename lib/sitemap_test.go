@@ -0,0 +1,88 @@
+package lib
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGenerateSplitSitemap(t *testing.T) {
+	defer func(urls, bytes int) { sitemapMaxURLs, sitemapMaxBytes = urls, bytes }(
+		sitemapMaxURLs, sitemapMaxBytes)
+	sitemapMaxURLs = 2
+
+	blog := Blog{
+		{Title: "Rome", Written: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{Title: "Paris", Written: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{Title: "Oslo", Written: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	output := t.TempDir()
+	g, err := NewStaticBlogGenerator(blog, writeTestTemplates(t), output, func(string) {},
+		WithConfig(Config{URL: "https://example.com"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Generate(); err != nil {
+		t.Fatal(err)
+	}
+
+	for path, want := range map[string][]string{
+		"sitemap.xml": {"<sitemapindex",
+			"<loc>https://example.com/sitemap-1.xml</loc>\n    <lastmod>2024-03-01T00:00:00Z</lastmod>",
+			"<loc>https://example.com/sitemap-2.xml</loc>\n    <lastmod>2024-02-01T00:00:00Z</lastmod>"},
+		"sitemap-1.xml": {"<loc>https://example.com/</loc>", "<loc>https://example.com/rome.html</loc>"},
+		"sitemap-2.xml": {"<loc>https://example.com/paris.html</loc>", "<loc>https://example.com/oslo.html</loc>"},
+	} {
+		bytes, _ := os.ReadFile(filepath.Join(output, path))
+		for _, w := range want {
+			if !strings.Contains(string(bytes), w) {
+				t.Errorf("%s: want %q in %s", path, w, bytes)
+			}
+		}
+	}
+
+	sitemapMaxURLs = 50000
+	sitemapMaxBytes = 300
+	parts, err := splitSitemap([]sitemapURL{{Loc: strings.Repeat("a", 100)},
+		{Loc: strings.Repeat("b", 100)}}, make([]time.Time, 2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(parts) != 2 {
+		t.Errorf("want %v, got %v", 2, len(parts))
+	}
+}
+
+func TestGenerateSitemapNoIndex(t *testing.T) {
+	md := "---\nnoindex: true\n---\n\n# Paris\n\n*Feb 1, 2024*\n\nNot for search engines.\n"
+	paris, err := markdownToPost(md, time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !paris.NoIndex {
+		t.Errorf("want %v, got %v", true, paris.NoIndex)
+	}
+	blog := Blog{{Title: "Rome", Written: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)}, paris}
+
+	output := t.TempDir()
+	g, err := NewStaticBlogGenerator(blog, writeTestTemplates(t), output, func(string) {},
+		WithConfig(Config{URL: "https://example.com"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Generate(); err != nil {
+		t.Fatal(err)
+	}
+
+	sitemap, _ := os.ReadFile(filepath.Join(output, "sitemap.xml"))
+	if !strings.Contains(string(sitemap), "/rome.html") || strings.Contains(string(sitemap), "/paris.html") {
+		t.Errorf("want rome.html without paris.html, got %s", sitemap)
+	}
+	index, _ := os.ReadFile(filepath.Join(output, "index.html"))
+	if want := "Rome;Paris;"; string(index) != want {
+		t.Errorf("want %q, got %q", want, index)
+	}
+}
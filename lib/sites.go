@@ -0,0 +1,96 @@
+package lib
+
+import (
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+)
+
+// SitesFile is the name of the optional file listing Blogs generated together
+// in one run. It looks like this:
+//
+//	templates: theme
+//	sites:
+//	  - blog
+//	  - linklog
+//	  - photos
+const SitesFile = "sites.yaml"
+
+// SitesConfig lists Blog directories (relative to the directory with the
+// SitesFile) generated together with the shared templates directory.
+type SitesConfig struct {
+	// Templates is the templates directory used by all Blogs. If it's
+	// empty each Blog uses its own templates.
+	Templates string   `yaml:"templates"`
+	Sites     []string `yaml:"sites"`
+}
+
+// ReadSitesConfig reads the SitesConfig from the SitesFile in the provided
+// directory. If the file doesn't exist it returns the zero SitesConfig.
+func ReadSitesConfig(dir string) (SitesConfig, error) {
+	var config SitesConfig
+	err := readYAML(filepath.Join(dir, SitesFile), &config)
+	if err != nil {
+		return config, fmt.Errorf("failed to read sites: %s", err)
+	}
+	return config, nil
+}
+
+// layoutTemplates are the templateFiles combined with the layout.tmpl.
+var layoutTemplates = []string{"index.tmpl", "post.tmpl", "tag.tmpl",
+	"tags.tmpl", "404.tmpl", "expired.tmpl", "author.tmpl", "blogroll.tmpl",
+	"archive.tmpl", "link.tmpl"}
+
+// Templates are the parsed templates of a templates directory shared by
+// StaticBlogGenerators of multiple Blogs (see WithTemplates), so they're
+// parsed only once.
+type Templates struct {
+	dir    string
+	parsed map[string]*template.Template
+}
+
+// ParseTemplates parses the templates in the directory. The options (for
+// example WithPlugin) must provide the same template functions as the ones
+// of the generators using the Templates.
+func ParseTemplates(dir string, opts ...Option) (Templates, error) {
+	g := StaticBlogGenerator{options: newOptions(opts), templatesDir: dir}
+
+	tagTemplates, err := filepath.Glob(filepath.Join(dir, tagTemplateFile("*")))
+	if err != nil {
+		return Templates{}, err
+	}
+	names := layoutTemplates
+	for _, file := range tagTemplates {
+		names = append(names[:len(names):len(names)], filepath.Base(file))
+	}
+
+	t := Templates{dir, map[string]*template.Template{}}
+	for _, name := range names {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			continue
+		}
+
+		parsed, err := g.parseTemplate(name)
+		if err != nil {
+			return Templates{}, err
+		}
+		t.parsed[name] = parsed
+	}
+	return t, nil
+}
+
+// template returns a copy of the parsed template (using the funcs) or nil if
+// it wasn't parsed.
+func (t Templates) template(dir, name string, funcs template.FuncMap) (*template.Template, error) {
+	parsed, ok := t.parsed[name]
+	if !ok || filepath.Clean(dir) != filepath.Clean(t.dir) {
+		return nil, nil
+	}
+
+	clone, err := parsed.Clone()
+	if err != nil {
+		return nil, err
+	}
+	return clone.Funcs(funcs), nil
+}
@@ -0,0 +1,53 @@
+package lib
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSharedTemplates(t *testing.T) {
+	templates := writeTestTemplates(t)
+	os.WriteFile(filepath.Join(templates, "index.tmpl"),
+		[]byte(`{{define "content"}}{{site.Title}}:{{range .}}{{.Title}};{{end}}{{end}}`), 0600)
+
+	shared, err := ParseTemplates(templates)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := shared.parsed["tags.tmpl"]; ok || shared.parsed["index.tmpl"] == nil {
+		t.Errorf("want %v, got %v", "index.tmpl without tags.tmpl", shared.parsed)
+	}
+
+	for title, post := range map[string]string{"Blog": "Rome", "Linklog": "Paris"} {
+		output := t.TempDir()
+		g, err := NewStaticBlogGenerator(Blog{{Title: post, Written: time.Now()}}, templates,
+			output, func(string) {}, WithConfig(Config{Title: title}), WithTemplates(shared))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := g.Generate(); err != nil {
+			t.Fatal(err)
+		}
+
+		bytes, _ := os.ReadFile(filepath.Join(output, "index.html"))
+		if want := title + ":" + post + ";"; string(bytes) != want {
+			t.Errorf("want %q, got %q", want, bytes)
+		}
+	}
+}
+
+func TestReadSitesConfig(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, SitesFile),
+		[]byte("templates: theme\nsites:\n  - blog\n  - linklog\n"), 0600)
+
+	config, err := ReadSitesConfig(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Templates != "theme" || len(config.Sites) != 2 || config.Sites[1] != "linklog" {
+		t.Errorf("want %v, got %v", "theme with blog and linklog", config)
+	}
+}
@@ -0,0 +1,126 @@
+package lib
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/gosimple/slug"
+)
+
+// slugLanguages are the languages with specific transliteration rules (for
+// example ä becomes ae in German).
+var slugLanguages = map[string]bool{"de": true, "en": true, "es": true,
+	"fi": true, "fr": true, "gr": true, "el": true, "kk": true, "nb": true,
+	"nl": true, "nn": true, "pl": true, "sl": true, "sv": true, "tr": true}
+
+// SlugConfig holds settings of the slugs of titles, tags and authors used in
+// URLs. The zero value transliterates them to ASCII with the English rules.
+type SlugConfig struct {
+	// Language of the transliteration rules (for example de or pl). It's
+	// ignored if Unicode is set.
+	Language string `yaml:"language"`
+
+	// Unicode tells whether letters and digits are kept instead of being
+	// transliterated to ASCII (useful for CJK or Cyrillic titles).
+	Unicode bool `yaml:"unicode"`
+
+	// MaxLength is the maximum number of characters of slugs. They're
+	// truncated after whole words if possible.
+	MaxLength int `yaml:"maxLength"`
+
+	// Stopwords are words left out of slugs (unless they'd be empty), for
+	// example a, an and the.
+	Stopwords []string `yaml:"stopwords"`
+}
+
+func (c SlugConfig) validate() error {
+	if c.Language != "" && !slugLanguages[strings.ToLower(c.Language)] {
+		return fmt.Errorf("unsupported slug language: %s", c.Language)
+	}
+	if c.MaxLength < 0 {
+		return fmt.Errorf("invalid slug max length: %d", c.MaxLength)
+	}
+	return nil
+}
+
+// slugify returns the slug of the string according to the SlugConfig.
+func (c SlugConfig) slugify(str string) string {
+	var s string
+	switch {
+	case c.Unicode:
+		s = unicodeSlug(str)
+	case c.Language != "":
+		s = slug.MakeLang(str, c.Language)
+	default:
+		s = slug.Make(str)
+	}
+
+	if len(c.Stopwords) > 0 {
+		stopwords := map[string]bool{}
+		for _, word := range c.Stopwords {
+			stopwords[strings.ToLower(word)] = true
+		}
+
+		var words []string
+		for _, word := range strings.Split(s, "-") {
+			if !stopwords[word] {
+				words = append(words, word)
+			}
+		}
+		if len(words) > 0 {
+			s = strings.Join(words, "-")
+		}
+	}
+
+	if c.MaxLength > 0 {
+		s = truncateSlug(s, c.MaxLength)
+	}
+	return s
+}
+
+// unicodeSlug returns the lowercase slug of the string keeping its letters
+// and digits (in any script) and replacing other characters with dashes.
+func unicodeSlug(str string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(str) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r) || r == '_' {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+		} else {
+			dash = true
+		}
+	}
+	return strings.Trim(b.String(), "_")
+}
+
+// truncateSlug truncates the slug to at most max characters after its last
+// whole word (the first word is cut if it's longer).
+func truncateSlug(s string, max int) string {
+	if len([]rune(s)) <= max {
+		return s
+	}
+
+	words := strings.Split(s, "-")
+	if first := []rune(words[0]); len(first) > max {
+		return string(first[:max])
+	}
+
+	truncated := words[0]
+	for _, word := range words[1:] {
+		if len([]rune(truncated))+1+len([]rune(word)) > max {
+			break
+		}
+		truncated += "-" + word
+	}
+	return truncated
+}
+
+// slug returns the slug of the string (see WithSlugger).
+func (g StaticBlogGenerator) slug(str string) string {
+	return g.options.slug(str)
+}
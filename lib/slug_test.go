@@ -0,0 +1,105 @@
+package lib
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSlugify(t *testing.T) {
+	for _, test := range []struct {
+		config SlugConfig
+		str    string
+		want   string
+	}{
+		{SlugConfig{}, "Über Straße", "uber-strasse"},
+		{SlugConfig{Language: "de"}, "Über Straße", "ueber-strasse"},
+		{SlugConfig{Unicode: true}, "Привет, мир!", "привет-мир"},
+		{SlugConfig{Unicode: true}, "東京 2020", "東京-2020"},
+		{SlugConfig{MaxLength: 12}, "The Eternal City of Rome", "the-eternal"},
+		{SlugConfig{MaxLength: 3}, "Rome", "rom"},
+		{SlugConfig{Unicode: true, MaxLength: 2}, "東京都", "東京"},
+		{SlugConfig{Stopwords: []string{"the", "Of"}}, "The Eternal City of Rome", "eternal-city-rome"},
+		{SlugConfig{Stopwords: []string{"the"}}, "The", "the"},
+	} {
+		if got := test.config.slugify(test.str); got != test.want {
+			t.Errorf("%+v: want %q, got %q", test.config, test.want, got)
+		}
+	}
+}
+
+func TestSlugConfig(t *testing.T) {
+	templates := writeTestTemplates(t)
+	os.WriteFile(filepath.Join(templates, "index.tmpl"), []byte(
+		`{{define "content"}}{{range .}}{{postURL .}} {{range .Tags}}{{tagURL .}}{{end}} {{slug .Title}}{{end}}{{end}}`), 0600)
+	blog := Blog{{Title: "Москва", Tags: []string{"Города"}, Written: time.Now()}}
+
+	output := t.TempDir()
+	g, err := NewStaticBlogGenerator(blog, templates, output, func(string) {},
+		WithConfig(Config{Slug: SlugConfig{Unicode: true}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Generate(); err != nil {
+		t.Fatal(err)
+	}
+
+	bytes, _ := os.ReadFile(filepath.Join(output, "index.html"))
+	want := "/москва.html /tags/города.html москва"
+	if string(bytes) != want {
+		t.Errorf("want %q, got %q", want, bytes)
+	}
+	if _, err := os.Stat(filepath.Join(output, "tags", "города.html")); err != nil {
+		t.Errorf("want %v, got %v", "the tag page", err)
+	}
+
+	_, err = NewStaticBlogGenerator(blog, templates, output, func(string) {},
+		WithConfig(Config{Slug: SlugConfig{Language: "xx"}}))
+	if err == nil {
+		t.Errorf("want %v, got %v", "an error", err)
+	}
+}
+
+func TestSlugger(t *testing.T) {
+	slugger := func(str string) string {
+		return strings.ToLower(strings.ReplaceAll(str, " ", "_"))
+	}
+
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, postsDir, draftDir), 0700)
+	os.WriteFile(filepath.Join(dir, postsDir, "rome.md"), []byte(
+		"# Eternal City\n\n*Jan 2, 2009*\n\n*Old Towns*\n\nRome"), 0600)
+	os.MkdirAll(filepath.Join(dir, commentsDir, "eternal_city"), 0700)
+	os.WriteFile(filepath.Join(dir, commentsDir, "eternal_city", "1.yaml"),
+		[]byte("name: Jane\ndate: 2021-08-12\nmessage: Nice\n"), 0600)
+
+	blog, err := NewMarkdownBlog(dir, WithSlugger(slugger)).Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(blog) != 1 || len(blog[0].Comments) != 1 {
+		t.Fatalf("want %v, got %v", "a post with a comment", blog)
+	}
+
+	templates := writeTestTemplates(t)
+	os.WriteFile(filepath.Join(templates, "index.tmpl"), []byte(
+		`{{define "content"}}{{range .}}{{postURL .}} {{range .Tags}}{{tagURL .}}{{end}} {{slug .Title}}{{end}}{{end}}`), 0600)
+
+	output := t.TempDir()
+	g, err := NewStaticBlogGenerator(blog, templates, output, func(string) {},
+		WithConfig(Config{Slug: SlugConfig{MaxLength: 3}}), WithSlugger(slugger))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Generate(); err != nil {
+		t.Fatal(err)
+	}
+
+	bytes, _ := os.ReadFile(filepath.Join(output, "index.html"))
+	want := "/eternal_city.html /tags/old_towns.html eternal_city"
+	if string(bytes) != want {
+		t.Errorf("want %q, got %q", want, bytes)
+	}
+}
@@ -0,0 +1,122 @@
+package lib
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"time"
+)
+
+// defaultStaleYears is the number of years after which Posts are stale if the
+// StaleConfig doesn't set it.
+const defaultStaleYears = 2
+
+// defaultStaleMarkers are the regular expressions matching version-specific
+// content if the StaleConfig doesn't set them.
+var defaultStaleMarkers = []string{
+	`(?i)\b(version|release|v)\s?\d+(\.\d+)+\b`,
+	`\b[A-Z][\w+#]* \d+\.\d+(\.\d+)?\b`,
+	`(?i)\bas of (19|20)\d\d\b`,
+	`(?i)\b(currently|at the time of writing|latest (version|release)|deprecated|beta)\b`,
+}
+
+// StaleConfig holds settings of the stale content report.
+type StaleConfig struct {
+	// Years is the number of years after which Posts that weren't updated are
+	// stale (2 if it's 0).
+	Years int `yaml:"years"`
+
+	// Markers are regular expressions matching version-specific content (for
+	// example `Go 1\.\d+`). They replace the default markers (version numbers
+	// and phrases like "currently" or "as of 2019").
+	Markers []string `yaml:"markers"`
+}
+
+// StalePost is a Post that wasn't updated for the StaleConfig's Years and
+// contains external links or version-specific content.
+type StalePost struct {
+	Post Post
+
+	// Updated is the date of the Post's last change (its Updated date or, if
+	// it's unknown, its Written date).
+	Updated time.Time
+
+	// Links are the URLs of the external links in the Post.
+	Links []string
+
+	// Markers are the texts in the Post matched by the StaleConfig's Markers,
+	// each of them once.
+	Markers []string
+}
+
+// Stale returns the Posts (without pages) not updated in the config's Years
+// before now that contain external links or version-specific content, the
+// least recently updated first. If includeDrafts == true draft Posts are also
+// included.
+func (b Blog) Stale(config StaleConfig, now time.Time, includeDrafts bool) ([]StalePost, error) {
+	years := config.Years
+	if years <= 0 {
+		years = defaultStaleYears
+	}
+
+	sources := config.Markers
+	if len(sources) == 0 {
+		sources = defaultStaleMarkers
+	}
+	var markers []*regexp.Regexp
+	for _, source := range sources {
+		marker, err := regexp.Compile(source)
+		if err != nil {
+			return nil, fmt.Errorf("invalid stale marker %s: %s", source, err)
+		}
+		markers = append(markers, marker)
+	}
+
+	cutoff := now.AddDate(-years, 0, 0)
+	var stale []StalePost
+	for _, post := range b {
+		if (post.Draft && !includeDrafts) || post.IsPage {
+			continue
+		}
+
+		updated := post.Updated
+		if updated.IsZero() {
+			updated = post.Written
+		}
+		if !updated.Before(cutoff) {
+			continue
+		}
+
+		html := string(renderPost(post))
+		s := StalePost{Post: post, Updated: updated}
+		seen := map[string]bool{}
+		for _, match := range linkRegexp.FindAllStringSubmatch(html, -1) {
+			u, err := url.Parse(match[1])
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") || seen[match[1]] {
+				continue
+			}
+			seen[match[1]] = true
+			s.Links = append(s.Links, match[1])
+		}
+
+		text := stripTags(html)
+		for _, marker := range markers {
+			for _, match := range marker.FindAllString(text, -1) {
+				if !seen[match] {
+					seen[match] = true
+					s.Markers = append(s.Markers, match)
+				}
+			}
+		}
+
+		if len(s.Links) > 0 || len(s.Markers) > 0 {
+			stale = append(stale, s)
+		}
+	}
+
+	sort.SliceStable(stale, func(i, j int) bool {
+		return stale[i].Updated.Before(stale[j].Updated)
+	})
+	return stale, nil
+}
@@ -0,0 +1,50 @@
+package lib
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestBlogStale(t *testing.T) {
+	blog := Blog{
+		{Title: "Go Modules", Written: time.Date(2019, 5, 1, 0, 0, 0, 0, time.UTC),
+			Content: "Go 1.12 currently needs [a flag](https://go.dev/ref/mod)."},
+		{Title: "Rome", Written: time.Date(2018, 5, 1, 0, 0, 0, 0, time.UTC),
+			Content: "See [the forum](https://example.com/forum)."},
+		{Title: "Updated", Written: time.Date(2018, 5, 1, 0, 0, 0, 0, time.UTC),
+			Updated: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), Content: "Go 1.21"},
+		{Title: "Timeless", Written: time.Date(2015, 5, 1, 0, 0, 0, 0, time.UTC),
+			Content: "Just [a link](/rome.html)."},
+		{Title: "Draft", Written: time.Date(2015, 5, 1, 0, 0, 0, 0, time.UTC),
+			Content: "Version 2.0", Draft: true},
+	}
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	stale, err := blog.Stale(StaleConfig{}, now, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var titles []string
+	for _, s := range stale {
+		titles = append(titles, s.Post.Title)
+	}
+	if want := []string{"Rome", "Go Modules"}; !reflect.DeepEqual(titles, want) {
+		t.Errorf("want %v, got %v", want, titles)
+	}
+	if want := []string{"Go 1.12", "currently"}; !reflect.DeepEqual(stale[1].Markers, want) {
+		t.Errorf("want %v, got %v", want, stale[1].Markers)
+	}
+	if want := []string{"https://go.dev/ref/mod"}; !reflect.DeepEqual(stale[1].Links, want) {
+		t.Errorf("want %v, got %v", want, stale[1].Links)
+	}
+
+	stale, _ = blog.Stale(StaleConfig{Years: 8, Markers: []string{`Version \d`}}, now, true)
+	if len(stale) != 1 || stale[0].Post.Title != "Draft" {
+		t.Errorf("want %v, got %v", "Draft", stale)
+	}
+
+	if _, err := blog.Stale(StaleConfig{Markers: []string{"("}}, now, false); err == nil {
+		t.Errorf("want an error, got nil")
+	}
+}
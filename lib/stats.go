@@ -0,0 +1,55 @@
+package lib
+
+import "sort"
+
+// longestPostsCount is the number of Posts included in Stats.LongestPosts.
+const longestPostsCount = 5
+
+// Stats holds statistics of a Blog.
+type Stats struct {
+	Posts int
+	Words int
+
+	// AverageWords is the average number of words per Post.
+	AverageWords int
+	PostsByYear  map[int]int
+	PostsByTag   map[string]int
+
+	// LongestPosts are the Posts with the most words in descending order.
+	LongestPosts []Post
+}
+
+// Stats returns statistics of the Blog. If includeDrafts == true draft Posts
+// are also included.
+func (b Blog) Stats(includeDrafts bool) Stats {
+	stats := Stats{PostsByYear: map[int]int{}, PostsByTag: map[string]int{}}
+
+	var posts []Post
+	for _, post := range b {
+		if post.Draft && !includeDrafts {
+			continue
+		}
+		posts = append(posts, post)
+
+		stats.Words += post.WordCount()
+		stats.PostsByYear[post.Written.Year()]++
+		for _, tag := range post.Tags {
+			stats.PostsByTag[tag]++
+		}
+	}
+
+	stats.Posts = len(posts)
+	if stats.Posts > 0 {
+		stats.AverageWords = stats.Words / stats.Posts
+	}
+
+	sort.SliceStable(posts, func(i, j int) bool {
+		return posts[i].WordCount() > posts[j].WordCount()
+	})
+	if len(posts) > longestPostsCount {
+		posts = posts[:longestPostsCount]
+	}
+	stats.LongestPosts = posts
+
+	return stats
+}
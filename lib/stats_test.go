@@ -0,0 +1,74 @@
+package lib
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestStats(t *testing.T) {
+	blog := Blog{
+		{Title: "Rome", Content: "Rome is the capital of Italy.", Tags: []string{"cities", "italy"},
+			Written: time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC)},
+		{Title: "Paris", Content: "Paris", Tags: []string{"cities"},
+			Written: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)},
+		{Title: "Oslo", Content: "Oslo is in Norway.", Tags: []string{"cities"},
+			Written: time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC), Draft: true},
+		{Title: "Bern", Content: "<p>Bern is <em>in</em> Switzerland.</p>", Format: FormatHTML,
+			Written: time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	tests := []struct {
+		name          string
+		blog          Blog
+		includeDrafts bool
+		want          Stats
+		longest       []string
+	}{
+		{"empty", Blog{}, true,
+			Stats{PostsByYear: map[int]int{}, PostsByTag: map[string]int{}}, nil},
+		{"without drafts", blog, false,
+			Stats{Posts: 3, Words: 11, AverageWords: 3,
+				PostsByYear: map[int]int{2023: 1, 2024: 2},
+				PostsByTag:  map[string]int{"cities": 2, "italy": 1}},
+			[]string{"Rome", "Bern", "Paris"}},
+		{"with drafts", blog, true,
+			Stats{Posts: 4, Words: 15, AverageWords: 3,
+				PostsByYear: map[int]int{2023: 1, 2024: 3},
+				PostsByTag:  map[string]int{"cities": 3, "italy": 1}},
+			[]string{"Rome", "Oslo", "Bern", "Paris"}},
+	}
+
+	for _, test := range tests {
+		stats := test.blog.Stats(test.includeDrafts)
+
+		var longest []string
+		for _, post := range stats.LongestPosts {
+			longest = append(longest, post.Title)
+		}
+		if !reflect.DeepEqual(longest, test.longest) {
+			t.Errorf("%s: want %v, got %v", test.name, test.longest, longest)
+		}
+
+		stats.LongestPosts = nil
+		if !reflect.DeepEqual(stats, test.want) {
+			t.Errorf("%s: want %v, got %v", test.name, test.want, stats)
+		}
+	}
+}
+
+func TestStatsLongestPosts(t *testing.T) {
+	var blog Blog
+	for _, content := range []string{"one", "one two", "one two three", "one two three four",
+		"one two three four five", "one two three four five six"} {
+		blog = append(blog, Post{Title: content, Content: content})
+	}
+
+	stats := blog.Stats(false)
+	if len(stats.LongestPosts) != longestPostsCount {
+		t.Errorf("want %v, got %v", longestPostsCount, len(stats.LongestPosts))
+	}
+	if want := "one two three four five six"; stats.LongestPosts[0].Title != want {
+		t.Errorf("want %v, got %v", want, stats.LongestPosts[0].Title)
+	}
+}
@@ -0,0 +1,175 @@
+package lib
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// PostStore stores source files of Posts (for example Markdown files). Names
+// of the files are paths relative to the posts directory using forward
+// slashes. Files of draft Posts are stored in the draft directory.
+type PostStore interface {
+	// List returns all files in the store.
+	List() ([]StoredFile, error)
+
+	// Read returns the content of the file with the name.
+	Read(name string) ([]byte, error)
+
+	// Write creates or replaces the file with the name.
+	Write(name string, data []byte) error
+
+	// Remove removes the file with the name. Removing a file that doesn't
+	// exist isn't an error.
+	Remove(name string) error
+}
+
+// StoredFile is a file in a PostStore.
+type StoredFile struct {
+	Name     string
+	Modified time.Time
+}
+
+// DirStore is a PostStore storing files in a directory (for example the posts
+// directory of a MarkdownBlog).
+type DirStore struct {
+	dir string
+}
+
+// NewDirStore creates a DirStore in the directory.
+func NewDirStore(dir string) DirStore {
+	return DirStore{dir}
+}
+
+// List returns all files in the directory and its subdirectories.
+func (s DirStore) List() ([]StoredFile, error) {
+	var files []StoredFile
+	err := filepath.WalkDir(s.dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(s.dir, p)
+		if err != nil {
+			return err
+		}
+		files = append(files, StoredFile{filepath.ToSlash(rel), info.ModTime()})
+		return nil
+	})
+	return files, err
+}
+
+// Read returns the content of the file.
+func (s DirStore) Read(name string) ([]byte, error) {
+	p, err := s.path(name)
+	if err != nil {
+		return nil, err
+	}
+	return os.ReadFile(p)
+}
+
+// Write creates or replaces the file (and its parent directories).
+func (s DirStore) Write(name string, data []byte) error {
+	p, err := s.path(name)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(p), 0700)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(p, data, 0600)
+}
+
+// Remove removes the file.
+func (s DirStore) Remove(name string) error {
+	p, err := s.path(name)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(p)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+func (s DirStore) path(name string) (string, error) {
+	clean := path.Clean("/" + name)
+	if clean == "/" {
+		return "", fmt.Errorf("invalid file name: %s", name)
+	}
+	return filepath.Join(s.dir, filepath.FromSlash(clean)), nil
+}
+
+// StoreBlog is a Blog stored in a PostStore. The files are parsed like the
+// ones of a MarkdownBlog (except includes and comments that need a Blog
+// directory).
+type StoreBlog struct {
+	store   PostStore
+	options options
+}
+
+// NewStoreBlog creates a StoreBlog in the PostStore.
+func NewStoreBlog(store PostStore, opts ...Option) StoreBlog {
+	return StoreBlog{store, newOptions(opts)}
+}
+
+// Read creates a Blog from the files in the PostStore. Files (and
+// directories) starting with a dot are ignored.
+func (b StoreBlog) Read() (Blog, error) {
+	loc, err := b.options.config.location()
+	if err != nil {
+		return Blog{}, err
+	}
+
+	files, err := b.store.List()
+	if err != nil {
+		return Blog{}, fmt.Errorf("failed to list posts: %s", err)
+	}
+
+	parser := MarkdownBlog{options: b.options}
+	var blog Blog
+	for _, file := range files {
+		if strings.HasPrefix(file.Name, ".") || strings.Contains(file.Name, "/.") {
+			continue
+		}
+
+		source, err := b.store.Read(file.Name)
+		if err != nil {
+			return Blog{}, fmt.Errorf("failed to read post: %s", err)
+		}
+
+		post, err := parser.parsePost(string(source), path.Ext(file.Name), loc)
+		if err != nil {
+			return Blog{}, fmt.Errorf("failed to parse post %s: %s", file.Name, err)
+		}
+
+		rel := file.Name
+		if strings.HasPrefix(rel, draftDir+"/") {
+			post.Draft = true
+			rel = strings.TrimPrefix(rel, draftDir+"/")
+		}
+		if dir := path.Dir(rel); dir != "." {
+			post.Dir = dir
+		}
+		if post.Updated.IsZero() {
+			post.Updated = file.Modified
+		}
+
+		blog = append(blog, post)
+	}
+	return blog, nil
+}
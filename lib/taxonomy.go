@@ -0,0 +1,88 @@
+package lib
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+)
+
+// TaxonomiesConfig holds settings of the term pages of the Blog's taxonomies:
+// tags and authors.
+type TaxonomiesConfig struct {
+	Tags    TaxonomyConfig `yaml:"tags"`
+	Authors TaxonomyConfig `yaml:"authors"`
+}
+
+// TaxonomyConfig holds settings of the feeds and the sitemap entries of the
+// term pages (like tag pages) of a taxonomy.
+type TaxonomyConfig struct {
+	// Feeds tells whether an Atom feed of the Posts of each term is generated
+	// (for tags it's also generated if Config.TagFeeds is set).
+	Feeds bool `yaml:"feeds"`
+
+	// Sitemap tells whether the term pages are in the sitemap. If it's not
+	// set, tag pages are and author pages aren't.
+	Sitemap *bool `yaml:"sitemap"`
+
+	// Exclude are the terms (case-insensitive) without feeds whose pages
+	// aren't in the sitemap, for example internal tags.
+	Exclude []string `yaml:"exclude"`
+}
+
+// feed tells whether the term has a feed.
+func (c TaxonomyConfig) feed(term string) bool {
+	return c.Feeds && !containsFold(c.Exclude, term)
+}
+
+// sitemap tells whether the term's page is in the sitemap. The def value is
+// used if the TaxonomyConfig's Sitemap isn't set.
+func (c TaxonomyConfig) sitemap(term string, def bool) bool {
+	if c.Sitemap != nil {
+		def = *c.Sitemap
+	}
+	return def && !containsFold(c.Exclude, term)
+}
+
+// tagTaxonomy returns the settings of the tag taxonomy with its Feeds set if
+// Config.TagFeeds is set.
+func (c Config) tagTaxonomy() TaxonomyConfig {
+	tags := c.Taxonomies.Tags
+	tags.Feeds = tags.Feeds || c.TagFeeds
+	return tags
+}
+
+// authorFeedPath returns the path of the feed of the Posts written by the
+// author in the output directory.
+func (g StaticBlogGenerator) authorFeedPath(author string) string {
+	return filepath.Join("authors", g.slug(author)+".xml")
+}
+
+// authorFeedURL returns the URL of the feed of the Posts written by the
+// author relative to the Blog's root.
+func (g StaticBlogGenerator) authorFeedURL(author string) string {
+	return "/" + filepath.ToSlash(g.authorFeedPath(author))
+}
+
+// generateAuthorFeeds generates a feed of the Posts written by each author
+// (see TaxonomyConfig.Feeds).
+func (g StaticBlogGenerator) generateAuthorFeeds() error {
+	for _, author := range g.authors {
+		if !g.options.config.Taxonomies.Authors.feed(author.Name) {
+			continue
+		}
+
+		name := author.Name
+		config := g.options.config.Feeds.Authors
+		posts := g.feedPosts(config, true, func(post Post) bool {
+			return slices.Contains(post.Authors, name)
+		})
+
+		path := g.authorFeedPath(author.Name)
+		title := fmt.Sprintf("%s: %s", g.options.config.Title, author.Name)
+		err := g.generateAtomFeed(path, g.absURL(path), title, posts, config)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package lib
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGenerateTaxonomies(t *testing.T) {
+	templates := writeTestTemplates(t)
+	os.WriteFile(filepath.Join(templates, "author.tmpl"),
+		[]byte(`{{define "content"}}{{headLinks .}}{{end}}`), 0600)
+	blog := Blog{
+		{Title: "Rome", Tags: []string{"cities", "Status"}, Authors: []string{"Jane"},
+			Written: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	output := t.TempDir()
+	sitemap := true
+	g, err := NewStaticBlogGenerator(blog, templates, output, func(string) {},
+		WithConfig(Config{URL: "https://example.com", Title: "Cities", TagFeeds: true,
+			Taxonomies: TaxonomiesConfig{
+				Tags:    TaxonomyConfig{Exclude: []string{"status"}},
+				Authors: TaxonomyConfig{Feeds: true, Sitemap: &sitemap},
+			}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Generate(); err != nil {
+		t.Fatal(err)
+	}
+
+	for path, want := range map[string]bool{
+		"tags/cities.xml":  true,
+		"tags/status.xml":  false,
+		"authors/jane.xml": true,
+	} {
+		if _, err := os.Stat(filepath.Join(output, path)); (err == nil) != want {
+			t.Errorf("%s: want %v, got %v", path, want, err == nil)
+		}
+	}
+
+	bytes, _ := os.ReadFile(filepath.Join(output, "sitemap.xml"))
+	for loc, want := range map[string]bool{
+		"https://example.com/tags/cities.html":  true,
+		"https://example.com/tags/status.html":  false,
+		"https://example.com/authors/jane.html": true,
+	} {
+		if strings.Contains(string(bytes), "<loc>"+loc+"</loc>") != want {
+			t.Errorf("%s: want %v in the sitemap, got %s", loc, want, bytes)
+		}
+	}
+
+	author, _ := os.ReadFile(filepath.Join(output, "authors", "jane.html"))
+	if want := `title="Cities: Jane" href="/authors/jane.xml"`; !strings.Contains(string(author), want) {
+		t.Errorf("want %s, got %s", want, author)
+	}
+}
@@ -0,0 +1,64 @@
+package lib
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+)
+
+// ThemeFile is the name of the optional file in the templates directory that
+// declares the parameters of the theme (the templates) with their defaults.
+const ThemeFile = "theme.yaml"
+
+// ThemeConfig holds the settings of a theme declared in its ThemeFile, which
+// looks like this:
+//
+//	params:
+//	  accentColor: "#336699"
+//	  columns: 2
+//	  showAuthor: true
+type ThemeConfig struct {
+	// Params holds the defaults of the theme's parameters keyed by their
+	// names. Blogs using the theme can override them with Config.Theme.
+	Params map[string]interface{} `yaml:"params"`
+}
+
+// ReadThemeConfig reads the ThemeConfig from the ThemeFile in the templates
+// directory. If the file doesn't exist it returns the zero ThemeConfig.
+func ReadThemeConfig(templatesDir string) (ThemeConfig, error) {
+	var theme ThemeConfig
+	err := readYAML(filepath.Join(templatesDir, ThemeFile), &theme)
+	if err != nil {
+		return theme, fmt.Errorf("failed to read theme: %s", err)
+	}
+	return theme, nil
+}
+
+// themeParams returns the parameters of the theme in the templates directory
+// with the values of the Config's Theme overriding the defaults. Parameters
+// the theme doesn't declare are left out and reported as warnings.
+func (g StaticBlogGenerator) themeParams() (map[string]interface{}, error) {
+	theme, err := ReadThemeConfig(g.templatesDir)
+	if err != nil {
+		return nil, err
+	}
+
+	params := map[string]interface{}{}
+	for name, value := range theme.Params {
+		params[name] = value
+	}
+
+	var names []string
+	for name := range g.options.config.Theme {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if _, ok := theme.Params[name]; !ok {
+			g.options.warn(fmt.Sprintf("theme has no parameter %s", name))
+			continue
+		}
+		params[name] = g.options.config.Theme[name]
+	}
+	return params, nil
+}
@@ -0,0 +1,61 @@
+package lib
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGenerateThemeParams(t *testing.T) {
+	templates := writeTestTemplates(t)
+	os.WriteFile(filepath.Join(templates, ThemeFile),
+		[]byte("params:\n  accentColor: \"#336699\"\n  columns: 2\n  showAuthor: true\n"), 0600)
+	os.WriteFile(filepath.Join(templates, "post.tmpl"), []byte(`{{define "content"}}`+
+		`{{site.Theme.accentColor}};{{site.Theme.columns}};{{if site.Theme.showAuthor}}Jane{{end}}`+
+		`{{end}}`), 0600)
+	blog := Blog{{Title: "Rome", Written: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)}}
+
+	var warnings []string
+	output := t.TempDir()
+	g, err := NewStaticBlogGenerator(blog, templates, output, func(string) {},
+		WithConfig(Config{Theme: map[string]interface{}{"accentColor": "#cc3300",
+			"showAuthor": false, "font": "serif"}}),
+		WithWarnFunc(func(message string) { warnings = append(warnings, message) }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Generate(); err != nil {
+		t.Fatal(err)
+	}
+
+	bytes, _ := os.ReadFile(filepath.Join(output, "rome.html"))
+	if want := "#cc3300;2;"; string(bytes) != want {
+		t.Errorf("want %q, got %q", want, bytes)
+	}
+	if want := "theme has no parameter font"; strings.Join(warnings, "|") != want {
+		t.Errorf("want %v, got %v", want, warnings)
+	}
+	if _, err := os.Stat(filepath.Join(output, ThemeFile)); err == nil {
+		t.Errorf("want %s not copied, got it in the output directory", ThemeFile)
+	}
+}
+
+func TestGenerateStrictThemeParams(t *testing.T) {
+	templates := writeTestTemplates(t)
+	os.WriteFile(filepath.Join(templates, ThemeFile), []byte("params:\n  columns: 2\n"), 0600)
+	os.WriteFile(filepath.Join(templates, "post.tmpl"),
+		[]byte(`{{define "content"}}{{site.Theme.colums}}{{end}}`), 0600)
+	blog := Blog{{Title: "Rome", Written: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)}}
+
+	g, err := NewStaticBlogGenerator(blog, templates, t.TempDir(), func(string) {},
+		WithConfig(Config{StrictTemplates: true}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = g.Generate()
+	if err == nil || !strings.Contains(err.Error(), `post.tmpl:1:22: executing "content"`) {
+		t.Errorf("want missing key error, got %v", err)
+	}
+}
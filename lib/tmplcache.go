@@ -0,0 +1,91 @@
+package lib
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"html/template"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// maxCachedTemplates is the number of page templates after which the
+// templateCache is emptied (so editing templates in watch mode doesn't grow
+// it forever).
+const maxCachedTemplates = 256
+
+// parsedTemplates caches templates parsed by all StaticBlogGenerators of the
+// process, so rebuilding a Blog (for example in watch mode) parses only the
+// changed templates.
+var parsedTemplates = &templateCache{layouts: map[string]*template.Template{},
+	pages: map[string]*template.Template{}}
+
+// templateCache holds parsed layouts and page templates (parsed with a copy of
+// their layout) keyed by hashes of their sources and names of the template
+// functions they were parsed with. The cached templates are never executed,
+// only their copies.
+type templateCache struct {
+	mu      sync.Mutex
+	layouts map[string]*template.Template
+	pages   map[string]*template.Template
+}
+
+// parse returns a copy (using the funcs) of the page template with the name
+// parsed with the layout.
+func (c *templateCache) parse(layout, page []byte, name string,
+	funcs template.FuncMap) (*template.Template, error) {
+	names := make([]string, 0, len(funcs))
+	for name := range funcs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	layoutKey := templateKey(strings.Join(names, ","), string(layout))
+	pageKey := templateKey(layoutKey, name, string(page))
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	parsed, ok := c.pages[pageKey]
+	if !ok {
+		base, ok := c.layouts[layoutKey]
+		if !ok {
+			var err error
+			base, err = template.New("layout.tmpl").Funcs(funcs).Parse(string(layout))
+			if err != nil {
+				return nil, err
+			}
+			c.layouts[layoutKey] = base
+		}
+
+		clone, err := base.Clone()
+		if err != nil {
+			return nil, err
+		}
+		_, err = clone.New(name).Parse(string(page))
+		if err != nil {
+			return nil, err
+		}
+
+		if len(c.pages) >= maxCachedTemplates {
+			c.layouts = map[string]*template.Template{layoutKey: base}
+			c.pages = map[string]*template.Template{}
+		}
+		c.pages[pageKey] = clone
+		parsed = clone
+	}
+
+	clone, err := parsed.Clone()
+	if err != nil {
+		return nil, err
+	}
+	return clone.Funcs(funcs), nil
+}
+
+func templateKey(parts ...string) string {
+	h := sha256.New()
+	for _, part := range parts {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,43 @@
+package lib
+
+import (
+	"html/template"
+	"strings"
+	"testing"
+)
+
+func TestTemplateCache(t *testing.T) {
+	c := &templateCache{layouts: map[string]*template.Template{},
+		pages: map[string]*template.Template{}}
+	layout := []byte(`<main>{{template "content" .}}</main>`)
+	execute := func(page, greeting string) string {
+		funcs := template.FuncMap{"greeting": func() string { return greeting }}
+		parsed, err := c.parse(layout, []byte(page), "post.tmpl", funcs)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var b strings.Builder
+		if err := parsed.Execute(&b, "Rome"); err != nil {
+			t.Fatal(err)
+		}
+		return b.String()
+	}
+
+	post := `{{define "content"}}{{greeting}}, {{.}}{{end}}`
+	for _, greeting := range []string{"Hello", "Ciao"} {
+		if got, want := execute(post, greeting), "<main>"+greeting+", Rome</main>"; got != want {
+			t.Errorf("want %q, got %q", want, got)
+		}
+	}
+	if len(c.layouts) != 1 || len(c.pages) != 1 {
+		t.Errorf("want %v, got %v", "1 layout and 1 page", c.pages)
+	}
+
+	if got, want := execute(`{{define "content"}}{{.}}!{{end}}`, ""), "<main>Rome!</main>"; got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+	if len(c.layouts) != 1 || len(c.pages) != 2 {
+		t.Errorf("want %v, got %v", "1 layout and 2 pages", c.pages)
+	}
+}
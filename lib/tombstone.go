@@ -0,0 +1,20 @@
+package lib
+
+import "html/template"
+
+// tombstoneTemplate generates pages of expired Posts if the expired.tmpl
+// template doesn't exist.
+var tombstoneTemplate = template.Must(template.New("tombstone").Parse(`<!DOCTYPE html>
+<html>
+  <head>
+    <meta charset="utf-8">
+    <meta name="robots" content="noindex">
+    <title>{{.Title}}</title>
+  </head>
+  <body>
+    <h1>{{.Title}}</h1>
+    <p>This post expired and is no longer available.</p>
+    <p><a href="/">Home</a></p>
+  </body>
+</html>
+`))
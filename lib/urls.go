@@ -0,0 +1,111 @@
+package lib
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// URL styles of generated pages (see Config.URLStyle).
+const (
+	// URLStyleHTML links pages with the .html extension (for example
+	// /rome.html).
+	URLStyleHTML = "html"
+
+	// URLStyleSlash generates pages to index.html files of directories linked
+	// with a trailing slash (for example /rome/ for rome/index.html).
+	URLStyleSlash = "slash"
+
+	// URLStyleClean links pages without the .html extension (for example
+	// /rome for rome.html), for hosts serving such URLs.
+	URLStyleClean = "clean"
+)
+
+// urlStyle returns the URLStyle (URLStyleHTML if it's empty).
+func (c Config) urlStyle() (string, error) {
+	switch c.URLStyle {
+	case "":
+		return URLStyleHTML, nil
+	case URLStyleHTML, URLStyleSlash, URLStyleClean:
+		return c.URLStyle, nil
+	}
+	return "", fmt.Errorf("unsupported urlStyle: %s", c.URLStyle)
+}
+
+// pagePath returns the path of the page with the name (a path without an
+// extension using forward slashes, for example tags/go) in the output
+// directory.
+func (g StaticBlogGenerator) pagePath(name string) string {
+	if g.options.config.URLStyle == URLStyleSlash {
+		return filepath.Join(filepath.FromSlash(name), "index.html")
+	}
+	return filepath.FromSlash(name) + ".html"
+}
+
+// pageURL returns the URL (relative to the Blog's root) of the page with the
+// path in the output directory.
+func (g StaticBlogGenerator) pageURL(p string) string {
+	url := "/" + filepath.ToSlash(p)
+	if g.options.config.URLStyle != URLStyleSlash && g.options.config.URLStyle != URLStyleClean {
+		return url
+	}
+
+	if path.Base(url) == "index.html" {
+		return strings.TrimSuffix(url, "index.html")
+	}
+	if g.options.config.URLStyle == URLStyleClean {
+		return strings.TrimSuffix(url, ".html")
+	}
+	return url
+}
+
+// absPageURL returns the absolute URL of the page with the path in the output
+// directory.
+func (g StaticBlogGenerator) absPageURL(p string) string {
+	return g.absURL(strings.TrimPrefix(g.pageURL(p), "/"))
+}
+
+// basePath returns the BasePath starting with a slash and without the
+// trailing one (or an empty string if the Blog is hosted at the root).
+func (c Config) basePath() string {
+	p := strings.Trim(c.BasePath, "/")
+	if p == "" {
+		return ""
+	}
+	return "/" + p
+}
+
+// siteURL returns the URL ending with the BasePath.
+func (c Config) siteURL() string {
+	// the URL ends with the BasePath only if it ends with all of its path
+	// segments (https://example.com/myblog doesn't end with /blog)
+	url, base := strings.TrimSuffix(c.URL, "/"), c.basePath()
+	if url == "" || base == "" || strings.HasSuffix(url, "/"+strings.Trim(base, "/")) {
+		return c.URL
+	}
+	return url + base
+}
+
+// withBasePath returns the URL prefixed with the BasePath if it's relative to
+// the Blog's root. URLs are expected to be prefixed only once, where they're
+// generated (or by prefixOutput), so a URL starting with the BasePath is
+// prefixed, too (for example /blog/rome.html of a Post in the blog
+// directory).
+func (c Config) withBasePath(url string) string {
+	base := c.basePath()
+	if base == "" || !strings.HasPrefix(url, "/") || strings.HasPrefix(url, "//") {
+		return url
+	}
+	return base + url
+}
+
+// trimBasePath returns the URL (relative to the host's root) without the
+// BasePath.
+func (c Config) trimBasePath(url string) string {
+	base := c.basePath()
+	if base == "" || (url != base && !strings.HasPrefix(url, base+"/")) {
+		return url
+	}
+	return "/" + strings.TrimPrefix(strings.TrimPrefix(url, base), "/")
+}
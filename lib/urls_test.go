@@ -0,0 +1,99 @@
+package lib
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestURLStyles(t *testing.T) {
+	templates := writeTestTemplates(t)
+	os.WriteFile(filepath.Join(templates, "index.tmpl"), []byte(
+		`{{define "content"}}{{range .}}{{postURL .}} {{range .Tags}}{{tagURL .}}{{end}}{{end}}{{end}}`), 0600)
+	blog := Blog{{Title: "Rome", Tags: []string{"cities"}, Written: time.Now()}}
+
+	for style, want := range map[string][]string{
+		"":            {"/rome.html /tags/cities.html", "rome.html", "https://example.com/rome.html"},
+		URLStyleSlash: {"/rome/ /tags/cities/", "rome/index.html", "https://example.com/rome/"},
+		URLStyleClean: {"/rome /tags/cities", "rome.html", "https://example.com/rome<"},
+	} {
+		output := t.TempDir()
+		g, err := NewStaticBlogGenerator(blog, templates, output, func(string) {},
+			WithConfig(Config{URL: "https://example.com", URLStyle: style, CheckLinks: true}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := g.Generate(); err != nil {
+			t.Fatal(err)
+		}
+
+		bytes, _ := os.ReadFile(filepath.Join(output, "index.html"))
+		if string(bytes) != want[0] {
+			t.Errorf("%s: want %q, got %q", style, want[0], bytes)
+		}
+		if _, err := os.Stat(filepath.Join(output, want[1])); err != nil {
+			t.Errorf("%s: want %v, got %v", style, want[1], err)
+		}
+		bytes, _ = os.ReadFile(filepath.Join(output, "sitemap.xml"))
+		if !strings.Contains(string(bytes), want[2]) {
+			t.Errorf("%s: want %q, got %q", style, want[2], bytes)
+		}
+	}
+
+	if _, err := NewStaticBlogGenerator(blog, templates, t.TempDir(), func(string) {},
+		WithConfig(Config{URLStyle: "php"})); err == nil {
+		t.Errorf("want %v, got %v", "an error", err)
+	}
+}
+
+func TestBasePath(t *testing.T) {
+	templates := writeTestTemplates(t)
+	os.WriteFile(filepath.Join(templates, "index.tmpl"), []byte(
+		`{{define "content"}}<link href="/style.css">{{range .}}<a href="{{postURL .}}">{{.Title}}</a>{{end}}{{end}}`), 0600)
+	os.WriteFile(filepath.Join(templates, "style.css"), nil, 0600)
+	blog := Blog{{Title: "Rome", Written: time.Now()}}
+
+	output := t.TempDir()
+	g, err := NewStaticBlogGenerator(blog, templates, output, func(string) {},
+		WithConfig(Config{URL: "https://example.com", BasePath: "blog/",
+			Redirects: map[string]string{"/old.html": "/rome.html"}, RedirectFiles: []string{RedirectsNetlify},
+			CheckLinks: true, BrokenLinks: BrokenLinksError}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Generate(); err != nil {
+		t.Fatal(err)
+	}
+
+	for file, want := range map[string]string{
+		"index.html":  `<link href="/blog/style.css"><a href="/blog/rome.html">Rome</a>`,
+		"old.html":    `url=/blog/rome.html`,
+		"_redirects":  `/blog/old.html /blog/rome.html 301`,
+		"sitemap.xml": `<loc>https://example.com/blog/rome.html</loc>`,
+	} {
+		bytes, _ := os.ReadFile(filepath.Join(output, file))
+		if !strings.Contains(string(bytes), want) {
+			t.Errorf("want %q, got %q", want, bytes)
+		}
+	}
+
+	c := Config{URL: "https://example.com/blog/", BasePath: "/blog"}
+	if got, want := c.siteURL(), "https://example.com/blog/"; got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+	for url, want := range map[string]string{
+		"https://example.com/myblog":  "https://example.com/myblog/blog",
+		"https://example.com/myblog/": "https://example.com/myblog/blog",
+		"https://example.com/a/blog":  "https://example.com/a/blog",
+	} {
+		c := Config{URL: url, BasePath: "/blog/"}
+		if got := c.siteURL(); got != want {
+			t.Errorf("%s: want %q, got %q", url, want, got)
+		}
+	}
+	if got, want := c.withBasePath("/blog/rome.html"), "/blog/blog/rome.html"; got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+}
@@ -0,0 +1,164 @@
+package lib
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	nethtml "golang.org/x/net/html"
+)
+
+// voidElements are the HTML elements without end tags.
+var voidElements = map[string]bool{"area": true, "base": true, "br": true,
+	"col": true, "embed": true, "hr": true, "img": true, "input": true,
+	"link": true, "meta": true, "param": true, "source": true, "track": true,
+	"wbr": true}
+
+// optionalEndElements are the HTML elements whose end tags can be omitted.
+var optionalEndElements = map[string]bool{"html": true, "head": true,
+	"body": true, "p": true, "li": true, "dt": true, "dd": true, "option": true,
+	"optgroup": true, "colgroup": true, "thead": true, "tbody": true,
+	"tfoot": true, "tr": true, "td": true, "th": true, "rt": true, "rp": true}
+
+// inCI tells whether the build runs in a continuous integration service
+// (they set the CI environment variable).
+func inCI() bool {
+	ci := os.Getenv("CI")
+	return ci != "" && ci != "false" && ci != "0"
+}
+
+// validateOutput checks that the generated HTML files are well-formed
+// (elements are closed in the right order and IDs are unique). The errors
+// are reported as warnings or, in continuous integration (see inCI), fail
+// the build.
+func (g StaticBlogGenerator) validateOutput() error {
+	titles := map[string]string{}
+	for _, post := range append(g.posts[:len(g.posts):len(g.posts)], g.unlisted...) {
+		titles[filepath.ToSlash(g.postPath(post))] = post.Title
+	}
+
+	invalid := 0
+	err := filepath.WalkDir(g.outputDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || filepath.Ext(p) != ".html" {
+			return err
+		}
+
+		file, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		rel, err := filepath.Rel(g.outputDir, p)
+		if err != nil {
+			return err
+		}
+		page := filepath.ToSlash(rel)
+
+		source := page
+		if title, ok := titles[page]; ok {
+			source = fmt.Sprintf("%s (%s)", page, title)
+		}
+
+		issues, err := validateHTML(file)
+		if err != nil {
+			return err
+		}
+		for _, issue := range issues {
+			g.options.warn(fmt.Sprintf("invalid HTML in %s on line %d: %s",
+				source, issue.line, issue.message))
+		}
+		if len(issues) > 0 {
+			invalid++
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if invalid > 0 && inCI() {
+		return fmt.Errorf("found %d invalid page(s)", invalid)
+	}
+	return nil
+}
+
+// validateHTML returns the structural errors of the HTML page: end tags
+// without start tags, elements that aren't closed (unless their end tags are
+// optional) and duplicate IDs.
+func validateHTML(r io.Reader) ([]auditIssue, error) {
+	var issues []auditIssue
+	line := 1
+	ids := map[string]int{}
+
+	type element struct {
+		name string
+		line int
+	}
+	var open []element
+
+	z := nethtml.NewTokenizer(r)
+	for {
+		tt := z.Next()
+		if tt == nethtml.ErrorToken {
+			if z.Err() != io.EOF {
+				return nil, z.Err()
+			}
+			break
+		}
+		tokenLine := line
+		line += strings.Count(string(z.Raw()), "\n")
+		token := z.Token()
+
+		switch tt {
+		case nethtml.StartTagToken, nethtml.SelfClosingTagToken:
+			for _, attr := range token.Attr {
+				if attr.Key != "id" {
+					continue
+				}
+				if first, ok := ids[attr.Val]; ok {
+					issues = append(issues, auditIssue{tokenLine,
+						fmt.Sprintf("duplicate id %q (first on line %d)", attr.Val, first)})
+				} else {
+					ids[attr.Val] = tokenLine
+				}
+			}
+
+			if tt == nethtml.StartTagToken && !voidElements[token.Data] {
+				open = append(open, element{token.Data, tokenLine})
+			}
+		case nethtml.EndTagToken:
+			if voidElements[token.Data] {
+				continue
+			}
+
+			i := len(open) - 1
+			for i >= 0 && open[i].name != token.Data {
+				i--
+			}
+			if i < 0 {
+				issues = append(issues, auditIssue{tokenLine,
+					fmt.Sprintf("unexpected </%s>", token.Data)})
+				continue
+			}
+
+			for _, e := range open[i+1:] {
+				if !optionalEndElements[e.name] {
+					issues = append(issues, auditIssue{e.line,
+						fmt.Sprintf("<%s> not closed before </%s>", e.name, token.Data)})
+				}
+			}
+			open = open[:i]
+		}
+	}
+
+	for _, e := range open {
+		if !optionalEndElements[e.name] {
+			issues = append(issues, auditIssue{e.line, fmt.Sprintf("unclosed <%s>", e.name)})
+		}
+	}
+	return issues, nil
+}
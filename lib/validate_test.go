@@ -0,0 +1,68 @@
+package lib
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidateHTML(t *testing.T) {
+	issues, err := validateHTML(strings.NewReader(`<!DOCTYPE html>
+<html lang="en">
+<body>
+<ul><li>Rome<li>Milan</ul>
+<p>The <b>Colosseum<br></p>
+<div id="map"></div></span>
+<div id="map">
+</body>
+</html>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []auditIssue{
+		{5, "<b> not closed before </p>"},
+		{6, "unexpected </span>"},
+		{7, `duplicate id "map" (first on line 6)`},
+		{7, "<div> not closed before </body>"},
+	}
+	if !reflect.DeepEqual(issues, want) {
+		t.Errorf("want %v, got %v", want, issues)
+	}
+
+	issues, _ = validateHTML(strings.NewReader(`<html><p>Rome<img src="/rome.jpg"><svg><path d="M0"/></svg></html>`))
+	if len(issues) != 0 {
+		t.Errorf("want %v, got %v", "no issues", issues)
+	}
+}
+
+func TestValidateOutput(t *testing.T) {
+	templates := writeTestTemplates(t)
+	os.WriteFile(filepath.Join(templates, "post.tmpl"), []byte(`{{define "content"}}{{. | html}}{{end}}`), 0600)
+	blog := Blog{{Title: "Rome", Written: time.Now(), Content: "<div>Colosseum"}}
+
+	var warnings []string
+	g, err := NewStaticBlogGenerator(blog, templates, t.TempDir(), func(string) {},
+		WithConfig(Config{ValidateHTML: true}),
+		WithWarnFunc(func(message string) { warnings = append(warnings, message) }))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("CI", "")
+	if err := g.Generate(); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"invalid HTML in rome.html (Rome) on line 1: <div> not closed before </p>"}
+	if !reflect.DeepEqual(warnings, want) {
+		t.Errorf("want %v, got %v", want, warnings)
+	}
+
+	t.Setenv("CI", "true")
+	if err := g.Generate(); err == nil {
+		t.Errorf("want %v, got %v", "an error", err)
+	}
+}
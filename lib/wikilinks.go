@@ -0,0 +1,81 @@
+package lib
+
+import (
+	"fmt"
+	stdhtml "html"
+	"regexp"
+	"strings"
+)
+
+// Ways of handling wiki links to Posts that don't exist.
+const (
+	// BrokenLinksError fails the generation.
+	BrokenLinksError = "error"
+
+	// BrokenLinksWarn reports a warning and renders the link as plain text.
+	BrokenLinksWarn = "warn"
+)
+
+// brokenLinks returns the BrokenLinks mode (BrokenLinksError if it's empty).
+func (c Config) brokenLinks() (string, error) {
+	switch c.BrokenLinks {
+	case "":
+		return BrokenLinksError, nil
+	case BrokenLinksError, BrokenLinksWarn:
+		return c.BrokenLinks, nil
+	}
+	return "", fmt.Errorf("unsupported brokenLinks mode: %s", c.BrokenLinks)
+}
+
+// wikiLinkRegexp matches [[Post Title]] and [[Post Title|label]].
+var wikiLinkRegexp = regexp.MustCompile(`\[\[([^\[\]|]+)(?:\|([^\[\]]+))?\]\]`)
+
+// resolveWikiLinks replaces wiki links in the Posts' Content with links to
+// the targets (the Posts whose pages are generated) with the linked titles
+// (compared case insensitively).
+func (g StaticBlogGenerator) resolveWikiLinks(posts, targets []Post) ([]Post, error) {
+	mode, err := g.options.config.brokenLinks()
+	if err != nil {
+		return nil, err
+	}
+
+	urls := map[string]string{}
+	for _, post := range targets {
+		urls[strings.ToLower(post.Title)] = g.postURL(post)
+	}
+
+	resolved := make([]Post, len(posts))
+	for i, post := range posts {
+		var err error
+		post.Content = wikiLinkRegexp.ReplaceAllStringFunc(post.Content, func(link string) string {
+			match := wikiLinkRegexp.FindStringSubmatch(link)
+			title, label := strings.TrimSpace(match[1]), strings.TrimSpace(match[2])
+			if label == "" {
+				label = title
+			}
+
+			url, ok := urls[strings.ToLower(title)]
+			if !ok {
+				message := fmt.Sprintf("broken wiki link in %s: %s", post.Title, link)
+				if mode == BrokenLinksWarn {
+					g.options.warn(message)
+				} else if err == nil {
+					err = fmt.Errorf("%s", message)
+				}
+				return label
+			}
+
+			if post.Format == FormatHTML {
+				return fmt.Sprintf(`<a href="%s">%s</a>`, stdhtml.EscapeString(url),
+					stdhtml.EscapeString(label))
+			}
+			return fmt.Sprintf("[%s](%s)", label, url)
+		})
+		if err != nil {
+			return nil, err
+		}
+		resolved[i] = post
+	}
+
+	return resolved, nil
+}
@@ -0,0 +1,98 @@
+package lib
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestResolveWikiLinks(t *testing.T) {
+	g := StaticBlogGenerator{}
+	posts := []Post{
+		{Title: "Rome", Content: "See [[paris]] and [[Paris|the capital]]."},
+		{Title: "Paris", Content: "Back to [[Rome]]."},
+	}
+
+	resolved, err := g.resolveWikiLinks(posts, posts)
+	if err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+
+	want := "See [paris](/paris.html) and [the capital](/paris.html)."
+	if resolved[0].Content != want {
+		t.Errorf("want %q, got %q", want, resolved[0].Content)
+	}
+}
+
+func TestResolveWikiLinksBroken(t *testing.T) {
+	posts := []Post{{Title: "Rome", Content: "See [[London]]."}}
+
+	if _, err := (StaticBlogGenerator{}).resolveWikiLinks(posts, posts); err == nil {
+		t.Errorf("want an error, got nil")
+	}
+
+	var warnings []string
+	g := StaticBlogGenerator{options: options{config: Config{BrokenLinks: BrokenLinksWarn},
+		warnFunc: func(message string) { warnings = append(warnings, message) }}}
+
+	resolved, err := g.resolveWikiLinks(posts, posts)
+	if err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+	if resolved[0].Content != "See London." {
+		t.Errorf("want %q, got %q", "See London.", resolved[0].Content)
+	}
+	if len(warnings) != 1 {
+		t.Errorf("want %v, got %v", 1, len(warnings))
+	}
+
+	// errors aren't reported as warnings, too
+	warnings = nil
+	g.options.config.BrokenLinks = BrokenLinksError
+	if _, err := g.resolveWikiLinks(posts, posts); err == nil || len(warnings) != 0 {
+		t.Errorf("want only an error, got %v and %v", err, warnings)
+	}
+}
+
+func TestGenerateWikiLinks(t *testing.T) {
+	templates := writeTestTemplates(t)
+	os.WriteFile(filepath.Join(templates, "post.tmpl"),
+		[]byte(`{{define "content"}}{{. | html}}{{end}}`), 0600)
+	blog := Blog{
+		{Title: "Rome", Content: "See [[Paris]].", Written: time.Now().Add(-time.Hour)},
+		{Title: "Paris", Content: "Paris", Written: time.Now().Add(-2 * time.Hour), Draft: true},
+		{Title: "Oslo", Content: "Oslo", Written: time.Now().Add(time.Hour)},
+	}
+
+	output := t.TempDir()
+	g, err := NewStaticBlogGenerator(blog, templates, output, func(string) {}, WithPreview())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Generate(); err != nil {
+		t.Fatal(err)
+	}
+	if page, _ := os.ReadFile(filepath.Join(output, "rome.html")); !strings.Contains(string(page),
+		`<a href="/paris.html">Paris</a>`) {
+		t.Errorf("want a link to the draft in a preview, got %s", page)
+	}
+
+	// drafts are linked only in previews
+	_, err = NewStaticBlogGenerator(blog, templates, t.TempDir(), func(string) {})
+	if err == nil || !strings.Contains(err.Error(), "broken wiki link in Rome: [[Paris]]") {
+		t.Errorf("want a broken link error, got %v", err)
+	}
+
+	// scheduled posts aren't generated, so they can't be linked
+	blog = Blog{
+		{Title: "Rome", Content: "See [[Oslo]].", Written: time.Now().Add(-time.Hour)},
+		{Title: "Oslo", Content: "Oslo", Written: time.Now().Add(time.Hour)},
+	}
+	_, err = NewStaticBlogGenerator(blog, templates, t.TempDir(), func(string) {},
+		WithConfig(Config{ScheduledPosts: true}))
+	if err == nil || !strings.Contains(err.Error(), "broken wiki link in Rome: [[Oslo]]") {
+		t.Errorf("want a broken link error, got %v", err)
+	}
+}
@@ -0,0 +1,192 @@
+// Package litepubtest helps testing LitePub templates (like themes) without
+// the litepub binary: it renders a Blog fixture with the templates into
+// memory and compares the generated files with golden files.
+//
+//	func TestTheme(t *testing.T) {
+//		blog, err := lib.NewMarkdownBlog("testdata/blog").Read()
+//		if err != nil {
+//			t.Fatal(err)
+//		}
+//		litepubtest.Render(t, blog, "templates").Golden(t, "testdata/golden")
+//	}
+//
+// Running the tests with the -update flag (go test -update) writes the
+// golden files instead of comparing them.
+package litepubtest
+
+import (
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mirovarga/litepub/lib"
+)
+
+var update = flag.Bool("update", false, "update the golden files of litepubtest")
+
+// buildTime is the time of the Build (see lib.WithBuild) of rendered Blogs,
+// so templates showing it produce the same files in each run.
+var buildTime = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// Site holds the contents of the files generated from a Blog (without the
+// files copied from the templates and static directories) by their paths
+// relative to the output directory using forward slashes.
+type Site map[string][]byte
+
+// Paths returns the paths of the Site's files sorted alphabetically.
+func (s Site) Paths() []string {
+	var paths []string
+	for path := range s {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// Render generates the Blog with the templates in the directory and the
+// options and returns the generated files. The Build (see lib.WithBuild) has
+// a fixed time unless the options set it. It fails the test if the Blog can't
+// be generated.
+func Render(t testing.TB, blog lib.Blog, templates string, opts ...lib.Option) Site {
+	t.Helper()
+
+	site, err := render(blog, templates, t.TempDir(), opts)
+	if err != nil {
+		t.Fatalf("failed to render blog: %s", err)
+	}
+	return site
+}
+
+func render(blog lib.Blog, templates, output string, opts []lib.Option) (Site, error) {
+	generated := map[string]bool{}
+	record := lib.WithFileHook(func(path string, content []byte) ([]byte, error) {
+		generated[path] = true
+		return content, nil
+	})
+
+	opts = append([]lib.Option{lib.WithBuild(lib.Build{Version: lib.Version, Time: buildTime})},
+		append(opts, record)...)
+	gen, err := lib.NewStaticBlogGenerator(blog, templates, output, func(string) {}, opts...)
+	if err != nil {
+		return nil, err
+	}
+	err = gen.Generate()
+	if err != nil {
+		return nil, err
+	}
+
+	// the files are read after the build, because some stages (like relative
+	// URLs) rewrite them
+	site := Site{}
+	for path := range generated {
+		content, err := os.ReadFile(filepath.Join(output, filepath.FromSlash(path)))
+		if err != nil {
+			return nil, err
+		}
+		site[path] = content
+	}
+	return site, nil
+}
+
+// Golden compares the Site's files with the golden files of the same paths in
+// the directory and reports differences, missing and unexpected golden files
+// as test errors. With the -update flag it replaces the golden files with the
+// Site's files instead.
+func (s Site) Golden(t testing.TB, dir string) {
+	t.Helper()
+
+	if *update {
+		err := s.write(dir)
+		if err != nil {
+			t.Fatalf("failed to update golden files: %s", err)
+		}
+		return
+	}
+
+	for _, problem := range s.compare(dir) {
+		t.Error(problem)
+	}
+}
+
+// write replaces the files in the directory with the Site's files.
+func (s Site) write(dir string) error {
+	err := os.RemoveAll(dir)
+	if err != nil {
+		return err
+	}
+
+	for path, content := range s {
+		file := filepath.Join(dir, filepath.FromSlash(path))
+		err := os.MkdirAll(filepath.Dir(file), 0755)
+		if err != nil {
+			return err
+		}
+		err = os.WriteFile(file, content, 0644)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// compare returns the differences between the Site's files and the golden
+// files in the directory.
+func (s Site) compare(dir string) []string {
+	var problems []string
+	for _, path := range s.Paths() {
+		golden, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(path)))
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: missing golden file (run with -update)", path))
+			continue
+		}
+		if diff := firstDiff(string(golden), string(s[path])); diff != "" {
+			problems = append(problems, fmt.Sprintf("%s: doesn't match the golden file: %s", path, diff))
+		}
+	}
+
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		if _, ok := s[filepath.ToSlash(rel)]; !ok {
+			problems = append(problems, fmt.Sprintf("%s: golden file wasn't generated", filepath.ToSlash(rel)))
+		}
+		return nil
+	})
+	if err != nil {
+		problems = append(problems, fmt.Sprintf("failed to read golden files: %s", err))
+	}
+	return problems
+}
+
+// firstDiff describes the first line that differs between the want and got
+// contents. It returns an empty string if they're equal.
+func firstDiff(want, got string) string {
+	if want == got {
+		return ""
+	}
+
+	wantLines, gotLines := strings.Split(want, "\n"), strings.Split(got, "\n")
+	for i := 0; ; i++ {
+		var w, g string
+		if i < len(wantLines) {
+			w = wantLines[i]
+		}
+		if i < len(gotLines) {
+			g = gotLines[i]
+		}
+		if w != g || i >= len(wantLines) || i >= len(gotLines) {
+			return fmt.Sprintf("line %d: want %q, got %q", i+1, w, g)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package litepubtest
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/mirovarga/litepub/lib"
+)
+
+func TestRenderGolden(t *testing.T) {
+	blog, err := lib.NewMarkdownBlog("testdata/blog").Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	site := Render(t, blog, "testdata/templates")
+	if want := []string{"index.html", "oslo.html", "rome.html", "tags/cities.html"}; !reflect.DeepEqual(site.Paths(), want) {
+		t.Errorf("want %v, got %v", want, site.Paths())
+	}
+	site.Golden(t, "testdata/golden")
+}
+
+func TestSiteCompare(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "index.html"), []byte("<h1>Rome</h1>\n<p>Old</p>\n"), 0600)
+	os.WriteFile(filepath.Join(dir, "stale.html"), []byte("stale"), 0600)
+
+	site := Site{
+		"index.html": []byte("<h1>Rome</h1>\n<p>New</p>\n"),
+		"rome.html":  []byte("Rome"),
+	}
+	want := []string{
+		`index.html: doesn't match the golden file: line 2: want "<p>Old</p>", got "<p>New</p>"`,
+		"rome.html: missing golden file (run with -update)",
+		"stale.html: golden file wasn't generated",
+	}
+	if problems := site.compare(dir); !reflect.DeepEqual(problems, want) {
+		t.Errorf("want %v, got %v", want, problems)
+	}
+
+	err := site.write(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if problems := site.compare(dir); len(problems) > 0 {
+		t.Errorf("want no problems, got %v", problems)
+	}
+}